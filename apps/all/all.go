@@ -0,0 +1,56 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package all imports all the application packages so they
+// register themselves to the apps registry. Import it for side
+// effects only:
+//
+//	import _ "klogproc/apps/all"
+package all
+
+import (
+	_ "klogproc/servicelog/apiguard"
+	_ "klogproc/servicelog/apiguard-kontext018"
+	_ "klogproc/servicelog/apiguard-kwords"
+	_ "klogproc/servicelog/apiguard-mquery"
+	_ "klogproc/servicelog/apiguard-treq"
+	_ "klogproc/servicelog/custom"
+	_ "klogproc/servicelog/extproc"
+	_ "klogproc/servicelog/gokit"
+	_ "klogproc/servicelog/kontext013"
+	_ "klogproc/servicelog/kontext015"
+	_ "klogproc/servicelog/kontext018"
+	_ "klogproc/servicelog/korpusdb"
+	_ "klogproc/servicelog/kwords"
+	_ "klogproc/servicelog/kwords2"
+	_ "klogproc/servicelog/mapka"
+	_ "klogproc/servicelog/mapka2"
+	_ "klogproc/servicelog/mapka3"
+	_ "klogproc/servicelog/mapping"
+	_ "klogproc/servicelog/masm"
+	_ "klogproc/servicelog/morfio"
+	_ "klogproc/servicelog/mquery"
+	_ "klogproc/servicelog/mquerysru"
+	_ "klogproc/servicelog/shiny"
+	_ "klogproc/servicelog/ske"
+	_ "klogproc/servicelog/syd"
+	_ "klogproc/servicelog/treq"
+	_ "klogproc/servicelog/treqapi"
+	_ "klogproc/servicelog/vlo"
+	_ "klogproc/servicelog/wag06"
+	_ "klogproc/servicelog/wag07"
+	_ "klogproc/servicelog/wsserver"
+)
@@ -0,0 +1,68 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"klogproc/corpora"
+	"klogproc/load/accesslog"
+	"klogproc/lookup"
+	"klogproc/luasandbox"
+	"klogproc/users"
+
+	"github.com/czcorpus/klogproc-core/analysis"
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// Env provides application factories with a configuration of a processed
+// log and with resources derived from the configuration. Resources are
+// created on demand so factories can ask only for what they need.
+//
+// Additional resources may be provided by implementing other interfaces
+// (e.g. errstream.SinkProvider) which factories can test for.
+type Env interface {
+
+	// LogConf returns the configuration of the processed log
+	LogConf() storage.LogProcConf
+
+	// AppErrRegister returns a register of application errors
+	// (only available when creating parsers)
+	AppErrRegister() storage.AppErrorRegister
+
+	AnonymousUsers() []int
+
+	// RealtimeClock specifies whether records are processed as they
+	// are written (tail) or ex post (batch)
+	RealtimeClock() bool
+
+	Notifier() analysis.Notifier
+
+	// AccessLogParser returns an access log parser
+	// with a format configured for the log
+	AccessLogParser() (accesslog.LineParser, error)
+
+	// UserResolver returns a resolver mapping usernames
+	// to user IDs
+	UserResolver() (users.IDResolver, error)
+
+	Corpora() corpora.Conf
+
+	// LookupTables returns lookup tables configured for the log
+	// (to be available to Lua scripts)
+	LookupTables() (*lookup.Registry, error)
+
+	ScriptLimits() luasandbox.Limits
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"errors"
+	"io"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// ErrParserUnavailable is returned (wrapped) by line parsers which
+// temporarily cannot parse any line (e.g. an external parser process
+// is being restarted). Unlike storage.LineParsingError, it does not
+// mean the line is broken so callers should not drop the line silently.
+var ErrParserUnavailable = errors.New("parser not available")
+
+// typedLineParser is a parser returning concrete (application specific)
+// input records
+type typedLineParser[T storage.InputRecord] interface {
+	ParseLine(s string, lineNum int64) (T, error)
+}
+
+type lineParser[T storage.InputRecord] struct {
+	lp typedLineParser[T]
+}
+
+// ParseLine parses a passed line of a respective log
+func (p *lineParser[T]) ParseLine(s string, lineNum int64) (storage.InputRecord, error) {
+	return p.lp.ParseLine(s, lineNum)
+}
+
+// Close closes the wrapped parser in case it holds some
+// resources (e.g. an external process)
+func (p *lineParser[T]) Close() error {
+	if c, ok := p.lp.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// WrapParser wraps an application specific parser into a general
+// form as required by the core of klogproc
+func WrapParser[T storage.InputRecord](lp typedLineParser[T]) storage.LineParser {
+	return &lineParser[T]{lp: lp}
+}
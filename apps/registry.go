@@ -0,0 +1,219 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apps contains a registry of supported application types.
+// Each application package (see `servicelog`) registers its line parser,
+// transformer and record types so other parts of klogproc (factories,
+// script stubs, schemas) do not have to list the applications on their own.
+package apps
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// ParserFactory creates a line parser for a log described by env
+type ParserFactory func(env Env) (storage.LineParser, error)
+
+// TransformerFactory creates a transformer for a log described by env
+type TransformerFactory func(env Env) (storage.LogItemTransformer, error)
+
+// App describes a supported application type (and its version)
+type App struct {
+	Type string
+
+	// Version is an application version. For applications without
+	// versions, it is empty.
+	Version string
+
+	// NewParser creates a line parser. Applications processed only
+	// via a different app type (e.g. as part of APIGuard logs) have
+	// no parser.
+	NewParser ParserFactory
+
+	NewTransformer TransformerFactory
+
+	// NewInputRecord creates an empty input record (used to generate
+	// script stubs and schemas)
+	NewInputRecord func() storage.InputRecord
+
+	// NewOutputRecord creates an empty output record (used by Lua scripts
+	// and to generate script stubs and schemas)
+	NewOutputRecord func() storage.OutputRecord
+
+	// Buffering specifies whether the transformer works with
+	// a log buffer (`buffer` configuration)
+	Buffering bool
+
+	// NoDetection excludes the version from automatic version
+	// detection (e.g. a version sharing a log format with another one)
+	NoDetection bool
+
+	// Recognizes tests whether a parsed record has a shape specific
+	// to the version. Automatic version detection uses it to distinguish
+	// versions whose parsers accept each other's lines. If nil, any
+	// parsed record is considered recognized.
+	Recognizes func(rec storage.InputRecord) bool
+}
+
+// String returns an identifier of the app in the [type]-[version] form
+// (or just [type] for applications without versions)
+func (app App) String() string {
+	if app.Version == "" {
+		return app.Type
+	}
+	return app.Type + "-" + app.Version
+}
+
+// SupportsScripting tests whether records of the app can be
+// transformed by Lua scripts
+func (app App) SupportsScripting() bool {
+	return app.NewTransformer != nil && app.NewOutputRecord != nil
+}
+
+// HasRecordTypes tests whether the app provides its input and output
+// record types (required for script stubs and schemas)
+func (app App) HasRecordTypes() bool {
+	return app.NewInputRecord != nil && app.NewOutputRecord != nil
+}
+
+// -----
+
+// Registry contains registered applications. Versions of each
+// application type are kept sorted from the oldest to the newest.
+type Registry struct {
+	apps map[string][]App
+}
+
+// Register adds an application to the registry.
+// It panics in case the app type is empty or the app is already
+// registered as this is always a programming error.
+func (r *Registry) Register(app App) {
+	if app.Type == "" {
+		panic("apps: cannot register an application with an empty type")
+	}
+	for _, curr := range r.apps[app.Type] {
+		if curr.Version == app.Version {
+			panic(fmt.Sprintf("apps: application %s registered twice", app))
+		}
+	}
+	versions := append(r.apps[app.Type], app)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+	r.apps[app.Type] = versions
+}
+
+// Get returns an application of a specified type and version. For applications
+// without versions (i.e. with a single version registered as empty), any version
+// is accepted.
+func (r *Registry) Get(appType, version string) (App, error) {
+	versions, ok := r.apps[appType]
+	if !ok {
+		return App{}, fmt.Errorf("unknown app type %s", appType)
+	}
+	for _, app := range versions {
+		if app.Version == version {
+			return app, nil
+		}
+	}
+	if len(versions) == 1 && versions[0].Version == "" {
+		return versions[0], nil
+	}
+	return App{}, fmt.Errorf("unsupported version %s of %s", version, appType)
+}
+
+// OfType returns all the versions of an application type
+// (from the oldest to the newest)
+func (r *Registry) OfType(appType string) []App {
+	ans := make([]App, len(r.apps[appType]))
+	copy(ans, r.apps[appType])
+	return ans
+}
+
+// All returns all the registered applications sorted by their types
+// and versions
+func (r *Registry) All() []App {
+	types := make([]string, 0, len(r.apps))
+	for appType := range r.apps {
+		types = append(types, appType)
+	}
+	sort.Strings(types)
+	ans := make([]App, 0, len(types)*2)
+	for _, appType := range types {
+		ans = append(ans, r.apps[appType]...)
+	}
+	return ans
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{apps: make(map[string][]App)}
+}
+
+// compareVersions compares version strings by their numeric
+// parts (so e.g. 0.9 < 0.13); non-numeric parts are compared
+// as strings
+func compareVersions(v1, v2 string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(v, func(c rune) bool { return c == '.' || c == '-' })
+	}
+	p1, p2 := split(v1), split(v2)
+	for i := 0; i < len(p1) && i < len(p2); i++ {
+		n1, err1 := strconv.Atoi(p1[i])
+		n2, err2 := strconv.Atoi(p2[i])
+		if err1 == nil && err2 == nil {
+			if n1 != n2 {
+				return n1 - n2
+			}
+
+		} else if c := strings.Compare(p1[i], p2[i]); c != 0 {
+			return c
+		}
+	}
+	return len(p1) - len(p2)
+}
+
+// -----
+
+var defaultRegistry = NewRegistry()
+
+// Register adds an application to the default registry.
+// Application packages call this from their init() functions.
+func Register(app App) {
+	defaultRegistry.Register(app)
+}
+
+// Get returns an application from the default registry
+// (see Registry.Get)
+func Get(appType, version string) (App, error) {
+	return defaultRegistry.Get(appType, version)
+}
+
+// OfType returns all the versions of an application type
+// from the default registry
+func OfType(appType string) []App {
+	return defaultRegistry.OfType(appType)
+}
+
+// All returns all the applications from the default registry
+func All() []App {
+	return defaultRegistry.All()
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	line string
+}
+
+func (r *testRecord) GetTime() time.Time {
+	return time.Time{}
+}
+
+func (r *testRecord) IsProcessable() bool {
+	return r.line != ""
+}
+
+type testParser struct{}
+
+func (p *testParser) ParseLine(s string, lineNum int64) (*testRecord, error) {
+	return &testRecord{line: s}, nil
+}
+
+func TestRegistryGet(t *testing.T) {
+	r := NewRegistry()
+	r.Register(App{Type: "app", Version: "0.13"})
+	r.Register(App{Type: "app", Version: "0.9"})
+	r.Register(App{Type: "app", Version: "0.13-api", NoDetection: true})
+	r.Register(App{Type: "simple"})
+
+	app, err := r.Get("app", "0.13")
+	assert.NoError(t, err)
+	assert.Equal(t, "app-0.13", app.String())
+
+	_, err = r.Get("app", "0.14")
+	assert.Error(t, err)
+	_, err = r.Get("app", "")
+	assert.Error(t, err)
+	_, err = r.Get("unknown", "")
+	assert.Error(t, err)
+
+	// apps without versions accept any version
+	app, err = r.Get("simple", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, "simple", app.String())
+}
+
+func TestRegistryVersionOrder(t *testing.T) {
+	r := NewRegistry()
+	r.Register(App{Type: "app", Version: "0.13-api"})
+	r.Register(App{Type: "app", Version: "0.13"})
+	r.Register(App{Type: "app", Version: "0.9"})
+	r.Register(App{Type: "other", Version: "v1-api"})
+	r.Register(App{Type: "other"})
+
+	versions := make([]string, 0, 3)
+	for _, app := range r.OfType("app") {
+		versions = append(versions, app.Version)
+	}
+	assert.Equal(t, []string{"0.9", "0.13", "0.13-api"}, versions)
+
+	all := r.All()
+	assert.Len(t, all, 5)
+	assert.Equal(t, "other", all[3].String())
+	assert.Equal(t, "other-v1-api", all[4].String())
+}
+
+func TestRegistryRegisterTwice(t *testing.T) {
+	r := NewRegistry()
+	r.Register(App{Type: "app", Version: "1"})
+	assert.Panics(t, func() { r.Register(App{Type: "app", Version: "1"}) })
+	assert.Panics(t, func() { r.Register(App{Version: "1"}) })
+}
+
+func TestAppCapabilities(t *testing.T) {
+	app := App{
+		Type:            "app",
+		NewOutputRecord: func() storage.OutputRecord { return nil },
+	}
+	assert.False(t, app.SupportsScripting())
+	assert.False(t, app.HasRecordTypes())
+	app.NewTransformer = func(env Env) (storage.LogItemTransformer, error) { return nil, nil }
+	app.NewInputRecord = func() storage.InputRecord { return &testRecord{} }
+	assert.True(t, app.SupportsScripting())
+	assert.True(t, app.HasRecordTypes())
+}
+
+func TestWrapParser(t *testing.T) {
+	lp := WrapParser(&testParser{})
+	rec, err := lp.ParseLine("foo", 1)
+	assert.NoError(t, err)
+	assert.True(t, rec.IsProcessable())
+	assert.Equal(t, "foo", rec.(*testRecord).line)
+}
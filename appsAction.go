@@ -0,0 +1,83 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"klogproc/apps"
+	"klogproc/trfactory"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+// runAppsAction prints supported application types along with their
+// versions and capabilities. With a non-empty appType, only the type
+// is listed.
+func runAppsAction(w io.Writer, appType string) error {
+	var listed []apps.App
+	if appType == "" {
+		listed = apps.All()
+
+	} else {
+		listed = apps.OfType(appType)
+		if len(listed) == 0 {
+			return fmt.Errorf("unknown app type %s", appType)
+		}
+	}
+	headerFmt := color.New(color.FgGreen).SprintfFunc()
+	columnFmt := color.New(color.FgHiMagenta).SprintfFunc()
+	tbl := table.New(
+		"App type",
+		"Version",
+		"Parser",
+		"Scripting",
+		"Buffering",
+		"Version detection",
+	)
+	tbl.
+		WithWriter(w).
+		WithHeaderFormatter(headerFmt).
+		WithFirstColumnFormatter(columnFmt).
+		WithHeaderSeparatorRow('═')
+	for _, app := range listed {
+		version := app.Version
+		if version == "" {
+			version = "-"
+		}
+		detectable := len(trfactory.DetectableVersions(app.Type)) > 0 && !app.NoDetection
+		tbl.AddRow(
+			app.Type,
+			version,
+			yesNo(app.NewParser != nil),
+			yesNo(app.SupportsScripting()),
+			yesNo(app.Buffering),
+			yesNo(detectable),
+		)
+	}
+	tbl.Print()
+	return nil
+}
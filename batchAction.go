@@ -18,10 +18,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"klogproc/apps"
 	"klogproc/config"
+	"klogproc/errstream"
 	"klogproc/load/batch"
+	"klogproc/load/botreport"
+	"klogproc/load/sampling"
+	"klogproc/load/throttle"
+	"klogproc/luasandbox"
 	"klogproc/notifications"
 	"klogproc/trfactory"
+	"os"
 	"os/signal"
 	"reflect"
 	"syscall"
@@ -45,10 +54,17 @@ type cnkLogProcessor struct {
 	anonymousUsers []int
 	geoIPDb        *geoip2.Reader
 	chunkSize      int
-	numNonLoggable int
 	skipAnalysis   bool
 	logTransformer storage.LogItemTransformer
 	logBuffer      storage.ServiceLogBuffer
+
+	// botReport is used in the "analysis only" mode
+	// to collect traffic statistics
+	botReport *botreport.Collector
+
+	// sampler (if set) selects a deterministic subset of records
+	// to be processed
+	sampler *sampling.Sampler
 }
 
 func (clp *cnkLogProcessor) recordIsLoggable(logRec storage.InputRecord) bool {
@@ -56,11 +72,21 @@ func (clp *cnkLogProcessor) recordIsLoggable(logRec storage.InputRecord) bool {
 }
 
 // ProcItem transforms input log record into an output format.
-// In case an unsupported record is encountered, nil is returned.
+// In case an unsupported record is encountered, an empty slice is returned.
+// The returned outcome describes what happened to the record.
 func (clp *cnkLogProcessor) ProcItem(
 	logRec storage.InputRecord,
-) []storage.OutputRecord {
+) ([]storage.OutputRecord, batch.ItemOutcome) {
+	// bots and scrapers often produce records which are not processable
+	// (e.g. requests of static files or failed requests) so the traffic
+	// statistics must include them too
+	if clp.botReport != nil && (clp.sampler == nil || clp.sampler.Accepts(logRec)) {
+		clp.botReport.AddRecord(logRec)
+	}
 	if clp.recordIsLoggable(logRec) {
+		if clp.sampler != nil && !clp.sampler.Accepts(logRec) {
+			return []storage.OutputRecord{}, batch.ItemSampledOut
+		}
 		ans := make([]storage.OutputRecord, 0, 2)
 		prepInp, err := clp.logTransformer.Preprocess(logRec, clp.logBuffer)
 		if err != nil {
@@ -68,9 +94,18 @@ func (clp *cnkLogProcessor) ProcItem(
 				Str("appType", clp.appType).
 				Str("appVersion", clp.appVersion).
 				Err(err).Msgf("Failed to transform item %s", logRec)
-			return []storage.OutputRecord{}
+			if luasandbox.IsLimitError(err) {
+				return []storage.OutputRecord{}, batch.ItemScriptLimitError
+			}
+			return []storage.OutputRecord{}, batch.ItemTransformError
+		}
+		if len(prepInp) == 0 {
+			return []storage.OutputRecord{}, batch.ItemPreprocessDrop
 		}
 		for _, precord := range prepInp {
+			if clp.botReport != nil {
+				clp.botReport.AddPreprocessed(precord)
+			}
 			clp.logBuffer.AddRecord(precord)
 			rec, err := clp.logTransformer.Transform(precord)
 			if err != nil {
@@ -78,15 +113,20 @@ func (clp *cnkLogProcessor) ProcItem(
 					Str("appType", clp.appType).
 					Str("appVersion", clp.appVersion).
 					Err(err).Msgf("Failed to transform item %s", logRec)
-				return []storage.OutputRecord{}
+				if luasandbox.IsLimitError(err) {
+					return []storage.OutputRecord{}, batch.ItemScriptLimitError
+				}
+				return []storage.OutputRecord{}, batch.ItemTransformError
 			}
 			applyLocation(precord, clp.geoIPDb, rec)
+			if rrec, ok := rec.(sampling.RateRecord); ok && clp.sampler != nil {
+				rrec.SetSamplingRate(clp.sampler.Rate())
+			}
 			ans = append(ans, rec)
 		}
-		return ans
+		return ans, batch.ItemProcessed
 	}
-	clp.numNonLoggable++
-	return []storage.OutputRecord{}
+	return []storage.OutputRecord{}, batch.ItemNonProcessable
 }
 
 // GetAppType returns a string idenfier unique for a concrete application we
@@ -100,18 +140,27 @@ func (clp *cnkLogProcessor) GetAppVersion() string {
 	return clp.appVersion
 }
 
-func runBatchAction(
+// newBatchProcessor creates a log processor (including its transformer and
+// log buffer) as used by file-based processing of logs (batch, reconcile).
+// The minTimestamp specifies files to be processed (which is needed
+// in case the application version is detected automatically).
+// Without persistBuffer, a configured log buffer is replaced by a dummy one
+// so the buffer state stored in LogBufferStateDir is neither read nor modified
+// (e.g. for actions which do not write anything).
+func newBatchProcessor(
 	conf *config.Main,
-	options *ProcessOptions,
 	geoDB *geoip2.Reader,
-	finishEvent chan<- bool,
-) {
+	worklogReset bool,
+	persistBuffer bool,
+	minTimestamp int64,
+) (*cnkLogProcessor, error) {
 	// For debugging e-mail notification, you can pass `conf.EmailNotification`
 	// as the first argument and use the "batch" mode to tune log processing.
 	nullMailNot, _ := notifications.NewNotifier(nil, conf.ConomiNotification, conf.TimezoneLocation())
 
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	if err := batch.ResolveVersion(conf.LogFiles, minTimestamp); err != nil {
+		return nil, err
+	}
 
 	lt, err := trfactory.GetLogTransformer(
 		conf.LogFiles,
@@ -120,8 +169,7 @@ func runBatchAction(
 		nullMailNot,
 	)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to run batch action")
-		return
+		return nil, fmt.Errorf("failed to create log processor: %w", err)
 	}
 
 	var buffStorage storage.ServiceLogBuffer
@@ -140,15 +188,18 @@ func runBatchAction(
 		}
 	}
 
-	if conf.LogFiles.Buffer != nil {
+	if conf.LogFiles.Buffer != nil && persistBuffer {
 		buffStorage = logbuffer.NewStorage[storage.InputRecord, logbuffer.SerializableState](
 			conf.LogFiles.Buffer,
-			options.worklogReset,
+			worklogReset,
 			conf.LogFiles.LogBufferStateDir,
 			conf.LogFiles.SrcPath,
 			stateFactory,
 		)
 
+	} else if conf.LogFiles.Buffer != nil {
+		buffStorage = logbuffer.NewDummyStorage[storage.InputRecord, logbuffer.SerializableState](stateFactory)
+
 	} else {
 		buffStorage = logbuffer.NewDummyStorage[storage.InputRecord, logbuffer.SerializableState](
 			func() logbuffer.SerializableState {
@@ -160,7 +211,7 @@ func runBatchAction(
 		)
 	}
 
-	processor := &cnkLogProcessor{
+	return &cnkLogProcessor{
 		geoIPDb:        geoDB,
 		chunkSize:      conf.ElasticSearch.PushChunkSize,
 		appType:        conf.LogFiles.AppType,
@@ -169,8 +220,44 @@ func runBatchAction(
 		anonymousUsers: conf.AnonymousUsers,
 		skipAnalysis:   conf.LogFiles.SkipAnalysis,
 		logBuffer:      buffStorage,
+	}, nil
+}
+
+// checkSamplingSupport tests whether records of the application
+// can be sampled by the configured key and whether the output records
+// are able to store the sampling rate (which is not needed in case
+// no records are written - i.e. with writesOutput == false).
+func checkSamplingSupport(sampler *sampling.Sampler, appType, version string, writesOutput bool) error {
+	app, err := apps.Get(appType, version)
+	if err != nil {
+		return fmt.Errorf("failed to check sampling support: %w", err)
 	}
-	channelWriteES := make(chan *storage.BoundOutputRecord, conf.ElasticSearch.PushChunkSize*2)
+	if !app.HasRecordTypes() {
+		return nil
+	}
+	inputRec, ok := app.NewInputRecord().(sampling.Record)
+	if !ok {
+		return fmt.Errorf("cannot sample records of %s: no client IP", app)
+	}
+	if err := sampler.Supports(inputRec); err != nil {
+		return fmt.Errorf("cannot sample records of %s: %w", app, err)
+	}
+	if _, ok := app.NewOutputRecord().(sampling.RateRecord); !ok && writesOutput {
+		return fmt.Errorf(
+			"cannot sample records of %s: output records cannot store the samplingRate property", app)
+	}
+	return nil
+}
+
+func runBatchAction(
+	conf *config.Main,
+	options *ProcessOptions,
+	geoDB *geoip2.Reader,
+	finishEvent chan<- bool,
+) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	worklog := batch.NewWorklog(conf.LogFiles.WorklogPath)
 	log.Info().Msgf("using worklog %s", conf.LogFiles.WorklogPath)
 	if options.worklogReset {
@@ -180,13 +267,58 @@ func runBatchAction(
 			log.Fatal().Msgf("unable to initialize worklog: %s", err)
 		}
 	}
-	defer worklog.Save()
 
+	processor, err := newBatchProcessor(conf, geoDB, options.worklogReset, true, worklog.GetLastRecord())
+	if errors.Is(err, batch.ErrNothingToProcess) {
+		// with an up-to-date worklog, there is nothing to detect
+		// the `auto` version from and nothing to do either
+		log.Info().Err(err).Msg("batch action finished")
+		finishEvent <- true
+		return
+
+	} else if err != nil {
+		log.Fatal().Err(err).Msg("failed to run batch action")
+		return
+	}
+	if options.sampleRate > 0 {
+		processor.sampler, err = sampling.NewSampler(options.sampleRate, options.sampleSeed, options.sampleKey)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to run batch action")
+			return
+		}
+		if err := checkSamplingSupport(
+			processor.sampler, processor.appType, processor.appVersion, !options.analysisOnly); err != nil {
+			log.Fatal().Err(err).Msg("failed to run batch action")
+			return
+		}
+		log.Warn().
+			Float64("rate", processor.sampler.Rate()).
+			Str("key", processor.sampler.Key()).
+			Msg("using sampling mode, only a subset of records will be processed")
+	}
+	if options.analysisOnly {
+		var patterns *botreport.BotPatterns
+		if conf.LogFiles.BotPatternsPath != "" {
+			patterns, err = botreport.LoadBotPatterns(conf.LogFiles.BotPatternsPath)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to run batch action")
+				return
+			}
+		}
+		processor.botReport = botreport.NewCollector(patterns, botreport.Options{})
+	}
+	channelWriteES := make(chan *storage.BoundOutputRecord, conf.ElasticSearch.PushChunkSize*2)
+	report := batch.NewRunReport(conf.LogFiles.AppType, conf.LogFiles.Version)
 	wait := make(chan any)
+	// with throttling enabled, parsed records go through a throttle
+	// relay, otherwise directly to the writer
+	channelParsed := channelWriteES
+	var writeThrottle *throttle.Throttle
 	if options.dryRun || options.analysisOnly {
 		wch := save.RunWriteConsumer(ctx, channelWriteES, !options.analysisOnly)
 		go func() {
-			for range wch {
+			for confirm := range wch {
+				report.AddWriteConfirmation(confirm.FilePath, confirm.Error)
 			}
 			wait <- struct{}{}
 		}()
@@ -194,23 +326,107 @@ func runBatchAction(
 
 	} else {
 		wch := elastic.RunWriteConsumer(ctx, conf.LogFiles.AppType, &conf.ElasticSearch, channelWriteES)
+		if conf.LogFiles.Throttle.IsConfigured() {
+			writeThrottle, err = throttle.NewThrottle(conf.LogFiles.Throttle, conf.ElasticSearch.PushChunkSize)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to run batch action")
+				return
+			}
+			log.Info().Float64("docsPerSec", writeThrottle.Rate()).Msg("using throttled writing")
+			channelParsed = make(chan *storage.BoundOutputRecord, conf.ElasticSearch.PushChunkSize*2)
+			go throttle.Relay(ctx, writeThrottle, channelParsed, channelWriteES)
+		}
 		go func() {
 			for confirm := range wch {
 				if confirm.Error != nil {
 					log.Error().Err(confirm.Error).Msg("failed to save data to ElasticSearch database")
 					// TODO
 				}
+				if writeThrottle != nil {
+					writeThrottle.Confirm(confirm.Error)
+				}
+				report.AddWriteConfirmation(confirm.FilePath, confirm.Error)
 			}
 			wait <- struct{}{}
 		}()
 	}
-	proc := batch.CreateLogFileProcFunc(ctx, processor, options.datetimeRange, channelWriteES)
+	var errorSink errstream.Sink = errstream.NullSink{}
+	var errWriter *errstream.Writer
+	if conf.LogFiles.ErrorStream != nil && !options.analysisOnly {
+		errWriter = errstream.NewWriter(
+			ctx,
+			conf.LogFiles.ErrorStream,
+			conf.ElasticSearch,
+			conf.LogFiles.AppType,
+			conf.LogFiles.Version,
+			conf.LogFiles.SrcPath,
+			conf.TimezoneLocation(),
+			options.dryRun,
+		)
+		errWriter.Start()
+		errorSink = errWriter
+		log.Info().Str("index", conf.LogFiles.ErrorStream.Index).Msg("writing application errors")
+	}
+	proc := batch.CreateLogFileProcFunc(
+		ctx, processor, options.datetimeRange, report, errorSink, channelParsed)
 	proc(conf.LogFiles, worklog.GetLastRecord())
 	<-wait
-	log.Info().Msgf("Ignored %d non-loggable entries (bots, static files etc.)", processor.numNonLoggable)
-	stateData := buffStorage.GetStateData(time.Now())
-	if stateData != nil && !reflect.ValueOf(stateData).IsNil() {
-		log.Debug().Any("report", buffStorage.GetStateData(time.Now()).Report()).Msg("state report")
+	if errWriter != nil {
+		errWriter.Stop()
+	}
+	report.Finish()
+	if report.IsAborted() {
+		// the worklog is not updated so the next run processes
+		// the unfinished files again
+		log.Error().Str("reason", report.Aborted).Msg("batch processing aborted, worklog not updated")
+
+	} else if processor.sampler != nil {
+		// sampled runs must not prevent the next full run
+		// from processing the same files
+		log.Warn().Msg("sampling mode, worklog not updated")
+
+	} else if err := worklog.Save(); err != nil {
+		log.Error().Err(err).Msg("failed to update worklog")
+	}
+	if writeThrottle != nil {
+		log.Info().
+			Int("numBackoffs", writeThrottle.NumBackoffs()).
+			Float64("finalDocsPerSec", writeThrottle.Rate()).
+			Msg("throttled writing finished")
+	}
+	log.Info().Msgf("Ignored %d non-loggable entries (bots, static files etc.)", report.Total.NonProcessable)
+	report.PrintSummary(os.Stderr)
+	if conf.LogFiles.ReportPath != "" {
+		if err := report.WriteJSON(conf.LogFiles.ReportPath); err != nil {
+			log.Error().Err(err).Msg("failed to save batch report")
+
+		} else {
+			log.Info().Str("path", conf.LogFiles.ReportPath).Msg("saved batch report")
+		}
+	}
+	stateData := processor.logBuffer.GetStateData(time.Now())
+	hasStateData := stateData != nil && !reflect.ValueOf(stateData).IsNil()
+	if hasStateData {
+		log.Debug().Any("report", stateData.Report()).Msg("state report")
+	}
+	if processor.botReport != nil {
+		botRep := processor.botReport.Report(conf.LogFiles.AppType, conf.LogFiles.Version)
+		if hasStateData {
+			botRep.BufferState = stateData.Report()
+		}
+		if conf.LogFiles.AnalysisReportDir == "" {
+			log.Warn().Msg("no analysis report directory configured - bot analysis report not saved")
+
+		} else if err := botRep.WriteAll(conf.LogFiles.AnalysisReportDir); err != nil {
+			log.Error().Err(err).Msg("failed to save bot analysis report")
+
+		} else {
+			log.Info().
+				Str("dir", conf.LogFiles.AnalysisReportDir).
+				Int("suspiciousIps", len(botRep.SuspiciousIPs)).
+				Int("suspiciousUserAgents", len(botRep.SuspiciousUAs)).
+				Msg("saved bot analysis report")
+		}
 	}
 	finishEvent <- true
 }
@@ -43,6 +43,12 @@ const (
 	ActionVersion          = "version"
 	ActionTestNotification = "test-notification"
 	ActionSnapshot         = "snapshot"
+	ActionReconcile        = "reconcile"
+	ActionScriptTest       = "script-test"
+	ActionSchema           = "schema"
+	ActionMkMapping        = "mkmapping"
+	ActionCheckMapping     = "check-mapping"
+	ActionApps             = "apps"
 
 	DefaultTimeZone                       = "Europe/Prague"
 	DefaultLogInactivityCheckIntervalSecs = 3600
@@ -88,8 +94,8 @@ func Validate(conf *Main, action string) {
 	if !fsop.IsFile(conf.GeoIPDbPath) {
 		log.Fatal().Msgf("Invalid GeoIPDbPath: '%s'", conf.GeoIPDbPath)
 	}
-	if action == ActionBatch && conf.LogFiles == nil {
-		log.Fatal().Msg("missing configuration data for the `batch` action")
+	if (action == ActionBatch || action == ActionReconcile) && conf.LogFiles == nil {
+		log.Fatal().Msgf("missing configuration data for the `%s` action", action)
 	}
 	if action == ActionTail && conf.LogTail == nil {
 		log.Fatal().Msg("missing configuration data for the `tail` action")
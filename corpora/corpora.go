@@ -0,0 +1,132 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package corpora assigns corpora to log records of applications
+// which do not log them (or log them only partially). As the corpora
+// change over time, each assignment is valid in a specified time range
+// so processing of older logs produces the same data as before.
+package corpora
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+)
+
+// Period specifies corpora valid within [From, To). Both limits can be
+// either a date (YYYY-MM-DD, interpreted in UTC) or an RFC3339 datetime.
+// An empty limit means an unbounded range.
+type Period struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Corpora []string `json:"corpora"`
+}
+
+// Conf maps categories of records (e.g. a tool used) to corpora periods.
+// Categories are defined by individual transformers.
+type Conf map[string][]Period
+
+// Validate checks all the periods for format errors and overlaps
+func (conf Conf) Validate() error {
+	_, err := NewAssignments(conf)
+	return err
+}
+
+type compiledPeriod struct {
+	from    time.Time
+	to      time.Time
+	corpora []string
+}
+
+func (p compiledPeriod) contains(t time.Time) bool {
+	return (p.from.IsZero() || !t.Before(p.from)) && (p.to.IsZero() || t.Before(p.to))
+}
+
+func parseLimit(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(dateLayout, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// Assignments provides corpora valid at a specified time
+// for individual categories of records
+type Assignments struct {
+	categories map[string][]compiledPeriod
+}
+
+// Get returns corpora of a category valid at time t. In case no
+// period matches, nil is returned.
+func (a *Assignments) Get(category string, t time.Time) []string {
+	if a == nil {
+		return nil
+	}
+	for _, p := range a.categories[category] {
+		if p.contains(t) {
+			return p.corpora
+		}
+	}
+	return nil
+}
+
+// Has tells whether there are any periods defined for a category
+func (a *Assignments) Has(category string) bool {
+	return a != nil && len(a.categories[category]) > 0
+}
+
+// NewAssignments creates Assignments out of a configuration.
+// Periods of a single category must not overlap.
+func NewAssignments(conf Conf) (*Assignments, error) {
+	ans := &Assignments{categories: make(map[string][]compiledPeriod)}
+	for category, periods := range conf {
+		compiled := make([]compiledPeriod, 0, len(periods))
+		for i, p := range periods {
+			from, err := parseLimit(p.From)
+			if err != nil {
+				return nil, fmt.Errorf("invalid corpora period %d of %s: %w", i, category, err)
+			}
+			to, err := parseLimit(p.To)
+			if err != nil {
+				return nil, fmt.Errorf("invalid corpora period %d of %s: %w", i, category, err)
+			}
+			if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+				return nil, fmt.Errorf("invalid corpora period %d of %s: empty time range", i, category)
+			}
+			if len(p.Corpora) == 0 {
+				return nil, fmt.Errorf("invalid corpora period %d of %s: no corpora", i, category)
+			}
+			compiled = append(compiled, compiledPeriod{from: from, to: to, corpora: p.Corpora})
+		}
+		sort.Slice(compiled, func(i, j int) bool {
+			return compiled[i].from.Before(compiled[j].from)
+		})
+		for i := 1; i < len(compiled); i++ {
+			prev := compiled[i-1]
+			if prev.to.IsZero() || compiled[i].from.Before(prev.to) {
+				return nil, fmt.Errorf("invalid corpora periods of %s: overlapping time ranges", category)
+			}
+		}
+		ans.categories[category] = compiled
+	}
+	return ans, nil
+}
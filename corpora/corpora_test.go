@@ -0,0 +1,71 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpora
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignmentsGet(t *testing.T) {
+	a, err := NewAssignments(Conf{
+		"sync": {
+			{From: "2025-01-01", Corpora: []string{"syn2020"}},
+			{To: "2025-01-01", Corpora: []string{"syn2010", "oral_v2"}},
+		},
+		"dia": {
+			{From: "2024-06-01T12:00:00+02:00", To: "2025-01-01", Corpora: []string{"diakon"}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{"syn2010", "oral_v2"},
+		a.Get("sync", time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)),
+	)
+	assert.Equal(t, []string{"syn2020"}, a.Get("sync", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, a.Get("dia", time.Date(2024, 6, 1, 9, 59, 0, 0, time.UTC)))
+	assert.Equal(t, []string{"diakon"}, a.Get("dia", time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+	assert.Nil(t, a.Get("other", time.Now()))
+	assert.True(t, a.Has("dia"))
+	assert.False(t, a.Has("other"))
+}
+
+func TestNilAssignments(t *testing.T) {
+	var a *Assignments
+	assert.Nil(t, a.Get("sync", time.Now()))
+	assert.False(t, a.Has("sync"))
+}
+
+func TestOverlappingPeriods(t *testing.T) {
+	conf := Conf{
+		"sync": {
+			{From: "2024-01-01", Corpora: []string{"syn2020"}},
+			{From: "2023-01-01", To: "2024-02-01", Corpora: []string{"syn2010"}},
+		},
+	}
+	assert.Error(t, conf.Validate())
+}
+
+func TestInvalidPeriods(t *testing.T) {
+	assert.Error(t, Conf{"a": {{From: "2024-13-01", Corpora: []string{"x"}}}}.Validate())
+	assert.Error(t, Conf{"a": {{From: "2024-02-01", To: "2024-01-01", Corpora: []string{"x"}}}}.Validate())
+	assert.Error(t, Conf{"a": {{From: "2024-01-01"}}}.Validate())
+	assert.NoError(t, Conf{"a": {{Corpora: []string{"x"}}}}.Validate())
+}
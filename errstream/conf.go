@@ -0,0 +1,34 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errstream
+
+import "errors"
+
+// Conf configures writing of application errors as structured
+// documents to a separate Elasticsearch index
+type Conf struct {
+	// Index is the name of the Elasticsearch index for error records
+	Index string `json:"index"`
+}
+
+// Validate checks the configuration
+func (conf *Conf) Validate() error {
+	if conf.Index == "" {
+		return errors.New("invalid errorStream configuration: missing index")
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errstream
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// legacyErrorLineRegexp matches ERROR entries of the Python logging
+	// module as used by KonText up to 0.17 (the milliseconds part is ignored)
+	legacyErrorLineRegexp = regexp.MustCompile(
+		`^(\d{4}-\d{2}-\d{2}\s[012]\d:[0-5]\d:[0-5]\d)[\.,]\d+\s+\[([^\]]*)\]\s+ERROR:\s+(.*)$`)
+
+	exceptionMessageRegexp = regexp.MustCompile(`^([A-Za-z_][\w\.]*(?:Error|Exception)):\s*(.*)$`)
+
+	// volatileValuesRegexp matches values which typically differ between
+	// occurrences of the same error (quoted values, numbers, addresses)
+	volatileValuesRegexp = regexp.MustCompile(`'[^']*'|"[^"]*"|0x[0-9a-fA-F]+|\d+`)
+)
+
+// Entry is an application error entry extracted from a log
+type Entry struct {
+	Time          time.Time
+	Logger        string
+	ExceptionID   string
+	ExceptionType string
+	Message       string
+	Stack         []string
+}
+
+// Fingerprint identifies errors of the same origin. It is based on
+// the exception type and the stack (or the message if there is no stack),
+// with volatile values (numbers, quoted strings) removed.
+func (e Entry) Fingerprint() string {
+	var src strings.Builder
+	src.WriteString(e.ExceptionType)
+	src.WriteString("\n")
+	if len(e.Stack) > 0 {
+		for _, frame := range e.Stack {
+			src.WriteString(volatileValuesRegexp.ReplaceAllString(strings.TrimSpace(frame), "?"))
+			src.WriteString("\n")
+		}
+
+	} else {
+		src.WriteString(e.Logger)
+		src.WriteString("\n")
+		src.WriteString(volatileValuesRegexp.ReplaceAllString(e.Message, "?"))
+	}
+	sum := sha1.Sum([]byte(src.String()))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ParseLegacyErrorLine parses an ERROR line as logged by KonText
+// up to version 0.17 (e.g. `2019-06-25 14:04:50,012 [main] ERROR: ...`).
+// If the message starts with an exception name, it is used as the
+// exception type. As KonText logs local time without a zone, the time
+// is interpreted in loc.
+func ParseLegacyErrorLine(s string, loc *time.Location) (Entry, bool) {
+	srch := legacyErrorLineRegexp.FindStringSubmatch(s)
+	if srch == nil {
+		return Entry{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", srch[1], loc)
+	if err != nil {
+		return Entry{}, false
+	}
+	ans := Entry{Time: t, Logger: srch[2], Message: srch[3]}
+	if m := exceptionMessageRegexp.FindStringSubmatch(srch[3]); m != nil {
+		ans.ExceptionType = m[1]
+		ans.Message = m[2]
+	}
+	return ans, true
+}
+
+// Sink accepts extracted error entries
+type Sink interface {
+	Add(entry Entry)
+
+	// Location returns a configured time zone of the processed
+	// logs which is used to interpret times logged without a zone
+	Location() *time.Location
+}
+
+// NullSink is used in case no error stream is configured
+type NullSink struct{}
+
+func (ns NullSink) Add(entry Entry) {}
+
+func (ns NullSink) Location() *time.Location {
+	return time.Local
+}
+
+// SinkProvider is implemented by app factory environments
+// (see apps.Env) providing a sink for application errors
+type SinkProvider interface {
+	ErrorSink() Sink
+}
+
+// GetSink returns a sink provided by env or NullSink
+// in case env does not provide any
+func GetSink(env any) Sink {
+	if sp, ok := env.(SinkProvider); ok && sp.ErrorSink() != nil {
+		return sp.ErrorSink()
+	}
+	return NullSink{}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLegacyErrorLine(t *testing.T) {
+	e, ok := ParseLegacyErrorLine(
+		`2019-06-25 14:04:50,012 [controller] ERROR: KeyError: 'corpname'`, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2019, 6, 25, 14, 4, 50, 0, time.UTC), e.Time)
+	assert.Equal(t, "controller", e.Logger)
+	assert.Equal(t, "KeyError", e.ExceptionType)
+	assert.Equal(t, "'corpname'", e.Message)
+
+	e, ok = ParseLegacyErrorLine(`2019-06-25 14:04:50.012 [main] ERROR: failed to load corpus`, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, "", e.ExceptionType)
+	assert.Equal(t, "failed to load corpus", e.Message)
+
+	_, ok = ParseLegacyErrorLine(`2019-06-25 14:04:50,012 [main] INFO: all fine`, time.UTC)
+	assert.False(t, ok)
+}
+
+func TestParseLegacyErrorLineLocalTime(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Prague")
+	assert.NoError(t, err)
+	e, ok := ParseLegacyErrorLine(`2019-06-25 14:04:50,012 [main] ERROR: failed to load corpus`, loc)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2019, 6, 25, 12, 4, 50, 0, time.UTC), e.Time.UTC())
+}
+
+func TestFingerprintIgnoresVolatileValues(t *testing.T) {
+	e1 := Entry{ExceptionType: "KeyError", Message: "item 'foo' not found at 0x7f00ab"}
+	e2 := Entry{ExceptionType: "KeyError", Message: "item 'bar' not found at 0x7f11cd"}
+	e3 := Entry{ExceptionType: "ValueError", Message: "item 'bar' not found at 0x7f11cd"}
+	assert.Equal(t, e1.Fingerprint(), e2.Fingerprint())
+	assert.NotEqual(t, e1.Fingerprint(), e3.Fingerprint())
+	assert.Len(t, e1.Fingerprint(), 16)
+}
+
+func TestFingerprintUsesStack(t *testing.T) {
+	stack1 := []string{`File "/opt/kontext/lib/app.py", line 120, in run`, `File "/opt/kontext/lib/conc.py", line 33, in find`}
+	stack2 := []string{`File "/opt/kontext/lib/app.py", line 125, in run`, `File "/opt/kontext/lib/conc.py", line 34, in find`}
+	e1 := Entry{ExceptionType: "ConcError", Message: "query 1 failed", Stack: stack1}
+	e2 := Entry{ExceptionType: "ConcError", Message: "another message", Stack: stack2}
+	assert.Equal(t, e1.Fingerprint(), e2.Fingerprint())
+}
+
+func TestNewOutputRecord(t *testing.T) {
+	e := Entry{
+		Time:          time.Date(2026, 3, 1, 9, 11, 12, 0, time.UTC),
+		ExceptionType: "KeyError",
+		Message:       "'corpname'",
+	}
+	rec := NewOutputRecord(e, "kontext", "0.15")
+	assert.Equal(t, RecordType, rec.Type)
+	assert.Equal(t, "kontext", rec.Props["app"])
+	assert.Equal(t, "0.15", rec.Props["version"])
+	assert.Equal(t, e.Fingerprint(), rec.Props["fingerprint"])
+	assert.Equal(t, "2026-03-01T09:11:12Z", rec.Datetime)
+	assert.NotEmpty(t, rec.GetID())
+	assert.Equal(t, rec.GetID(), NewOutputRecord(e, "kontext", "0.15").GetID())
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errstream
+
+import (
+	"klogproc/servicelog/custom"
+)
+
+const (
+	// RecordType is the `type` property of error records
+	RecordType = "error"
+)
+
+// NewOutputRecord creates an error document for an entry
+// of a specific application
+func NewOutputRecord(entry Entry, appType, version string) *custom.OutputRecord {
+	rec := &custom.OutputRecord{
+		Type:        RecordType,
+		IsAnonymous: true,
+		Props: map[string]any{
+			"app":           appType,
+			"version":       version,
+			"logger":        entry.Logger,
+			"exceptionType": entry.ExceptionType,
+			"message":       entry.Message,
+			"fingerprint":   entry.Fingerprint(),
+		},
+	}
+	if entry.ExceptionID != "" {
+		rec.Props["exceptionId"] = entry.ExceptionID
+	}
+	if len(entry.Stack) > 0 {
+		rec.Props["stack"] = entry.Stack
+	}
+	rec.SetTime(entry.Time)
+	rec.ID = rec.GenerateDeterministicID()
+	return rec
+}
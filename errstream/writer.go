@@ -0,0 +1,135 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errstream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/czcorpus/klogproc-core/save"
+	"github.com/czcorpus/klogproc-core/save/elastic"
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// Writer is a Sink writing error records to a configured
+// Elasticsearch index (or to stdout in the dry-run mode).
+// Records are written within sessions (see Start, Stop) so
+// they are flushed at the same pace as regular records.
+type Writer struct {
+	ctx      context.Context
+	esConf   elastic.ConnectionConf
+	appType  string
+	version  string
+	filePath string
+	location *time.Location
+	dryRun   bool
+	records  chan *storage.BoundOutputRecord
+	done     chan struct{}
+	mutex    sync.Mutex
+}
+
+// Add converts an entry into an error record and writes it
+func (w *Writer) Add(entry Entry) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.records == nil {
+		log.Warn().
+			Str("appType", w.appType).
+			Str("message", entry.Message).
+			Msg("no active error stream session, ignoring error entry")
+		return
+	}
+	w.records <- &storage.BoundOutputRecord{
+		Rec:      NewOutputRecord(entry, w.appType, w.version),
+		FilePath: w.filePath,
+	}
+}
+
+// Location returns a time zone used to interpret
+// local times of parsed error entries
+func (w *Writer) Location() *time.Location {
+	return w.location
+}
+
+// Start opens a new write session
+func (w *Writer) Start() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.records != nil {
+		return
+	}
+	w.records = make(chan *storage.BoundOutputRecord, w.esConf.PushChunkSize*2)
+	w.done = make(chan struct{})
+	go func(records chan *storage.BoundOutputRecord, done chan struct{}) {
+		if w.dryRun {
+			for confirm := range save.RunWriteConsumer(w.ctx, records, true) {
+				if confirm.Error != nil {
+					log.Error().Err(confirm.Error).Msg("failed to write error record")
+				}
+			}
+
+		} else {
+			for confirm := range elastic.RunWriteConsumer(w.ctx, w.appType, &w.esConf, records) {
+				if confirm.Error != nil {
+					log.Error().Err(confirm.Error).Msg("failed to save error record to ElasticSearch database")
+				}
+			}
+		}
+		close(done)
+	}(w.records, w.done)
+}
+
+// Stop closes the current write session and waits
+// for all the pending records to be written
+func (w *Writer) Stop() {
+	w.mutex.Lock()
+	records, done := w.records, w.done
+	w.records = nil
+	w.mutex.Unlock()
+	if records == nil {
+		return
+	}
+	close(records)
+	<-done
+}
+
+// NewWriter creates a Writer for a log of a specific application.
+// The Elasticsearch connection is the same as for regular records,
+// only the index is replaced by the one from the configuration.
+func NewWriter(
+	ctx context.Context,
+	conf *Conf,
+	esConf elastic.ConnectionConf,
+	appType string,
+	version string,
+	filePath string,
+	location *time.Location,
+	dryRun bool,
+) *Writer {
+	esConf.Index = conf.Index
+	return &Writer{
+		ctx:      ctx,
+		esConf:   esConf,
+		appType:  appType,
+		version:  version,
+		filePath: filePath,
+		location: location,
+		dryRun:   dryRun,
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esmapping
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ConflictType specifies a kind of difference between
+// an expected and a live mapping
+type ConflictType string
+
+const (
+	// ConflictTypeMismatch means that a field is mapped with
+	// a different type than expected
+	ConflictTypeMismatch ConflictType = "type-mismatch"
+
+	// ConflictTypeMissing means that a field is not mapped in the live
+	// index (it will be mapped dynamically once indexed)
+	ConflictTypeMissing ConflictType = "missing"
+
+	// ConflictTypeExtra means that the live index maps a field
+	// which is not present in the output record
+	ConflictTypeExtra ConflictType = "extra"
+)
+
+// Conflict describes a difference in mapping of a single field
+type Conflict struct {
+	Field    string       `json:"field"`
+	Type     ConflictType `json:"type"`
+	Expected string       `json:"expected,omitempty"`
+	Actual   string       `json:"actual,omitempty"`
+}
+
+func (c Conflict) String() string {
+	switch c.Type {
+	case ConflictTypeMismatch:
+		return fmt.Sprintf("%s: expected %s, found %s", c.Field, c.Expected, c.Actual)
+	case ConflictTypeMissing:
+		return fmt.Sprintf("%s: missing (expected %s)", c.Field, c.Expected)
+	case ConflictTypeExtra:
+		return fmt.Sprintf("%s: not in output record (found %s)", c.Field, c.Actual)
+	}
+	return c.Field
+}
+
+// IsError tells whether the conflict may cause indexing problems
+// or wrong query results (i.e. it is not just an informative difference)
+func (c Conflict) IsError() bool {
+	return c.Type == ConflictTypeMismatch
+}
+
+// Flatten converts nested properties into a map of dotted field paths
+// and their types. Objects without a type are reported as "object".
+func Flatten(props map[string]*Property) map[string]string {
+	ans := make(map[string]string)
+	flatten("", props, ans)
+	return ans
+}
+
+func flatten(prefix string, props map[string]*Property, ans map[string]string) {
+	for name, p := range props {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		tp := p.Type
+		if tp == "" {
+			tp = "object"
+		}
+		ans[path] = tp
+		if len(p.Properties) > 0 {
+			flatten(path, p.Properties, ans)
+		}
+	}
+}
+
+func isDynamicObject(p *Property) bool {
+	if p == nil {
+		return false
+	}
+	switch v := p.Dynamic.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Compare compares an expected mapping (generated from an output record)
+// with a live one. Fields inside dynamic objects of the expected mapping
+// are not reported. The result is sorted by field names.
+func Compare(expected, live Mappings) []Conflict {
+	exp := Flatten(expected.Properties)
+	act := Flatten(live.Properties)
+	dynPrefixes := make([]string, 0, 5)
+	var collectDynamic func(prefix string, props map[string]*Property)
+	collectDynamic = func(prefix string, props map[string]*Property) {
+		for name, p := range props {
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			if isDynamicObject(p) {
+				dynPrefixes = append(dynPrefixes, path+".")
+			}
+			collectDynamic(path, p.Properties)
+		}
+	}
+	collectDynamic("", expected.Properties)
+	inDynamic := func(path string) bool {
+		for _, prefix := range dynPrefixes {
+			if len(path) > len(prefix) && path[:len(prefix)] == prefix {
+				return true
+			}
+		}
+		return false
+	}
+
+	ans := make([]Conflict, 0, 10)
+	for field, tp := range exp {
+		actTp, ok := act[field]
+		if !ok {
+			ans = append(ans, Conflict{Field: field, Type: ConflictTypeMissing, Expected: tp})
+
+		} else if actTp != tp {
+			ans = append(ans, Conflict{Field: field, Type: ConflictTypeMismatch, Expected: tp, Actual: actTp})
+		}
+	}
+	for field, tp := range act {
+		if _, ok := exp[field]; !ok && !inDynamic(field) {
+			ans = append(ans, Conflict{Field: field, Type: ConflictTypeExtra, Actual: tp})
+		}
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		return ans[i].Field < ans[j].Field
+	})
+	return ans
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esmapping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const liveMappingSrc = `{
+	"kontext-2026": {
+		"mappings": {
+			"properties": {
+				"type": {"type": "keyword"},
+				"datetime": {"type": "date"},
+				"procTime": {"type": "float"},
+				"corpora": {"type": "text", "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}},
+				"args": {"dynamic": "true", "properties": {"q": {"type": "text"}}},
+				"geoip": {"properties": {"location": {"type": "geo_point"}, "latitude": {"type": "float"}}},
+				"legacy": {"type": "keyword"}
+			}
+		}
+	}
+}`
+
+const liveMappingTypedSrc = `{
+	"kontext": {"mappings": {"_doc": {"properties": {"type": {"type": "keyword"}}}}}
+}`
+
+func TestParseLiveMappingTyped(t *testing.T) {
+	m, err := parseLiveMapping([]byte(liveMappingTypedSrc))
+	assert.NoError(t, err)
+	assert.Equal(t, "keyword", m.Properties["type"].Type)
+}
+
+func TestCompare(t *testing.T) {
+	live, err := parseLiveMapping([]byte(liveMappingSrc))
+	assert.NoError(t, err)
+	conflicts := Compare(FromValue(&testOutput{}), live)
+	assert.Equal(
+		t,
+		[]Conflict{
+			{Field: "corpora", Type: ConflictTypeMismatch, Expected: "keyword", Actual: "text"},
+			{Field: "geoip.country_name", Type: ConflictTypeMissing, Expected: "keyword"},
+			{Field: "isQuery", Type: ConflictTypeMissing, Expected: "boolean"},
+			{Field: "legacy", Type: ConflictTypeExtra, Actual: "keyword"},
+			{Field: "limit", Type: ConflictTypeMissing, Expected: "long"},
+			{Field: "numHits", Type: ConflictTypeMissing, Expected: "long"},
+			{Field: "procTime", Type: ConflictTypeMismatch, Expected: "double", Actual: "float"},
+		},
+		conflicts,
+	)
+	assert.True(t, conflicts[0].IsError())
+	assert.False(t, conflicts[1].IsError())
+}
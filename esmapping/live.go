@@ -0,0 +1,90 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esmapping
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// liveIndexMapping covers both the typeless (ES 7+) mapping format
+// and the older one with a single mapping type
+type liveIndexMapping struct {
+	Mappings map[string]json.RawMessage `json:"mappings"`
+}
+
+// parseLiveMapping extracts properties from a response
+// of the `GET /[index]/_mapping` API
+func parseLiveMapping(data []byte) (Mappings, error) {
+	var resp map[string]liveIndexMapping
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return Mappings{}, err
+	}
+	ans := Mappings{Properties: make(map[string]*Property)}
+	for _, idx := range resp {
+		if props, ok := idx.Mappings["properties"]; ok {
+			if err := json.Unmarshal(props, &ans.Properties); err != nil {
+				return Mappings{}, err
+			}
+			continue
+		}
+		for _, typeMapping := range idx.Mappings {
+			var tm Mappings
+			if err := json.Unmarshal(typeMapping, &tm); err != nil {
+				return Mappings{}, err
+			}
+			for k, v := range tm.Properties {
+				ans.Properties[k] = v
+			}
+		}
+	}
+	return ans, nil
+}
+
+// FetchMapping loads a mapping of an existing index (or indices
+// matching a pattern - in such case, the mappings are merged)
+func FetchMapping(ctx context.Context, server, index string) (Mappings, error) {
+	url := fmt.Sprintf("%s/%s/_mapping", strings.TrimRight(server, "/"), index)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Mappings{}, fmt.Errorf("failed to fetch index mapping: %w", err)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Mappings{}, fmt.Errorf("failed to fetch index mapping: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Mappings{}, fmt.Errorf("failed to fetch index mapping: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Mappings{}, fmt.Errorf(
+			"failed to fetch index mapping: elasticsearch responded with %s: %s", resp.Status, string(data))
+	}
+	ans, err := parseLiveMapping(data)
+	if err != nil {
+		return Mappings{}, fmt.Errorf("failed to parse index mapping: %w", err)
+	}
+	return ans, nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package esmapping generates Elasticsearch index templates from Go types
+// of output records and compares them with live index mappings.
+package esmapping
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// geoPointField is a JSON name of a [lon, lat] pair
+	// which is mapped as a geo_point
+	geoPointField = "location"
+
+	// datetimeField is a JSON name of a record time. Output records
+	// store it as an RFC3339 string so it must be mapped as a date
+	// explicitly
+	datetimeField = "datetime"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Property is a mapping of a single field. For objects, it contains
+// nested properties.
+type Property struct {
+	Type       string               `json:"type,omitempty"`
+	Properties map[string]*Property `json:"properties,omitempty"`
+
+	// Dynamic is either a bool or a string in live mappings
+	Dynamic any `json:"dynamic,omitempty"`
+}
+
+// Mappings is a root of an index mapping
+type Mappings struct {
+	Properties map[string]*Property `json:"properties"`
+}
+
+// IndexTemplate is a composable index template as accepted
+// by the `_index_template` API
+type IndexTemplate struct {
+	IndexPatterns []string       `json:"index_patterns"`
+	Priority      int            `json:"priority,omitempty"`
+	Template      templateBody   `json:"template"`
+	Meta          map[string]any `json:"_meta,omitempty"`
+}
+
+type templateBody struct {
+	Mappings Mappings `json:"mappings"`
+}
+
+func isGeoPoint(name string, t reflect.Type) bool {
+	if name != geoPointField {
+		return false
+	}
+	if t.Kind() != reflect.Array && t.Kind() != reflect.Slice {
+		return false
+	}
+	k := t.Elem().Kind()
+	return (t.Kind() == reflect.Slice || t.Len() == 2) &&
+		(k == reflect.Float32 || k == reflect.Float64)
+}
+
+type generator struct {
+	visiting map[reflect.Type]bool
+}
+
+func (g *generator) addFields(t reflect.Type, props map[string]*Property) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			g.addFields(ft, props)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if isGeoPoint(name, ft) {
+			props[name] = &Property{Type: "geo_point"}
+			continue
+		}
+		if name == datetimeField && ft.Kind() == reflect.String {
+			props[name] = &Property{Type: "date"}
+			continue
+		}
+		if p := g.propertyOf(ft); p != nil {
+			props[name] = p
+		}
+	}
+}
+
+// propertyOf returns a mapping of a type or nil if the type
+// cannot be mapped (e.g. an interface)
+func (g *generator) propertyOf(t reflect.Type) *Property {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Property{Type: "date"}
+	case t.Kind() == reflect.String:
+		return &Property{Type: "keyword"}
+	case t.Kind() == reflect.Bool:
+		return &Property{Type: "boolean"}
+	case t.Kind() == reflect.Int8:
+		return &Property{Type: "byte"}
+	case t.Kind() == reflect.Int16 || t.Kind() == reflect.Uint8:
+		return &Property{Type: "short"}
+	case t.Kind() == reflect.Int32 || t.Kind() == reflect.Uint16:
+		return &Property{Type: "integer"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &Property{Type: "long"}
+	case t.Kind() == reflect.Float32:
+		return &Property{Type: "float"}
+	case t.Kind() == reflect.Float64:
+		return &Property{Type: "double"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		// Elasticsearch has no array type - any field can contain
+		// multiple values
+		return g.propertyOf(t.Elem())
+	case t.Kind() == reflect.Map:
+		// keys are not known in advance so we let Elasticsearch
+		// map them dynamically
+		return &Property{Type: "object", Dynamic: true}
+	case t.Kind() == reflect.Struct:
+		if g.visiting[t] {
+			return nil
+		}
+		g.visiting[t] = true
+		props := make(map[string]*Property)
+		g.addFields(t, props)
+		delete(g.visiting, t)
+		return &Property{Properties: props}
+	}
+	return nil
+}
+
+// FromValue creates index mappings describing the type of v
+// as it is encoded to JSON.
+//
+// Strings are mapped as `keyword`, time.Time and a `datetime` string
+// as `date` and a `location` field containing a [lon, lat] pair
+// as `geo_point`.
+func FromValue(v any) Mappings {
+	g := &generator{visiting: make(map[reflect.Type]bool)}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ans := Mappings{Properties: make(map[string]*Property)}
+	if t.Kind() == reflect.Struct {
+		g.addFields(t, ans.Properties)
+	}
+	return ans
+}
+
+func mergeProperties(dst, src map[string]*Property) {
+	for name, p := range src {
+		curr, ok := dst[name]
+		if !ok {
+			// copy to keep the source mappings untouched
+			cp := *p
+			if p.Properties != nil {
+				cp.Properties = make(map[string]*Property)
+				mergeProperties(cp.Properties, p.Properties)
+			}
+			dst[name] = &cp
+			continue
+		}
+		if curr.Type == "" && p.Type == "" {
+			mergeProperties(curr.Properties, p.Properties)
+		}
+	}
+}
+
+// Merge creates mappings containing fields of all the provided
+// mappings. In case a field is mapped with different types,
+// the first mapping wins.
+func Merge(mappings ...Mappings) Mappings {
+	ans := Mappings{Properties: make(map[string]*Property)}
+	for _, m := range mappings {
+		mergeProperties(ans.Properties, m.Properties)
+	}
+	return ans
+}
+
+// NewIndexTemplate creates a composable index template for
+// output records of an application. As all the versions of
+// an application are typically stored in the same indices,
+// the template covers output records of all the provided
+// versions (see Merge).
+func NewIndexTemplate(appType, indexPattern string, versions []string, outputRecs []any) *IndexTemplate {
+	meta := map[string]any{
+		"generatedBy": "klogproc",
+		"appType":     appType,
+	}
+	if len(versions) > 0 {
+		meta["appVersions"] = versions
+	}
+	mappings := make([]Mappings, len(outputRecs))
+	for i, rec := range outputRecs {
+		mappings[i] = FromValue(rec)
+	}
+	return &IndexTemplate{
+		IndexPatterns: []string{indexPattern},
+		Template:      templateBody{Mappings: Merge(mappings...)},
+		Meta:          meta,
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esmapping
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"klogproc/servicelog/custom"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testGeo struct {
+	CountryName string     `json:"country_name"`
+	Latitude    float32    `json:"latitude"`
+	Location    [2]float32 `json:"location"`
+}
+
+type testBase struct {
+	ID   string `json:"-"`
+	Type string `json:"type"`
+}
+
+type testOutput struct {
+	testBase
+	Datetime time.Time         `json:"datetime"`
+	IsQuery  bool              `json:"isQuery"`
+	ProcTime float64           `json:"procTime"`
+	NumHits  int               `json:"numHits"`
+	Corpora  []string          `json:"corpora"`
+	Args     map[string]string `json:"args"`
+	GeoIP    testGeo           `json:"geoip"`
+	Limit    *int              `json:"limit,omitempty"`
+	Anything any               `json:"anything"`
+	internal string
+}
+
+func TestFromValue(t *testing.T) {
+	m := FromValue(&testOutput{})
+	p := m.Properties
+	assert.NotContains(t, p, "ID")
+	assert.NotContains(t, p, "internal")
+	assert.NotContains(t, p, "anything")
+	assert.Equal(t, "keyword", p["type"].Type)
+	assert.Equal(t, "date", p["datetime"].Type)
+	assert.Equal(t, "boolean", p["isQuery"].Type)
+	assert.Equal(t, "double", p["procTime"].Type)
+	assert.Equal(t, "long", p["numHits"].Type)
+	assert.Equal(t, "keyword", p["corpora"].Type)
+	assert.Equal(t, "object", p["args"].Type)
+	assert.Equal(t, true, p["args"].Dynamic)
+	assert.Equal(t, "long", p["limit"].Type)
+	assert.Equal(t, "", p["geoip"].Type)
+	assert.Equal(t, "keyword", p["geoip"].Properties["country_name"].Type)
+	assert.Equal(t, "float", p["geoip"].Properties["latitude"].Type)
+	assert.Equal(t, "geo_point", p["geoip"].Properties["location"].Type)
+}
+
+func TestFromValueStringDatetime(t *testing.T) {
+	m := FromValue(&custom.OutputRecord{})
+	p := m.Properties
+	assert.Equal(t, "date", p["datetime"].Type)
+	assert.Equal(t, "keyword", p["type"].Type)
+	assert.Equal(t, "keyword", p["userId"].Type)
+	assert.Equal(t, "geo_point", p["geoip"].Properties["location"].Type)
+}
+
+type testOutputV2 struct {
+	testBase
+	Datetime string  `json:"datetime"`
+	IsQuery  string  `json:"isQuery"`
+	Status   int     `json:"status"`
+	GeoIP    testGeo `json:"geoip"`
+	Region   struct {
+		Name string `json:"name"`
+	} `json:"region"`
+}
+
+func TestMerge(t *testing.T) {
+	m1 := FromValue(&testOutput{})
+	m2 := FromValue(&testOutputV2{})
+	merged := Merge(m1, m2)
+	p := merged.Properties
+	assert.Equal(t, "date", p["datetime"].Type)
+	assert.Equal(t, "boolean", p["isQuery"].Type)
+	assert.Equal(t, "long", p["numHits"].Type)
+	assert.Equal(t, "long", p["status"].Type)
+	assert.Equal(t, "keyword", p["region"].Properties["name"].Type)
+	assert.Equal(t, "geo_point", p["geoip"].Properties["location"].Type)
+	assert.NotContains(t, m1.Properties, "status")
+}
+
+func TestNewIndexTemplate(t *testing.T) {
+	tpl := NewIndexTemplate(
+		"kontext", "kontext-*", []string{"0.17", "0.18"}, []any{&testOutput{}, &testOutputV2{}})
+	data, err := json.Marshal(tpl)
+	assert.NoError(t, err)
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []any{"kontext-*"}, decoded["index_patterns"])
+	props := decoded["template"].(map[string]any)["mappings"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "date"}, props["datetime"])
+	assert.Equal(t, map[string]any{"type": "long"}, props["status"])
+	assert.Equal(t, []any{"0.17", "0.18"}, decoded["_meta"].(map[string]any)["appVersions"])
+}
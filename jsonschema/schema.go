@@ -0,0 +1,151 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonschema generates JSON Schema (draft 2020-12) documents
+// describing Go types as they are encoded by encoding/json.
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// Draft is the JSON Schema version of generated documents
+	Draft = "https://json-schema.org/draft/2020-12/schema"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema is a subset of JSON Schema sufficient to describe
+// JSON-encoded Go values
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// parseTag returns the JSON name of a field and whether
+// the field should be skipped
+func parseTag(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+type generator struct {
+	// visiting prevents infinite recursion in case of recursive types
+	visiting map[reflect.Type]bool
+}
+
+func (g *generator) addFields(t reflect.Type, props map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := parseTag(field)
+		if skip {
+			continue
+		}
+		ft := field.Type
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				// promoted fields
+				g.addFields(ft, props)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		props[name] = g.schemaOf(field.Type)
+	}
+}
+
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	ans := &Schema{}
+	switch {
+	case t == timeType:
+		ans.Type = "string"
+		ans.Format = "date-time"
+	case t.Kind() == reflect.String:
+		ans.Type = "string"
+	case t.Kind() == reflect.Bool:
+		ans.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		ans.Type = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		ans.Type = "number"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// []byte is encoded as a base64 string
+		ans.Type = "string"
+		ans.Format = "byte"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		ans.Type = "array"
+		ans.Items = g.schemaOf(t.Elem())
+		nullable = nullable || t.Kind() == reflect.Slice
+	case t.Kind() == reflect.Map:
+		ans.Type = "object"
+		ans.AdditionalProperties = g.schemaOf(t.Elem())
+		nullable = true
+	case t.Kind() == reflect.Struct:
+		ans.Type = "object"
+		if !g.visiting[t] {
+			g.visiting[t] = true
+			ans.Properties = make(map[string]*Schema)
+			g.addFields(t, ans.Properties)
+			delete(g.visiting, t)
+		}
+	default:
+		// interfaces and other types accept any value
+		return ans
+	}
+	if nullable {
+		ans.Type = []string{ans.Type.(string), "null"}
+	}
+	return ans
+}
+
+// FromValue creates a schema describing the type of v. In case v
+// is a pointer, the schema describes the pointed value.
+func FromValue(v any, title string) *Schema {
+	g := &generator{visiting: make(map[reflect.Type]bool)}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ans := g.schemaOf(t)
+	ans.Schema = Draft
+	ans.Title = title
+	return ans
+}
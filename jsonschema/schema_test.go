@@ -0,0 +1,80 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBase struct {
+	Level string `json:"level"`
+}
+
+type testNode struct {
+	Name     string      `json:"name"`
+	Children []*testNode `json:"children"`
+}
+
+type testRecord struct {
+	testBase
+	Time     time.Time         `json:"time"`
+	Latency  float64           `json:"latency,omitempty"`
+	Status   int               `json:"status"`
+	IsQuery  bool              `json:"isQuery"`
+	Args     map[string]string `json:"args"`
+	Corpora  []string          `json:"corpora"`
+	Limit    *int              `json:"limit"`
+	Tree     testNode          `json:"tree"`
+	Any      any               `json:"any"`
+	NoTag    string
+	Ignored  string `json:"-"`
+	internal string
+}
+
+func TestFromValue(t *testing.T) {
+	s := FromValue(&testRecord{}, "test")
+	assert.Equal(t, Draft, s.Schema)
+	assert.Equal(t, "test", s.Title)
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, "string", s.Properties["level"].Type)
+	assert.Equal(t, "date-time", s.Properties["time"].Format)
+	assert.Equal(t, "number", s.Properties["latency"].Type)
+	assert.Equal(t, "integer", s.Properties["status"].Type)
+	assert.Equal(t, "boolean", s.Properties["isQuery"].Type)
+	assert.Equal(t, []string{"object", "null"}, s.Properties["args"].Type)
+	assert.Equal(t, "string", s.Properties["args"].AdditionalProperties.Type)
+	assert.Equal(t, []string{"array", "null"}, s.Properties["corpora"].Type)
+	assert.Equal(t, "string", s.Properties["corpora"].Items.Type)
+	assert.Equal(t, []string{"integer", "null"}, s.Properties["limit"].Type)
+	assert.Nil(t, s.Properties["any"].Type)
+	assert.Contains(t, s.Properties, "NoTag")
+	assert.NotContains(t, s.Properties, "Ignored")
+	assert.NotContains(t, s.Properties, "internal")
+
+	tree := s.Properties["tree"]
+	assert.Equal(t, "string", tree.Properties["name"].Type)
+	// recursive type is not expanded
+	assert.Equal(t, []string{"object", "null"}, tree.Properties["children"].Items.Type)
+	assert.Nil(t, tree.Properties["children"].Items.Properties)
+
+	_, err := json.Marshal(s)
+	assert.NoError(t, err)
+}
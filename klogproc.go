@@ -36,6 +36,7 @@ import (
 
 	"klogproc/config"
 	"klogproc/load/batch"
+	"klogproc/load/throttle"
 	"klogproc/notifications"
 
 	"github.com/czcorpus/klogproc-core/save/elastic"
@@ -210,6 +211,20 @@ func main() {
 	batchCmd.StringVar(&procOpts.scriptPath, "script-path", "", "Set or override Lua script path for log processing")
 	noScript := batchCmd.Bool("no-script", false, "disables Lua script for log processing (overrides both cmd arg and json conf)")
 	batchCmd.BoolVar(&procOpts.analysisOnly, "analysis-only", false, "In batch mode, analyze logs for bots etc.")
+	batchCmd.StringVar(&procOpts.reportPath, "report-path", "", "Set or override path of a JSON report with batch processing counters")
+	batchCmd.StringVar(&procOpts.analysisReportDir, "analysis-dir", "", "Set or override a directory for bot analysis reports (with -analysis-only)")
+	batchCmd.Float64Var(&procOpts.sampleRate, "sample", 0, "Process only a deterministic subset of records (e.g. 0.01 for 1%)")
+	batchCmd.StringVar(&procOpts.sampleSeed, "sample-seed", "", "Seed for the -sample mode (different seeds select different subsets)")
+	batchCmd.StringVar(&procOpts.sampleKey, "sample-key", "ip", "Record attribute used to select samples (ip, user, record)")
+	batchCmd.StringVar(&procOpts.maxRate, "max-rate", "", "Limit writing to Elasticsearch to N documents per second (or Nc chunks per second)")
+	batchCmd.StringVar(&procOpts.botPatternsPath, "bot-patterns", "", "Set or override a path to known bots definitions (e.g. bots.default.json)")
+
+	reconcileCmd := flag.NewFlagSet(config.ActionReconcile, flag.ExitOnError)
+	reconcileCmd.BoolVar(&procOpts.dryRun, "dry-run", false, "Only report differences, do not re-index anything (even with -reindex)")
+	reconcileFromTimestamp := reconcileCmd.String("from-time", "", "Reconcile only the records with datetime greater or equal to this time (UNIX timestamp, or YYYY-MM-DDTHH:mm:ss\u00B1hh:mm)")
+	reconcileToTimestamp := reconcileCmd.String("to-time", "", "Reconcile only the records with datetime less or equal to this time (UNIX timestamp, or YYYY-MM-DDTHH:mm:ss\u00B1hh:mm)")
+	reconcileCmd.BoolVar(&procOpts.reindex, "reindex", false, "Write missing records to Elasticsearch")
+	reconcileCmd.StringVar(&procOpts.reportPath, "report-path", "", "Save a JSON report with missing and unexpected IDs per day")
 
 	tailCmd := flag.NewFlagSet(config.ActionTail, flag.ExitOnError)
 	tailCmd.BoolVar(&procOpts.dryRun, "dry-run", false, "Do not write data anywhere, just print them")
@@ -233,6 +248,28 @@ func main() {
 	testnotifCmd := flag.NewFlagSet(config.ActionTestNotification, flag.ExitOnError)
 
 	mkscriptCmd := flag.NewFlagSet(config.ActionMkScript, flag.ExitOnError)
+	mkscriptDefs := mkscriptCmd.Bool("defs", false, "Generate LuaLS (EmmyLua) type definitions instead of a script stub")
+	mkscriptOutput := mkscriptCmd.String("output", "", "Write type definitions to a file instead of stdout (with -defs)")
+
+	schemaCmd := flag.NewFlagSet(config.ActionSchema, flag.ExitOnError)
+	schemaOutputDir := schemaCmd.String("output-dir", "", "Write each schema to a separate file in the directory instead of stdout")
+
+	mkmappingCmd := flag.NewFlagSet(config.ActionMkMapping, flag.ExitOnError)
+	mkmappingIndexPattern := mkmappingCmd.String("index-pattern", defaultIndexPattern, "Index pattern of generated templates ({app} is replaced by an app type)")
+	mkmappingOutputDir := mkmappingCmd.String("output-dir", "", "Write each template to a separate file in the directory instead of stdout")
+
+	checkMappingCmd := flag.NewFlagSet(config.ActionCheckMapping, flag.ExitOnError)
+	checkMappingAppType := checkMappingCmd.String("app-type", "", "Set app type to check (default: the one from logFiles configuration)")
+	checkMappingVersion := checkMappingCmd.String("version", "", "Set app version to check (default: the one from logFiles configuration)")
+
+	appsCmd := flag.NewFlagSet(config.ActionApps, flag.ExitOnError)
+
+	var scriptTestOpts scriptTestOptions
+	scriptTestCmd := flag.NewFlagSet(config.ActionScriptTest, flag.ExitOnError)
+	scriptTestCmd.StringVar(&scriptTestOpts.fixturesDir, "fixtures", "", "A directory with expected script outputs (line-NNNNNN.json)")
+	scriptTestCmd.BoolVar(&scriptTestOpts.updateFixtures, "update-fixtures", false, "Store current script outputs as new fixtures")
+	scriptTestCmd.BoolVar(&scriptTestOpts.diffOnly, "diff-only", false, "Print only lines where script output differs from the default one")
+	scriptTestCmd.IntVar(&scriptTestOpts.maxLines, "max-lines", 0, "Process at most N lines of the log file")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Klogproc - an utility for processing CNC application logs\n\n"+
@@ -242,12 +279,20 @@ func main() {
 			"\t%s docupdate [options] [config.json]\n"+
 			"\t%s docremove [options] [config.json]\n"+
 			"\t%s keyremove [options] [config.json]\n"+
+			"\t%s reconcile [options] [config.json]\n"+
 			"\t%s snapshot [options] [config.json] [list/create/remove/restore] [snapshot name]\n"+
 			"\t%s test-nofification [options] [config.json]\n"+
-			"\t%s mkscript [options] [config.json]\n"+
+			"\t%s mkscript [options] [app type] [version]\n"+
+			"\t%s script-test [options] [app type] [version] [script.lua] [sample.log]\n"+
+			"\t%s schema [options] [app type] [version]\n"+
+			"\t%s mkmapping [options] [app type] [version]\n"+
+			"\t%s check-mapping [options] [config.json]\n"+
+			"\t%s apps [app type]\n"+
 			"\t%s version\n",
 			filepath.Base(os.Args[0]), filepath.Base(os.Args[0]), filepath.Base(os.Args[0]),
 			filepath.Base(os.Args[0]), filepath.Base(os.Args[0]), filepath.Base(os.Args[0]),
+			filepath.Base(os.Args[0]), filepath.Base(os.Args[0]), filepath.Base(os.Args[0]),
+			filepath.Base(os.Args[0]), filepath.Base(os.Args[0]), filepath.Base(os.Args[0]),
 			filepath.Base(os.Args[0]), filepath.Base(os.Args[0]), filepath.Base(os.Args[0]))
 	}
 	flag.Parse()
@@ -292,6 +337,21 @@ func main() {
 		} else if procOpts.scriptPath != "" {
 			conf.LogFiles.ScriptPath = procOpts.scriptPath
 		}
+		if procOpts.reportPath != "" {
+			conf.LogFiles.ReportPath = procOpts.reportPath
+		}
+		if procOpts.analysisReportDir != "" {
+			conf.LogFiles.AnalysisReportDir = procOpts.analysisReportDir
+		}
+		if procOpts.botPatternsPath != "" {
+			conf.LogFiles.BotPatternsPath = procOpts.botPatternsPath
+		}
+		if procOpts.maxRate != "" {
+			conf.LogFiles.Throttle, err = throttle.ParseRate(procOpts.maxRate)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to parse -max-rate")
+			}
+		}
 		geoDb, err := geoip2.Open(conf.GeoIPDbPath)
 		if err != nil {
 			log.Fatal().Err(err).Msg("failed to open geo IP database")
@@ -300,6 +360,19 @@ func main() {
 		finish := make(chan bool)
 		go runBatchAction(conf, procOpts, geoDb, finish)
 		<-finish
+	case config.ActionReconcile:
+		reconcileCmd.Parse(os.Args[2:])
+		conf = setup(reconcileCmd.Arg(0), action)
+		procOpts.datetimeRange, err = batch.NewDateTimeRange(reconcileFromTimestamp, reconcileToTimestamp)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to parse command line date range")
+		}
+		geoDb, err := geoip2.Open(conf.GeoIPDbPath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to open geo IP database")
+		}
+		defer geoDb.Close()
+		runReconcileAction(conf, procOpts, geoDb)
 	case config.ActionTail:
 		tailCmd.Parse(os.Args[2:])
 		conf = setup(tailCmd.Arg(0), action)
@@ -324,13 +397,88 @@ func main() {
 			map[string]any{"app": "klogproc", "dt": time.Now().In(conf.TimezoneLocation())},
 			"This is just a testing notification triggered by running `klogproc test-notification`",
 		)
+	case config.ActionScriptTest:
+		scriptTestCmd.Parse(os.Args[2:])
+		if scriptTestCmd.NArg() != 4 {
+			fmt.Println("script-test requires arguments: [app type] [version] [script.lua] [sample.log]")
+			os.Exit(1)
+		}
+		ok, err := runScriptTestAction(
+			os.Stdout,
+			scriptTestCmd.Arg(0),
+			scriptTestCmd.Arg(1),
+			scriptTestCmd.Arg(2),
+			scriptTestCmd.Arg(3),
+			scriptTestOpts,
+		)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if !ok {
+			os.Exit(1)
+		}
 	case config.ActionMkScript:
 		mkscriptCmd.Parse(os.Args[2:])
-		if err := generateLuaStub(mkscriptCmd.Arg(0), mkscriptCmd.Arg(1)); err != nil {
+		if *mkscriptDefs {
+			err = generateLuaDefs(mkscriptCmd.Arg(0), mkscriptCmd.Arg(1), *mkscriptOutput)
+
+		} else {
+			err = generateLuaStub(mkscriptCmd.Arg(0), mkscriptCmd.Arg(1))
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+	case config.ActionSchema:
+		schemaCmd.Parse(os.Args[2:])
+		if err := generateSchemas(schemaCmd.Arg(0), schemaCmd.Arg(1), *schemaOutputDir); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+	case config.ActionApps:
+		appsCmd.Parse(os.Args[2:])
+		if err := runAppsAction(os.Stdout, appsCmd.Arg(0)); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+	case config.ActionMkMapping:
+		mkmappingCmd.Parse(os.Args[2:])
+		err = generateIndexTemplates(
+			mkmappingCmd.Arg(0), mkmappingCmd.Arg(1), *mkmappingIndexPattern, *mkmappingOutputDir)
+		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 
+	case config.ActionCheckMapping:
+		checkMappingCmd.Parse(os.Args[2:])
+		conf = setup(checkMappingCmd.Arg(0), action)
+		appType, version := *checkMappingAppType, *checkMappingVersion
+		if appType == "" {
+			if conf.LogFiles == nil {
+				log.Fatal().Msg("No app-type found - use cmd arg. -app-type or a single application config for batch processing")
+			}
+			// all the files are sampled as there is no worklog involved
+			if err := batch.ResolveVersion(conf.LogFiles, -1); err != nil {
+				log.Fatal().Err(err).Msg("failed to run check-mapping action")
+			}
+			appType = conf.LogFiles.AppType
+			version = conf.LogFiles.Version
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		ok, err := runCheckMappingAction(ctx, os.Stdout, conf, appType, version)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to run check-mapping action")
+		}
+		if !ok {
+			os.Exit(1)
+		}
+
 	case config.ActionSnapshot:
 		snapshotCmd.Parse(os.Args[2:])
 		conf = setup(snapshotCmd.Arg(0), action)
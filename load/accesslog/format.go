@@ -0,0 +1,308 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// apacheDirectives maps Apache LogFormat directives to names
+// of equivalent nginx variables
+var apacheDirectives = map[byte]string{
+	'a': "remote_addr",
+	'h': "remote_addr",
+	'l': "remote_ident",
+	'u': "remote_user",
+	't': "time_apache",
+	'r': "request",
+	's': "status",
+	'b': "body_bytes_sent",
+	'B': "body_bytes_sent",
+	'D': "request_time_us",
+	'T': "request_time",
+	'm': "request_method",
+	'U': "uri",
+	'q': "query_string",
+	'H': "server_protocol",
+	'v': "server_name",
+	'V': "server_name",
+	'p': "server_port",
+	'I': "bytes_received",
+	'O': "bytes_sent",
+	'X': "connection_status",
+	'P': "pid",
+	'k': "keepalive_requests",
+}
+
+// apacheParamDirectives maps Apache LogFormat directives with
+// a parameter (%{param}x) to name prefixes
+var apacheParamDirectives = map[byte]string{
+	'i': "http_",
+	'o': "sent_http_",
+	'C': "cookie_",
+	'e': "env_",
+	'n': "note_",
+}
+
+// segment is either a literal or a variable
+type segment struct {
+	literal  string
+	variable string
+}
+
+// Format is a compiled access log format. Both nginx `log_format`
+// style (`$remote_addr`) and Apache `LogFormat` style (`%h`)
+// variables are supported, Apache directives are converted
+// to names of equivalent nginx variables (e.g. `%{User-Agent}i`
+// becomes `http_user_agent`).
+type Format struct {
+	src     string
+	pattern *regexp.Regexp
+	vars    []string
+}
+
+// String returns the source of the format
+func (f *Format) String() string {
+	return f.src
+}
+
+// Variables returns names of all the variables in order
+// of their appearance
+func (f *Format) Variables() []string {
+	return f.vars
+}
+
+func normalizeHeaderName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+}
+
+func isVarChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func parseApacheDirective(src string, i int) (string, int, error) {
+	start := i
+	i++ // skip '%'
+	// modifiers (e.g. %>s, %400,501{User-agent}i, %!200h)
+	for i < len(src) && (src[i] == '>' || src[i] == '<' || src[i] == '!' || src[i] == ',' ||
+		src[i] >= '0' && src[i] <= '9') {
+		i++
+	}
+	var param string
+	if i < len(src) && src[i] == '{' {
+		end := strings.IndexByte(src[i:], '}')
+		if end < 0 {
+			return "", i, fmt.Errorf("unterminated parameter of %s", src[start:])
+		}
+		param = src[i+1 : i+end]
+		i += end + 1
+	}
+	if i >= len(src) {
+		return "", i, fmt.Errorf("incomplete directive %s", src[start:])
+	}
+	d := src[i]
+	i++
+	if param != "" {
+		if prefix, ok := apacheParamDirectives[d]; ok {
+			return prefix + normalizeHeaderName(param), i, nil
+		}
+		if d == 'T' {
+			switch param {
+			case "s":
+				return "request_time", i, nil
+			case "ms":
+				return "request_time_ms", i, nil
+			case "us":
+				return "request_time_us", i, nil
+			}
+		}
+		return "", i, fmt.Errorf("unsupported directive %s", src[start:i])
+	}
+	if name, ok := apacheDirectives[d]; ok {
+		return name, i, nil
+	}
+	return "", i, fmt.Errorf("unsupported directive %s", src[start:i])
+}
+
+func parseSegments(src string) ([]segment, error) {
+	ans := make([]segment, 0, 20)
+	var lit strings.Builder
+	flushLiteral := func() {
+		if lit.Len() > 0 {
+			ans = append(ans, segment{literal: lit.String()})
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src):
+			// escaped characters as used in Apache configuration (\")
+			switch src[i+1] {
+			case 't':
+				lit.WriteByte('\t')
+			default:
+				lit.WriteByte(src[i+1])
+			}
+			i += 2
+		case c == '%' && i+1 < len(src) && src[i+1] == '%':
+			lit.WriteByte('%')
+			i += 2
+		case c == '%':
+			name, next, err := parseApacheDirective(src, i)
+			if err != nil {
+				return nil, err
+			}
+			flushLiteral()
+			ans = append(ans, segment{variable: name})
+			i = next
+		case c == '$' && i+1 < len(src) && src[i+1] == '{':
+			end := strings.IndexByte(src[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated variable %s", src[i:])
+			}
+			flushLiteral()
+			ans = append(ans, segment{variable: src[i+2 : i+end]})
+			i += end + 1
+		case c == '$' && i+1 < len(src) && isVarChar(src[i+1]):
+			j := i + 1
+			for j < len(src) && isVarChar(src[j]) {
+				j++
+			}
+			flushLiteral()
+			ans = append(ans, segment{variable: src[i+1 : j]})
+			i = j
+		default:
+			lit.WriteByte(c)
+			i++
+		}
+	}
+	flushLiteral()
+	return ans, nil
+}
+
+// variablePattern returns a regexp group for a variable based
+// on surrounding literals
+func variablePattern(prev, next string, isLast bool) string {
+	switch {
+	case strings.HasSuffix(prev, `"`) && strings.HasPrefix(next, `"`):
+		// nginx escapes quotes as \x22, Apache as \"
+		return `((?:[^"\\]|\\.)*)`
+	case strings.HasSuffix(prev, "[") && strings.HasPrefix(next, "]"):
+		return `([^\]]*)`
+	case isLast:
+		return `(.*)`
+	}
+	return `(.*?)`
+}
+
+// ParseFormat compiles a format string
+func ParseFormat(src string) (*Format, error) {
+	segments, err := parseSegments(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse access log format: %w", err)
+	}
+	var patt strings.Builder
+	patt.WriteString("^")
+	vars := make([]string, 0, len(segments))
+	for i, seg := range segments {
+		if seg.variable == "" {
+			patt.WriteString(regexp.QuoteMeta(seg.literal))
+			continue
+		}
+		if i > 0 && segments[i-1].variable != "" {
+			return nil, fmt.Errorf(
+				"failed to parse access log format: variables %s and %s must be separated",
+				segments[i-1].variable, seg.variable)
+		}
+		var prev, next string
+		if i > 0 {
+			prev = segments[i-1].literal
+		}
+		if i < len(segments)-1 {
+			next = segments[i+1].literal
+		}
+		patt.WriteString(variablePattern(prev, next, i == len(segments)-1))
+		vars = append(vars, seg.variable)
+	}
+	patt.WriteString(`\s*$`)
+	if len(vars) == 0 {
+		return nil, fmt.Errorf("failed to parse access log format: no variables found")
+	}
+	rx, err := regexp.Compile(patt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse access log format: %w", err)
+	}
+	return &Format{src: src, pattern: rx, vars: vars}, nil
+}
+
+// Match parses a log line and returns values of all the variables.
+// Values of variables logged in quotes are unescaped.
+func (f *Format) Match(line string) (map[string]string, bool) {
+	m := f.pattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	ans := make(map[string]string, len(f.vars))
+	for i, name := range f.vars {
+		v := m[i+1]
+		if strings.IndexByte(v, '\\') >= 0 {
+			v = unescapeValue(v)
+		}
+		ans[name] = v
+	}
+	return ans, true
+}
+
+// unescapeValue decodes \xHH sequences (nginx) and \" and \\ (Apache)
+func unescapeValue(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 >= len(v) {
+			b.WriteByte(v[i])
+			continue
+		}
+		switch {
+		case v[i+1] == 'x' && i+3 < len(v) && isHex(v[i+2]) && isHex(v[i+3]):
+			b.WriteByte(hexVal(v[i+2])<<4 | hexVal(v[i+3]))
+			i += 3
+		case v[i+1] == '"' || v[i+1] == '\\':
+			b.WriteByte(v[i+1])
+			i++
+		default:
+			b.WriteByte(v[i])
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func hexVal(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	nginxFormat = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent" "$http_x_forwarded_for" rt=$request_time urt="$upstream_response_time"`
+	nginxEntry  = `10.0.3.50 - - [17/May/2021:06:36:36 +0200] "GET /wag/search/cs/test?pos=V HTTP/2.0" 200 9218 "-" "Mozilla/5.0 (X11; Linux x86_64) \"quoted\"" "203.0.113.7, 10.0.0.1" rt=0.465 urt="0.100, 0.200"`
+)
+
+func TestParseFormatNginx(t *testing.T) {
+	f, err := ParseFormat(nginxFormat)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"remote_addr", "remote_user", "time_local", "request", "status", "body_bytes_sent",
+			"http_referer", "http_user_agent", "http_x_forwarded_for", "request_time",
+			"upstream_response_time",
+		},
+		f.Variables(),
+	)
+	fields, ok := f.Match(nginxEntry)
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.3.50", fields["remote_addr"])
+	assert.Equal(t, "17/May/2021:06:36:36 +0200", fields["time_local"])
+	assert.Equal(t, "GET /wag/search/cs/test?pos=V HTTP/2.0", fields["request"])
+	assert.Equal(t, "200", fields["status"])
+	assert.Equal(t, `Mozilla/5.0 (X11; Linux x86_64) "quoted"`, fields["http_user_agent"])
+	assert.Equal(t, "203.0.113.7, 10.0.0.1", fields["http_x_forwarded_for"])
+	assert.Equal(t, "0.465", fields["request_time"])
+	assert.Equal(t, "0.100, 0.200", fields["upstream_response_time"])
+}
+
+func TestParseFormatApache(t *testing.T) {
+	f, err := ParseFormat(`%h %l %u %t \"%r\" %>s %b \"%{Referer}i\" \"%{User-Agent}i\" %D`)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"remote_addr", "remote_ident", "remote_user", "time_apache", "request", "status",
+			"body_bytes_sent", "http_referer", "http_user_agent", "request_time_us",
+		},
+		f.Variables(),
+	)
+	fields, ok := f.Match(
+		`10.1.1.15 - johndoe [17/May/2021:08:00:17 +0200] "GET /ske/run.cgi/first HTTP/1.1" 200 1793 "-" "curl/7.68.0" 12345`)
+	assert.True(t, ok)
+	assert.Equal(t, "johndoe", fields["remote_user"])
+	assert.Equal(t, "[17/May/2021:08:00:17 +0200]", fields["time_apache"])
+	assert.Equal(t, "curl/7.68.0", fields["http_user_agent"])
+	assert.Equal(t, "12345", fields["request_time_us"])
+}
+
+func TestParseFormatCustomField(t *testing.T) {
+	f, err := ParseFormat(`${remote_addr} [$time_iso8601] $request_method $request_uri app=$app_instance`)
+	assert.NoError(t, err)
+	fields, ok := f.Match(`192.168.1.1 [2021-05-17T06:36:36+02:00] POST /api/query app=node 2`)
+	assert.True(t, ok)
+	assert.Equal(t, "192.168.1.1", fields["remote_addr"])
+	assert.Equal(t, "POST", fields["request_method"])
+	assert.Equal(t, "node 2", fields["app_instance"])
+}
+
+func TestFormatMismatch(t *testing.T) {
+	f, err := ParseFormat(`$remote_addr [$time_local] "$request"`)
+	assert.NoError(t, err)
+	_, ok := f.Match(`192.168.1.1 17/May/2021:06:36:36 +0200 "GET / HTTP/1.1"`)
+	assert.False(t, ok)
+}
+
+func TestParseFormatErrors(t *testing.T) {
+	_, err := ParseFormat(`%h %Z`)
+	assert.Error(t, err)
+	_, err = ParseFormat(`%h %{User-Agent`)
+	assert.Error(t, err)
+	_, err = ParseFormat(`$remote_addr$remote_user`)
+	assert.Error(t, err)
+	_, err = ParseFormat(`no variables`)
+	assert.Error(t, err)
+}
+
+func TestParseFormatPercentLiteral(t *testing.T) {
+	f, err := ParseFormat(`%h 100%% %s`)
+	assert.NoError(t, err)
+	fields, ok := f.Match(`10.0.0.1 100% 404`)
+	assert.True(t, ok)
+	assert.Equal(t, "404", fields["status"])
+}
+
+func TestUnescapeValue(t *testing.T) {
+	assert.Equal(t, `a "b" c\d`, unescapeValue(`a \x22b\x22 c\\d`))
+	assert.Equal(t, `\q`, unescapeValue(`\q`))
+}
@@ -21,11 +21,16 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/czcorpus/klogproc-core/storage"
 	"github.com/rs/zerolog/log"
 )
 
+// apacheDatetimeLayout is the datetime layout of `time_local` (%t)
+// expected by consumers of ParsedAccessLog
+const apacheDatetimeLayout = "02/Jan/2006:15:04:05 -0700"
+
 func testOpenQuot(c byte) byte {
 	switch c {
 	case '"':
@@ -57,8 +62,33 @@ func getProcTime(procTimeExpr string) (float64, error) {
 	return -1, fmt.Errorf("failed to parse proc. time %s", procTimeExpr)
 }
 
-// LineParser is a parser for reading KonText application logs
-type LineParser struct{}
+// LineParser is a parser for reading HTTP access logs. The zero value
+// parses the Apache "combined" format followed by the `rt=` (processing
+// time) field. For other layouts, use NewLineParser with a format string.
+type LineParser struct {
+	format         *Format
+	trustedProxies TrustedProxies
+}
+
+// NewLineParser creates a parser for access logs written in the specified
+// format (see ParseFormat). An empty format means the default layout.
+// The X-Forwarded-For header (if logged) is used to resolve client
+// addresses only for requests coming from trustedProxies (IP addresses
+// or CIDR ranges).
+func NewLineParser(format string, trustedProxies []string) (LineParser, error) {
+	proxies, err := ParseTrustedProxies(trustedProxies)
+	if err != nil {
+		return LineParser{}, err
+	}
+	if format == "" {
+		return LineParser{trustedProxies: proxies}, nil
+	}
+	f, err := ParseFormat(format)
+	if err != nil {
+		return LineParser{}, err
+	}
+	return LineParser{format: f, trustedProxies: proxies}, nil
+}
 
 func (lp *LineParser) updateTokenAt(items []string, i int, value string) error {
 	if i < len(items) {
@@ -133,6 +163,56 @@ type ParsedAccessLog struct {
 	Referrer    string
 	UserAgent   string
 	ProcTime    float64
+
+	// Status is an HTTP response status code (0 if unknown)
+	Status int
+
+	// BodySize is a size of the response body in bytes
+	BodySize int64
+
+	// UpstreamResponseTime is a sum of all the upstream response
+	// times (in seconds) or -1 if not available
+	UpstreamResponseTime float64
+
+	// ForwardedFor contains the X-Forwarded-For header value
+	ForwardedFor string
+
+	// trustedProxies are proxies whose X-Forwarded-For
+	// headers can be used to resolve the client address
+	trustedProxies TrustedProxies
+
+	// Fields contains raw values of all the variables of
+	// a configured log format (nginx variable names are used)
+	Fields map[string]string
+}
+
+// IsSuccessStatus tests whether an HTTP status code represents
+// a successful (2xx) response. An unknown status (0) is considered
+// successful as some log formats do not contain the status at all.
+func IsSuccessStatus(status int) bool {
+	return status == 0 || status >= 200 && status < 300
+}
+
+// parseResponseInfo parses HTTP status and response body size values.
+// Missing or invalid values produce zero.
+func parseResponseInfo(status, bodySize string) (int, int64) {
+	st, err := strconv.Atoi(status)
+	if err != nil {
+		st = 0
+	}
+	size, err := strconv.ParseInt(bodySize, 10, 64)
+	if err != nil {
+		size = 0
+	}
+	return st, size
+}
+
+// ClientIP returns the original client address. Without configured
+// trusted proxies, this is the remote address. Otherwise the X-Forwarded-For
+// header is used for requests coming from a trusted proxy
+// (see TrustedProxies.ClientIP).
+func (pal *ParsedAccessLog) ClientIP() string {
+	return pal.trustedProxies.ClientIP(pal.IPAddress, pal.ForwardedFor)
 }
 
 // ParseLine parses a HTTP access log format line
@@ -148,7 +228,10 @@ type ParsedAccessLog struct {
 //  8. "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Ubuntu Chromium/76.0.3809.100 Chrome/76.0.3809.100 Safari/537.36"
 //  9. rt=0.012
 func (lp *LineParser) ParseLine(s string, lineNum int64) (*ParsedAccessLog, error) {
-	ans := &ParsedAccessLog{}
+	if lp.format != nil {
+		return lp.parseFormatted(s, lineNum)
+	}
+	ans := &ParsedAccessLog{UpstreamResponseTime: -1, trustedProxies: lp.trustedProxies}
 	var err error
 	var tokens []string
 	tokens, err = lp.tokenize(s)
@@ -177,8 +260,146 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*ParsedAccessLog, erro
 			return nil, storage.NewLineParsingError(lineNum, err.Error())
 		}
 	}
+	ans.Status, ans.BodySize = parseResponseInfo(tokens[5], tokens[6])
 	ans.Referrer = tokens[7]
 	ans.UserAgent = tokens[8]
 	ans.ProcTime, err = getProcTime(tokens[9])
 	return ans, err
 }
+
+// parseRequest splits the request line (e.g. "GET /foo?a=1 HTTP/1.1")
+// and fills in the respective properties
+func (pal *ParsedAccessLog) parseRequest(method, uri, version string) error {
+	pal.HTTPMethod = method
+	pal.HTTPVersion = version
+	parsedURL, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	pal.Path = parsedURL.Path
+	pal.URLArgs, err = url.ParseQuery(parsedURL.RawQuery)
+	return err
+}
+
+// normalizeDatetime converts supported time variables
+// to the Apache layout (16/Sep/2019:08:24:05 +0200)
+func normalizeDatetime(fields map[string]string) (string, error) {
+	if v, ok := fields["time_apache"]; ok {
+		return strings.Trim(v, "[]"), nil
+	}
+	if v, ok := fields["time_local"]; ok {
+		return v, nil
+	}
+	if v, ok := fields["time_iso8601"]; ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse time_iso8601: %w", err)
+		}
+		return t.Format(apacheDatetimeLayout), nil
+	}
+	if v, ok := fields["msec"]; ok {
+		ts, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse msec: %w", err)
+		}
+		return time.UnixMilli(int64(ts * 1000)).UTC().Format(apacheDatetimeLayout), nil
+	}
+	return "", fmt.Errorf("no time variable found")
+}
+
+// parseDuration parses a time value and converts it to seconds
+// using the provided divisor. Missing values ("-") produce -1.
+func parseDuration(v string, divisor float64) (float64, error) {
+	if v == "" || v == "-" {
+		return -1, nil
+	}
+	pt, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return -1, fmt.Errorf("failed to parse proc. time %s: %w", v, err)
+	}
+	return pt / divisor, nil
+}
+
+// parseUpstreamTime sums all the upstream response times. Nginx logs
+// multiple values separated by commas (or colons for internal redirects).
+func parseUpstreamTime(v string) (float64, error) {
+	ans := -1.0
+	for _, item := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ':' }) {
+		item = strings.TrimSpace(item)
+		if item == "-" || item == "" {
+			continue
+		}
+		t, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return -1, fmt.Errorf("failed to parse upstream response time %s: %w", v, err)
+		}
+		if ans < 0 {
+			ans = 0
+		}
+		ans += t
+	}
+	return ans, nil
+}
+
+func (lp *LineParser) parseFormatted(s string, lineNum int64) (*ParsedAccessLog, error) {
+	fields, ok := lp.format.Match(s)
+	if !ok {
+		return nil, storage.NewLineParsingError(lineNum, "line does not match the access log format")
+	}
+	ans := &ParsedAccessLog{
+		IPAddress:      fields["remote_addr"],
+		Username:       fields["remote_user"],
+		Referrer:       fields["http_referer"],
+		UserAgent:      fields["http_user_agent"],
+		ForwardedFor:   fields["http_x_forwarded_for"],
+		Fields:         fields,
+		trustedProxies: lp.trustedProxies,
+	}
+	bodySize, ok := fields["body_bytes_sent"]
+	if !ok {
+		bodySize = fields["bytes_sent"]
+	}
+	ans.Status, ans.BodySize = parseResponseInfo(fields["status"], bodySize)
+	var err error
+	ans.Datetime, err = normalizeDatetime(fields)
+	if err != nil {
+		return nil, storage.NewLineParsingError(lineNum, err.Error())
+	}
+	if req, ok := fields["request"]; ok {
+		urlBlock := strings.Split(req, " ")
+		if len(urlBlock) == 3 {
+			err = ans.parseRequest(urlBlock[0], urlBlock[1], urlBlock[2])
+		}
+
+	} else {
+		uri, ok := fields["request_uri"]
+		if !ok {
+			uri = fields["uri"]
+			if args := fields["args"]; args != "" && args != "-" {
+				uri += "?" + args
+
+			} else if qs := fields["query_string"]; qs != "" && qs != "-" {
+				uri += "?" + strings.TrimPrefix(qs, "?")
+			}
+		}
+		err = ans.parseRequest(fields["request_method"], uri, fields["server_protocol"])
+	}
+	if err != nil {
+		return nil, storage.NewLineParsingError(lineNum, err.Error())
+	}
+	switch {
+	case fields["request_time"] != "":
+		ans.ProcTime, err = parseDuration(fields["request_time"], 1)
+	case fields["request_time_ms"] != "":
+		ans.ProcTime, err = parseDuration(fields["request_time_ms"], 1e3)
+	case fields["request_time_us"] != "":
+		ans.ProcTime, err = parseDuration(fields["request_time_us"], 1e6)
+	default:
+		ans.ProcTime = -1
+	}
+	if err != nil {
+		return ans, err
+	}
+	ans.UpstreamResponseTime, err = parseUpstreamTime(fields["upstream_response_time"])
+	return ans, err
+}
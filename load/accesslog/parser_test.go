@@ -49,3 +49,87 @@ func TestRandomEntryWithoutRt(t *testing.T) {
 	assert.Equal(t, 10, len(tokens))
 	assert.Equal(t, "", tokens[len(tokens)-1])
 }
+
+func TestParseLineWithFormat(t *testing.T) {
+	parser, err := NewLineParser(nginxFormat, nil)
+	assert.NoError(t, err)
+	parsed, err := parser.ParseLine(nginxEntry, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.3.50", parsed.IPAddress)
+	assert.Equal(t, "10.0.3.50", parsed.ClientIP())
+	assert.Equal(t, "17/May/2021:06:36:36 +0200", parsed.Datetime)
+	assert.Equal(t, "GET", parsed.HTTPMethod)
+	assert.Equal(t, "HTTP/2.0", parsed.HTTPVersion)
+	assert.Equal(t, "/wag/search/cs/test", parsed.Path)
+	assert.Equal(t, "V", parsed.URLArgs.Get("pos"))
+	assert.InDelta(t, 0.465, parsed.ProcTime, 0.0001)
+	assert.InDelta(t, 0.3, parsed.UpstreamResponseTime, 0.0001)
+	assert.Equal(t, "9218", parsed.Fields["body_bytes_sent"])
+}
+
+func TestParseLineWithTrustedProxy(t *testing.T) {
+	parser, err := NewLineParser(nginxFormat, []string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+	parsed, err := parser.ParseLine(nginxEntry, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.3.50", parsed.IPAddress)
+	assert.Equal(t, "203.0.113.7", parsed.ClientIP())
+
+	_, err = NewLineParser(nginxFormat, []string{"foo"})
+	assert.Error(t, err)
+}
+
+func TestParseLineWithApacheFormat(t *testing.T) {
+	parser, err := NewLineParser(`%h %l %u %t "%r" %>s %b %{ms}T`, nil)
+	assert.NoError(t, err)
+	parsed, err := parser.ParseLine(
+		`10.1.1.15 - johndoe [17/May/2021:08:00:17 +0200] "GET /ske/run.cgi/first?corpname=syn2020 HTTP/1.1" 200 1793 250`, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "17/May/2021:08:00:17 +0200", parsed.Datetime)
+	assert.Equal(t, "10.1.1.15", parsed.ClientIP())
+	assert.Equal(t, "syn2020", parsed.URLArgs.Get("corpname"))
+	assert.InDelta(t, 0.25, parsed.ProcTime, 0.0001)
+	assert.Equal(t, -1.0, parsed.UpstreamResponseTime)
+}
+
+func TestParseLineWithSplitRequest(t *testing.T) {
+	parser, err := NewLineParser(`$remote_addr $time_iso8601 $request_method $uri $args`, nil)
+	assert.NoError(t, err)
+	parsed, err := parser.ParseLine(`10.0.0.1 2021-05-17T06:36:36+02:00 GET /api/search q=test`, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "17/May/2021:06:36:36 +0200", parsed.Datetime)
+	assert.Equal(t, "/api/search", parsed.Path)
+	assert.Equal(t, "test", parsed.URLArgs.Get("q"))
+	assert.Equal(t, -1.0, parsed.ProcTime)
+}
+
+func TestParseLineFormatMismatch(t *testing.T) {
+	parser, err := NewLineParser(`$remote_addr [$time_local] "$request"`, nil)
+	assert.NoError(t, err)
+	_, err = parser.ParseLine(`garbage`, 1)
+	assert.Error(t, err)
+}
+
+func TestParseLineResponseInfo(t *testing.T) {
+	parser := LineParser{}
+	parsed, err := parser.ParseLine(entry1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, parsed.Status)
+	assert.Equal(t, int64(9218), parsed.BodySize)
+
+	fparser, err := NewLineParser(`$remote_addr [$time_local] "$request" $status $body_bytes_sent`, nil)
+	assert.NoError(t, err)
+	parsed, err = fparser.ParseLine(`10.0.0.1 [17/May/2021:06:36:36 +0200] "GET / HTTP/1.1" 304 -`, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 304, parsed.Status)
+	assert.Equal(t, int64(0), parsed.BodySize)
+}
+
+func TestIsSuccessStatus(t *testing.T) {
+	assert.True(t, IsSuccessStatus(200))
+	assert.True(t, IsSuccessStatus(206))
+	assert.True(t, IsSuccessStatus(0))
+	assert.False(t, IsSuccessStatus(304))
+	assert.False(t, IsSuccessStatus(404))
+	assert.False(t, IsSuccessStatus(500))
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TrustedProxies is a list of networks of reverse proxies whose
+// X-Forwarded-For headers can be trusted
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a list of IP addresses and CIDR
+// ranges (e.g. `10.0.0.0/8`) of trusted reverse proxies
+func ParseTrustedProxies(items []string) (TrustedProxies, error) {
+	ans := make(TrustedProxies, 0, len(items))
+	for _, item := range items {
+		if strings.Contains(item, "/") {
+			_, ipNet, err := net.ParseCIDR(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s: %w", item, err)
+			}
+			ans = append(ans, ipNet)
+			continue
+		}
+		ip := net.ParseIP(item)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s: not an IP address", item)
+		}
+		bits := 8 * net.IPv4len
+		if ip.To4() == nil {
+			bits = 8 * net.IPv6len
+		}
+		ans = append(ans, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return ans, nil
+}
+
+// Contains tests whether addr is an address of a trusted proxy
+func (tp TrustedProxies) Contains(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range tp {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP resolves the original client address of a request received
+// from remoteAddr with the provided X-Forwarded-For header value.
+// As clients can send any X-Forwarded-For value, the header is used only
+// if remoteAddr is a trusted proxy and then the right-most address not
+// belonging to a trusted proxy is returned (i.e. the address as seen
+// by the outermost trusted proxy).
+func (tp TrustedProxies) ClientIP(remoteAddr, forwardedFor string) string {
+	if !tp.Contains(remoteAddr) || forwardedFor == "" || forwardedFor == "-" {
+		return remoteAddr
+	}
+	ans := remoteAddr
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// we cannot trust anything left of an invalid entry
+			break
+		}
+		ans = hop
+		if !tp.Contains(hop) {
+			break
+		}
+	}
+	return ans
+}
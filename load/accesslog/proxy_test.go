@@ -0,0 +1,69 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tp, err := ParseTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16", "2001:db8::1"})
+	assert.NoError(t, err)
+	assert.True(t, tp.Contains("10.0.0.1"))
+	assert.False(t, tp.Contains("10.0.0.2"))
+	assert.True(t, tp.Contains("192.168.10.20"))
+	assert.True(t, tp.Contains("2001:db8::1"))
+	assert.False(t, tp.Contains("2001:db8::2"))
+	assert.False(t, tp.Contains("-"))
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"proxy.example.com"})
+	assert.Error(t, err)
+}
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	tp, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expected     string
+	}{
+		{"no header", "10.0.0.1", "", "10.0.0.1"},
+		{"missing header", "10.0.0.1", "-", "10.0.0.1"},
+		{"single hop", "10.0.0.1", "203.0.113.7", "203.0.113.7"},
+		{"spoofed left-most hop", "10.0.0.1", "1.2.3.4, 203.0.113.7", "203.0.113.7"},
+		{"chain of trusted proxies", "10.0.0.1", "1.2.3.4, 203.0.113.7, 10.0.0.2", "203.0.113.7"},
+		{"untrusted remote address", "198.51.100.1", "203.0.113.7", "198.51.100.1"},
+		{"invalid hop", "10.0.0.1", "203.0.113.7, foo, 10.0.0.2", "10.0.0.2"},
+		{"trusted hops only", "10.0.0.1", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tp.ClientIP(tc.remoteAddr, tc.forwardedFor))
+		})
+	}
+}
+
+func TestNoTrustedProxiesClientIP(t *testing.T) {
+	var tp TrustedProxies
+	assert.Equal(t, "10.0.0.1", tp.ClientIP("10.0.0.1", "203.0.113.7"))
+}
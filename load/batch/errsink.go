@@ -0,0 +1,51 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"time"
+
+	"klogproc/errstream"
+)
+
+// timeRangeSink passes to the wrapped sink only error entries matching
+// the processed time range (i.e. the same ones for which log records
+// are processed) so repeated batch runs do not write the same errors again
+type timeRangeSink struct {
+	sink          errstream.Sink
+	datetimeRange DatetimeRange
+	minTimestamp  int64
+}
+
+// Add passes an entry to the wrapped sink in case it matches
+// the processed time range
+func (s *timeRangeSink) Add(entry errstream.Entry) {
+	if s.datetimeRange.From != nil && entry.Time.Before(*s.datetimeRange.From) {
+		return
+	}
+	if s.datetimeRange.To != nil && entry.Time.After(*s.datetimeRange.To) {
+		return
+	}
+	if entry.Time.Unix() < s.minTimestamp {
+		return
+	}
+	s.sink.Add(entry)
+}
+
+func (s *timeRangeSink) Location() *time.Location {
+	return s.sink.Location()
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"testing"
+	"time"
+
+	"klogproc/errstream"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type collectingSink struct {
+	entries []errstream.Entry
+}
+
+func (s *collectingSink) Add(entry errstream.Entry) {
+	s.entries = append(s.entries, entry)
+}
+
+func (s *collectingSink) Location() *time.Location {
+	return time.UTC
+}
+
+func TestTimeRangeSink(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	worklogTime := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	target := &collectingSink{}
+	sink := &timeRangeSink{
+		sink:          target,
+		datetimeRange: DatetimeRange{From: &from, To: &to},
+		minTimestamp:  worklogTime.Unix(),
+	}
+	for _, tm := range []time.Time{
+		time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC), // before -from
+		time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC),  // already processed
+		time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC), // after -to
+	} {
+		sink.Add(errstream.Entry{Time: tm})
+	}
+	if assert.Len(t, target.entries, 2) {
+		assert.Equal(t, worklogTime, target.entries[0].Time)
+		assert.Equal(t, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), target.entries[1].Time)
+	}
+	assert.Equal(t, time.UTC, sink.Location())
+}
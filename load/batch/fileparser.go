@@ -24,32 +24,55 @@ package batch
 import (
 	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"klogproc/apps"
+	"klogproc/errstream"
 	"klogproc/trfactory"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/czcorpus/klogproc-core/storage"
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	// parserRetryInterval specifies how often a line is retried
+	// in case the line parser is temporarily unavailable
+	parserRetryInterval = time.Second
+
+	// maxParserUnavailability specifies how long a line parser can stay
+	// unavailable before processing of the file is aborted
+	maxParserUnavailability = 5 * time.Minute
+)
+
 // newParser creates a new instance of the Parser.
 // tzShift can be used to correct an incorrectly stored datetime
-func newParser(path string, tzShift int, appType string, version string, appErrRegister storage.AppErrorRegister) *Parser {
+func newParser(
+	path string,
+	tzShift int,
+	logConf storage.LogProcConf,
+	appErrRegister storage.AppErrorRegister,
+	errorSink errstream.Sink,
+) *Parser {
 	f, err := os.Open(path)
 	if err != nil {
 		panic(err)
 	}
 	sc := bufio.NewScanner(f)
-	lineParser, err := trfactory.NewLineParser(appType, version, appErrRegister)
+	lineParser, err := trfactory.NewLineParser(logConf, appErrRegister, errorSink)
 	if err != nil {
 		panic(err) // TODO
 	}
 	return &Parser{
-		recType:    appType,
-		fr:         sc,
-		tzShift:    tzShift,
-		fileName:   filepath.Base(f.Name()),
-		lineParser: lineParser,
+		recType:        logConf.GetAppType(),
+		fr:             sc,
+		tzShift:        tzShift,
+		fileName:       filepath.Base(f.Name()),
+		lineParser:     lineParser,
+		appErrRegister: appErrRegister,
 	}
 }
 
@@ -62,39 +85,96 @@ type Parser struct {
 	tzShift    int
 	lineParser storage.LineParser
 	recType    string
+
+	// appErrRegister is notified about errors not reported
+	// by the line parser (e.g. exceeded script limits)
+	appErrRegister storage.AppErrorRegister
+}
+
+// Close releases resources held by the line parser
+// (e.g. an external parser process)
+func (p *Parser) Close() {
+	if c, ok := p.lineParser.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Error().Err(err).Str("file", p.fileName).Msg("failed to close line parser")
+		}
+	}
+}
+
+// parseLine parses a line. In case the line parser is temporarily
+// unavailable (e.g. an external parser process is being restarted),
+// the line is retried so it is not lost. Once the parser stays unavailable
+// for too long, an error wrapping apps.ErrParserUnavailable is returned.
+func (p *Parser) parseLine(ctx context.Context, line string, lineNum int64) (storage.InputRecord, error) {
+	var unavailableSince time.Time
+	for {
+		rec, err := p.lineParser.ParseLine(line, lineNum)
+		if !errors.Is(err, apps.ErrParserUnavailable) {
+			return rec, err
+		}
+		if unavailableSince.IsZero() {
+			unavailableSince = time.Now()
+			log.Warn().Err(err).Str("file", p.fileName).Int64("line", lineNum).Msg("line parser not available, waiting")
+
+		} else if time.Since(unavailableSince) > maxParserUnavailability {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(parserRetryInterval):
+		}
+	}
 }
 
 // Parse runs the parsing process based on provided minimum accepted record
 // time, record type (which is just passed to ElasticSearch) and a
 // provided LogInterceptor).
+// An error is returned only in case the file cannot be processed any further
+// (i.e. the line parser is not available).
 func (p *Parser) Parse(
 	ctx context.Context,
 	fromTimestamp int64,
 	proc logItemProcessor,
 	datetimeRange DatetimeRange,
+	report *RunReport,
 	outputs ...chan *storage.BoundOutputRecord,
-) {
+) error {
 	for i := int64(0); p.fr.Scan(); i++ {
 		select {
 		case <-ctx.Done():
 			log.Warn().Msg("batch file parser stopping due to cancellation")
-			return
+			return nil
 		default:
 		}
-		rec, err := p.lineParser.ParseLine(p.fr.Text(), i)
+		rec, err := p.parseLine(ctx, p.fr.Text(), i)
+		if errors.Is(err, apps.ErrParserUnavailable) {
+			if ctx.Err() != nil {
+				log.Warn().Msg("batch file parser stopping due to cancellation")
+				return nil
+			}
+			return fmt.Errorf("failed to process line %d of %s: %w", i, p.fileName, err)
+		}
+		report.Update(p.fileName, func(fs *FileStats) { fs.LinesRead++ })
 		if err == nil {
 			recTime := rec.GetTime()
 			if datetimeRange.From != nil && recTime.Before(*datetimeRange.From) {
 				log.Info().Msgf("Skipping line %d (timestamp: %v) due to required time range", i, recTime)
+				report.Update(p.fileName, func(fs *FileStats) { fs.SkippedTimeRange++ })
 				continue
 			}
 			if datetimeRange.To != nil && recTime.After(*datetimeRange.To) {
 				log.Info().Msgf("Stopping file processing - record at line %d (timestamp: %v) is newer than the required limit %v",
 					i, recTime, datetimeRange.To)
+				report.Update(p.fileName, func(fs *FileStats) { fs.SkippedTimeRange++ })
 				break
 			}
 			if recTime.Unix() >= fromTimestamp {
-				outRecs := proc.ProcItem(rec)
+				outRecs, outcome := proc.ProcItem(rec)
+				report.AddOutcome(p.fileName, outcome)
+				if outcome == ItemScriptLimitError {
+					p.appErrRegister.OnError(fmt.Sprintf("script limit exceeded at line %d of %s", i, p.fileName))
+				}
 				for _, outRec := range outRecs {
 					for _, output := range outputs {
 						output <- &storage.BoundOutputRecord{Rec: outRec, FilePath: p.fileName}
@@ -103,6 +183,7 @@ func (p *Parser) Parse(
 			}
 
 		} else {
+			report.Update(p.fileName, func(fs *FileStats) { fs.ParseErrors[fmt.Sprintf("%T", err)]++ })
 			switch tErr := err.(type) {
 			case storage.LineParsingError:
 				log.Info().Err(tErr).Str("file", p.fileName).Msg("file parsing error")
@@ -112,4 +193,5 @@ func (p *Parser) Parse(
 
 		}
 	}
+	return nil
 }
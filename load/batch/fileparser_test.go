@@ -0,0 +1,62 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"klogproc/apps"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// restartingParser is unavailable for a number of calls
+// (e.g. like a restarting parser process)
+type restartingParser struct {
+	numUnavailable int
+	calls          []int64
+}
+
+func (p *restartingParser) ParseLine(s string, lineNum int64) (storage.InputRecord, error) {
+	p.calls = append(p.calls, lineNum)
+	if p.numUnavailable > 0 {
+		p.numUnavailable--
+		return nil, fmt.Errorf("%w: restarting", apps.ErrParserUnavailable)
+	}
+	return nil, nil
+}
+
+func TestParseLineRetriesUnavailableParser(t *testing.T) {
+	lp := &restartingParser{numUnavailable: 1}
+	p := &Parser{fileName: "test.log", lineParser: lp}
+	_, err := p.parseLine(context.Background(), "foo", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 3}, lp.calls)
+}
+
+func TestParseLineCancelledWhileUnavailable(t *testing.T) {
+	lp := &restartingParser{numUnavailable: 100}
+	p := &Parser{fileName: "test.log", lineParser: lp}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := p.parseLine(ctx, "foo", 3)
+	assert.ErrorIs(t, err, apps.ErrParserUnavailable)
+	assert.Equal(t, []int64{3}, lp.calls)
+}
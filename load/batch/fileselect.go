@@ -31,8 +31,20 @@ import (
 	"strconv"
 	"time"
 
+	"klogproc/corpora"
+	"klogproc/errstream"
 	"klogproc/fsop"
+	"klogproc/load/accesslog"
 	"klogproc/load/alarm"
+	"klogproc/load/throttle"
+	"klogproc/lookup"
+	"klogproc/luasandbox"
+	"klogproc/servicelog/custom"
+	"klogproc/servicelog/extproc"
+	"klogproc/servicelog/gokit"
+	"klogproc/servicelog/mapping"
+	"klogproc/trfactory"
+	"klogproc/users"
 
 	"github.com/czcorpus/cnc-gokit/fs"
 	"github.com/czcorpus/klogproc-core/logbuffer"
@@ -56,8 +68,67 @@ type Conf struct {
 	Buffer                 *logbuffer.BufferConf `json:"buffer"`
 	ScriptPath             string                `json:"scriptPath"`
 
+	// ReportPath specifies where a JSON report with processing
+	// counters should be written once the batch run is finished.
+	// If empty, only a summary table is printed to stderr.
+	ReportPath string `json:"reportPath"`
+
+	// AnalysisReportDir specifies a directory where bot analysis
+	// reports (JSON, HTML, IP and user agent lists) are written
+	// in the "analysis only" mode.
+	AnalysisReportDir string `json:"analysisReportDir"`
+
+	// BotPatternsPath specifies a file with known bots definitions
+	// (see bots.default.json) used by the bot analysis report.
+	BotPatternsPath string `json:"botPatternsPath"`
+
+	// Throttle limits the rate of writing to Elasticsearch
+	// so large imports do not overload a production cluster.
+	Throttle *throttle.Conf `json:"throttle"`
+
+	// ScriptLimits configures resources available to the Lua script
+	// (if nil, default limits are applied)
+	ScriptLimits *luasandbox.Limits `json:"scriptLimits"`
+
+	// Mapping configures the `mapping` app type
+	Mapping *mapping.Conf `json:"mapping"`
+
+	// Gokit configures the `gokit` app type
+	Gokit *gokit.Conf `json:"gokit"`
+
+	// Exec configures the `exec` app type
+	Exec *extproc.Conf `json:"exec"`
+
+	// ErrorStream enables writing of application errors (KonText)
+	// as structured documents to a separate index
+	ErrorStream *errstream.Conf `json:"errorStream"`
+
+	// LookupTables are available to Lua scripts (as `lookup.<name>`)
+	// and to some transformers
+	LookupTables []lookup.Conf `json:"lookupTables"`
+
+	// Corpora assigns corpora to records of applications which
+	// do not log them (`syd`, `treq`)
+	Corpora corpora.Conf `json:"corpora"`
+
+	// Users configures resolution of usernames to user IDs
+	// for applications logging only usernames (`ske`, Shiny apps)
+	Users *users.Conf `json:"users"`
+
+	// AccessLogFormat specifies a layout of access log lines (`wag` 0.6,
+	// `mapka` 1 and 2, `ske`) using either nginx `log_format` or Apache
+	// `LogFormat` syntax. If empty, the Apache "combined" format followed
+	// by `rt=$request_time` is expected.
+	AccessLogFormat string `json:"accessLogFormat"`
+
+	// TrustedProxies lists IP addresses and CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header (`http_x_forwarded_for` in AccessLogFormat)
+	// is used to resolve client addresses. If empty, the header is ignored.
+	TrustedProxies []string `json:"trustedProxies"`
+
 	// Version represents a major and minor version signature as used in semantic versioning
-	// (e.g. 0.15, 1.2)
+	// (e.g. 0.15, 1.2). The value `auto` makes klogproc detect the version
+	// from log lines (for app types with multiple versions).
 	Version        string `json:"version"`
 	NumErrorsAlarm int    `json:"numErrorsAlarm"`
 	TZShift        int    `json:"tzShift"`
@@ -72,6 +143,14 @@ func (c *Conf) GetVersion() string {
 	return c.Version
 }
 
+// WithVersion returns a copy of the configuration with a different version
+// (this is used when detecting the version automatically)
+func (c *Conf) WithVersion(version string) storage.LogProcConf {
+	cp := *c
+	cp.Version = version
+	return &cp
+}
+
 func (c *Conf) GetBuffer() *logbuffer.BufferConf {
 	return c.Buffer
 }
@@ -84,10 +163,111 @@ func (c *Conf) GetPath() string {
 	return c.SrcPath
 }
 
+func (c *Conf) GetScriptLimits() *luasandbox.Limits {
+	return c.ScriptLimits
+}
+
+func (c *Conf) GetMapping() *mapping.Conf {
+	return c.Mapping
+}
+
+func (c *Conf) GetGokit() *gokit.Conf {
+	return c.Gokit
+}
+
+func (c *Conf) GetExec() *extproc.Conf {
+	return c.Exec
+}
+
+func (c *Conf) GetErrorStream() *errstream.Conf {
+	return c.ErrorStream
+}
+
+func (c *Conf) GetUsers() *users.Conf {
+	return c.Users
+}
+
+func (c *Conf) GetCorpora() corpora.Conf {
+	return c.Corpora
+}
+
+func (c *Conf) GetAccessLogFormat() string {
+	return c.AccessLogFormat
+}
+
+func (c *Conf) GetTrustedProxies() []string {
+	return c.TrustedProxies
+}
+
+func (c *Conf) GetLookupTables() []lookup.Conf {
+	return c.LookupTables
+}
+
 func (conf *Conf) Validate() error {
 	if pathExists := fs.PathExists(conf.SrcPath); !pathExists {
 		return errors.New("failed to validate batch file processing srcPath: path does not exist")
 	}
+	if conf.AppType == custom.AppType && conf.ScriptPath == "" {
+		return errors.New("failed to validate batch file processing: app type custom requires scriptPath")
+	}
+	if conf.Version == trfactory.AutoVersion && len(trfactory.DetectableVersions(conf.AppType)) == 0 {
+		return fmt.Errorf("failed to validate batch file processing: version of %s cannot be detected", conf.AppType)
+	}
+	if conf.AppType == mapping.AppType {
+		if conf.Mapping == nil {
+			return errors.New("failed to validate batch file processing: app type mapping requires mapping")
+		}
+		if err := conf.Mapping.Validate(); err != nil {
+			return fmt.Errorf("failed to validate batch file processing: %w", err)
+		}
+	}
+	if conf.AppType == gokit.AppType {
+		if conf.Gokit == nil {
+			return errors.New("failed to validate batch file processing: app type gokit requires gokit")
+		}
+		if err := conf.Gokit.Validate(); err != nil {
+			return fmt.Errorf("failed to validate batch file processing: %w", err)
+		}
+	}
+	if conf.AppType == extproc.AppType {
+		if conf.Exec == nil {
+			return errors.New("failed to validate batch file processing: app type exec requires exec")
+		}
+		if err := conf.Exec.Validate(); err != nil {
+			return fmt.Errorf("failed to validate batch file processing: %w", err)
+		}
+	}
+	for _, lt := range conf.LookupTables {
+		if err := lt.Validate(); err != nil {
+			return fmt.Errorf("failed to validate batch file processing: %w", err)
+		}
+	}
+	if conf.Users != nil {
+		if err := conf.Users.Validate(); err != nil {
+			return fmt.Errorf("failed to validate batch file processing: %w", err)
+		}
+	}
+	if err := conf.Corpora.Validate(); err != nil {
+		return fmt.Errorf("failed to validate batch file processing: %w", err)
+	}
+	if conf.ErrorStream != nil {
+		if err := conf.ErrorStream.Validate(); err != nil {
+			return fmt.Errorf("failed to validate batch file processing: %w", err)
+		}
+	}
+	if conf.AccessLogFormat != "" {
+		if _, err := accesslog.ParseFormat(conf.AccessLogFormat); err != nil {
+			return fmt.Errorf("failed to validate batch file processing: %w", err)
+		}
+	}
+	if _, err := accesslog.ParseTrustedProxies(conf.TrustedProxies); err != nil {
+		return fmt.Errorf("failed to validate batch file processing: %w", err)
+	}
+	if conf.ScriptLimits != nil {
+		if err := conf.ScriptLimits.Validate(); err != nil {
+			return err
+		}
+	}
 	if conf.Buffer != nil {
 		return conf.Buffer.Validate()
 	}
@@ -208,9 +388,18 @@ func getFilesInDir(dirPath string, minTimestamp int64, strictMatch bool, tzShift
 	return []string{}
 }
 
+// ListLogFiles returns log files to be processed - i.e. the configured file
+// or matching files of the configured directory
+func ListLogFiles(conf *Conf, minTimestamp int64) []string {
+	if fsop.IsDir(conf.SrcPath) {
+		return getFilesInDir(conf.SrcPath, minTimestamp, !conf.PartiallyMatchingFiles, conf.TZShift)
+	}
+	return []string{conf.SrcPath}
+}
+
 // logItemProcessor is an object handling a specific log file with a specific format
 type logItemProcessor interface {
-	ProcItem(logRec storage.InputRecord) []storage.OutputRecord
+	ProcItem(logRec storage.InputRecord) ([]storage.OutputRecord, ItemOutcome)
 	GetAppType() string
 	GetAppVersion() string
 }
@@ -219,11 +408,15 @@ type logItemProcessor interface {
 type LogFileProcFunc = func(conf *Conf, minTimestamp int64)
 
 // CreateLogFileProcFunc connects a defined log transformer with output channels and
-// returns a customized function for file/directory processing.
+// returns a customized function for file/directory processing. Application errors
+// found by line parsers are passed to errorSink in case they match the processed
+// time range (datetimeRange and minTimestamp).
 func CreateLogFileProcFunc(
 	ctx context.Context,
 	processor logItemProcessor,
 	datetimeRange DatetimeRange,
+	report *RunReport,
+	errorSink errstream.Sink,
 	destChans ...chan *storage.BoundOutputRecord,
 ) LogFileProcFunc {
 	return func(conf *Conf, minTimestamp int64) {
@@ -232,12 +425,11 @@ func CreateLogFileProcFunc(
 				close(ch)
 			}
 		}()
-		var files []string
-		if fsop.IsDir(conf.SrcPath) {
-			files = getFilesInDir(conf.SrcPath, minTimestamp, !conf.PartiallyMatchingFiles, conf.TZShift)
-
-		} else {
-			files = []string{conf.SrcPath}
+		files := ListLogFiles(conf, minTimestamp)
+		errorSink := &timeRangeSink{
+			sink:          errorSink,
+			datetimeRange: datetimeRange,
+			minTimestamp:  minTimestamp,
 		}
 		log.Info().Msgf("Found %d file(s) to process in %s", len(files), conf.SrcPath)
 		var procAlarm storage.AppErrorRegister
@@ -251,8 +443,17 @@ func CreateLogFileProcFunc(
 			log.Info().Msgf("Found time-zone correction %d minutes", conf.TZShift)
 		}
 		for i, file := range files {
-			p := newParser(file, conf.TZShift, processor.GetAppType(), processor.GetAppVersion(), procAlarm)
-			p.Parse(ctx, minTimestamp, processor, datetimeRange, destChans...)
+			p := newParser(file, conf.TZShift, conf, procAlarm, errorSink)
+			err := p.Parse(ctx, minTimestamp, processor, datetimeRange, report, destChans...)
+			p.Close()
+			if err != nil {
+				log.Error().
+					Err(err).
+					Strs("rest", files[i:]).
+					Msg("aborting processing of log files")
+				report.Abort(err)
+				return
+			}
 			select {
 			case <-ctx.Done():
 				log.Warn().
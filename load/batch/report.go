@@ -0,0 +1,265 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// ItemOutcome describes how a single parsed input record
+// has been handled by a logItemProcessor
+type ItemOutcome int
+
+const (
+	ItemProcessed ItemOutcome = iota
+	ItemNonProcessable
+	ItemPreprocessDrop
+	ItemTransformError
+	ItemSampledOut
+
+	// ItemScriptLimitError is a transform error caused by
+	// a Lua script exceeding its resource limits
+	ItemScriptLimitError
+)
+
+// FileStats contains counters for a single processed file
+// (or for the whole run in case of RunReport.Total)
+type FileStats struct {
+	LinesRead        int            `json:"linesRead"`
+	ParseErrors      map[string]int `json:"parseErrors"`
+	NonProcessable   int            `json:"nonProcessable"`
+	SkippedTimeRange int            `json:"skippedTimeRange"`
+	SampledOut       int            `json:"sampledOut"`
+	PreprocessDrops  int            `json:"preprocessDrops"`
+	TransformErrors  int            `json:"transformErrors"`
+	Written          int            `json:"written"`
+	WriteFailures    int            `json:"writeFailures"`
+}
+
+// NumParseErrors returns total number of parsing errors
+// regardless of their type
+func (fs *FileStats) NumParseErrors() int {
+	var ans int
+	for _, v := range fs.ParseErrors {
+		ans += v
+	}
+	return ans
+}
+
+func (fs *FileStats) add(other *FileStats) {
+	fs.LinesRead += other.LinesRead
+	for k, v := range other.ParseErrors {
+		fs.ParseErrors[k] += v
+	}
+	fs.NonProcessable += other.NonProcessable
+	fs.SkippedTimeRange += other.SkippedTimeRange
+	fs.SampledOut += other.SampledOut
+	fs.PreprocessDrops += other.PreprocessDrops
+	fs.TransformErrors += other.TransformErrors
+	fs.Written += other.Written
+	fs.WriteFailures += other.WriteFailures
+}
+
+func newFileStats() *FileStats {
+	return &FileStats{ParseErrors: make(map[string]int)}
+}
+
+// RunReport collects per-file and total counters of a batch run.
+// It is safe for concurrent use as the counters are updated both
+// by the file parser and by the consumer of write confirmations.
+type RunReport struct {
+	AppType       string                `json:"appType"`
+	AppVersion    string                `json:"appVersion"`
+	Start         time.Time             `json:"start"`
+	End           time.Time             `json:"end"`
+	ElapsedSecs   float64               `json:"elapsedSecs"`
+	LinesPerSec   float64               `json:"linesPerSec"`
+	WrittenPerSec float64               `json:"writtenPerSec"`
+	Files         map[string]*FileStats `json:"files"`
+	Total         *FileStats            `json:"total"`
+
+	// Aborted contains a reason why the run has been aborted
+	// before all the files were processed
+	Aborted string `json:"aborted,omitempty"`
+
+	mutex sync.Mutex
+}
+
+// Abort marks the run as not finished due to err
+func (r *RunReport) Abort(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Aborted = err.Error()
+}
+
+// IsAborted tests whether the run has been aborted
+// before all the files were processed
+func (r *RunReport) IsAborted() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.Aborted != ""
+}
+
+// Update applies fn to the counters of the file specified by fileName.
+// The function is called with the report locked.
+func (r *RunReport) Update(fileName string, fn func(fs *FileStats)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	fs, ok := r.Files[fileName]
+	if !ok {
+		fs = newFileStats()
+		r.Files[fileName] = fs
+	}
+	fn(fs)
+}
+
+// AddOutcome increments a counter matching the item processing outcome.
+func (r *RunReport) AddOutcome(fileName string, outcome ItemOutcome) {
+	r.Update(fileName, func(fs *FileStats) {
+		switch outcome {
+		case ItemNonProcessable:
+			fs.NonProcessable++
+		case ItemPreprocessDrop:
+			fs.PreprocessDrops++
+		case ItemTransformError, ItemScriptLimitError:
+			fs.TransformErrors++
+		case ItemSampledOut:
+			fs.SampledOut++
+		}
+	})
+}
+
+// AddWriteConfirmation registers a result of writing a single
+// record to a target database.
+func (r *RunReport) AddWriteConfirmation(fileName string, err error) {
+	r.Update(fileName, func(fs *FileStats) {
+		if err != nil {
+			fs.WriteFailures++
+
+		} else {
+			fs.Written++
+		}
+	})
+}
+
+// Finish calculates total values and elapsed time. It should be called
+// once all the files are processed and all the confirmations received.
+func (r *RunReport) Finish() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.End = time.Now()
+	r.Total = newFileStats()
+	for _, fs := range r.Files {
+		r.Total.add(fs)
+	}
+	r.ElapsedSecs = r.End.Sub(r.Start).Seconds()
+	if r.ElapsedSecs > 0 {
+		r.LinesPerSec = float64(r.Total.LinesRead) / r.ElapsedSecs
+		r.WrittenPerSec = float64(r.Total.Written) / r.ElapsedSecs
+	}
+}
+
+// WriteJSON stores the report to a file specified by path
+func (r *RunReport) WriteJSON(path string) error {
+	r.mutex.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to write batch report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write batch report: %w", err)
+	}
+	return nil
+}
+
+// PrintSummary writes a human-readable table with per-file
+// and total counters.
+func (r *RunReport) PrintSummary(w io.Writer) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	headerFmt := color.New(color.FgGreen).SprintfFunc()
+	columnFmt := color.New(color.FgHiMagenta).SprintfFunc()
+	tbl := table.New(
+		"File",
+		"Lines",
+		"Parse err.",
+		"Non-proc.",
+		"Time range skip",
+		"Sampled out",
+		"Preproc. drops",
+		"Transf. err.",
+		"Written",
+		"Write fail.",
+	)
+	tbl.
+		WithWriter(w).
+		WithHeaderFormatter(headerFmt).
+		WithFirstColumnFormatter(columnFmt).
+		WithHeaderSeparatorRow('\u2550')
+
+	fileNames := make([]string, 0, len(r.Files))
+	for k := range r.Files {
+		fileNames = append(fileNames, k)
+	}
+	sort.Strings(fileNames)
+	addRow := func(name string, fs *FileStats) {
+		tbl.AddRow(
+			name, fs.LinesRead, fs.NumParseErrors(), fs.NonProcessable, fs.SkippedTimeRange,
+			fs.SampledOut, fs.PreprocessDrops, fs.TransformErrors, fs.Written, fs.WriteFailures)
+	}
+	for _, name := range fileNames {
+		addRow(name, r.Files[name])
+	}
+	if r.Total != nil {
+		addRow("TOTAL", r.Total)
+	}
+	fmt.Fprintf(w, "\nBatch processing report for %s %s\n\n", r.AppType, r.AppVersion)
+	tbl.Print()
+	if r.Total != nil && len(r.Total.ParseErrors) > 0 {
+		fmt.Fprintln(w, "\nParse errors by type:")
+		for k, v := range r.Total.ParseErrors {
+			fmt.Fprintf(w, "\t%s: %d\n", k, v)
+		}
+	}
+	if r.Aborted != "" {
+		fmt.Fprintf(w, "\nprocessing aborted: %s\n", r.Aborted)
+	}
+	fmt.Fprintf(
+		w, "\nelapsed: %01.2fs, lines/s: %01.1f, written records/s: %01.1f\n\n",
+		r.ElapsedSecs, r.LinesPerSec, r.WrittenPerSec)
+}
+
+// NewRunReport creates a new report with start time set to now
+func NewRunReport(appType, appVersion string) *RunReport {
+	return &RunReport{
+		AppType:    appType,
+		AppVersion: appVersion,
+		Start:      time.Now(),
+		Files:      make(map[string]*FileStats),
+	}
+}
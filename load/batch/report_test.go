@@ -0,0 +1,56 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReportTotals(t *testing.T) {
+	report := NewRunReport("kontext", "0.18")
+	report.Update("a.log", func(fs *FileStats) {
+		fs.LinesRead += 3
+		fs.ParseErrors["storage.LineParsingError"]++
+	})
+	report.AddOutcome("a.log", ItemNonProcessable)
+	report.AddOutcome("b.log", ItemTransformError)
+	report.AddOutcome("b.log", ItemPreprocessDrop)
+	report.AddOutcome("b.log", ItemProcessed)
+	report.AddWriteConfirmation("b.log", nil)
+	report.AddWriteConfirmation("b.log", errors.New("failed"))
+	report.Finish()
+
+	assert.Equal(t, 3, report.Total.LinesRead)
+	assert.Equal(t, 1, report.Total.NumParseErrors())
+	assert.Equal(t, 1, report.Total.NonProcessable)
+	assert.Equal(t, 1, report.Total.TransformErrors)
+	assert.Equal(t, 1, report.Total.PreprocessDrops)
+	assert.Equal(t, 1, report.Total.Written)
+	assert.Equal(t, 1, report.Total.WriteFailures)
+	assert.Equal(t, 2, len(report.Files))
+}
+
+func TestRunReportAbort(t *testing.T) {
+	report := NewRunReport("exec", "")
+	assert.False(t, report.IsAborted())
+	report.Abort(errors.New("parser not available"))
+	assert.True(t, report.IsAborted())
+	assert.Equal(t, "parser not available", report.Aborted)
+}
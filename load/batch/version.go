@@ -0,0 +1,66 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"errors"
+	"fmt"
+
+	"klogproc/trfactory"
+)
+
+// ErrNothingToProcess is returned by ResolveVersion in case there
+// are no new log lines to detect the version from (e.g. all the files
+// have been already processed according to the worklog)
+var ErrNothingToProcess = errors.New("no new log lines to process")
+
+// ResolveVersion replaces the `auto` version of processed logs
+// with a version detected from samples of log lines. Each file to be
+// processed (see ListLogFiles) is sampled and all the files must be
+// of the same version.
+func ResolveVersion(conf *Conf, minTimestamp int64) error {
+	if conf.Version != trfactory.AutoVersion {
+		return nil
+	}
+	var version, versionPath string
+	for _, path := range ListLogFiles(conf, minTimestamp) {
+		sample, err := trfactory.ReadHeadSample(path, trfactory.DetectionSampleSize)
+		if err != nil {
+			return fmt.Errorf("failed to detect application version: %w", err)
+		}
+		if len(sample) == 0 {
+			continue
+		}
+		fileVersion, err := trfactory.ResolveAutoVersion(
+			conf.AppType, conf.WithVersion, sample, path)
+		if err != nil {
+			return err
+		}
+		if version != "" && fileVersion != version {
+			return fmt.Errorf(
+				"failed to detect application version: %s is of version %s while %s is of version %s, please process them separately",
+				versionPath, version, path, fileVersion)
+		}
+		version = fileVersion
+		versionPath = path
+	}
+	if version == "" {
+		return fmt.Errorf("failed to detect application version of %s: %w", conf.SrcPath, ErrNothingToProcess)
+	}
+	conf.Version = version
+	return nil
+}
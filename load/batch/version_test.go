@@ -0,0 +1,71 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"klogproc/trfactory"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKonText015Log(t *testing.T) string {
+	dir := t.TempDir()
+	lines := `2021-06-01 10:00:00,123 [QUERY] INFO: {"user_id": 12, "proc_time": 0.2041, "date": "2021-06-01 10:00:00.123456", "action": "query_submit", "is_indirect_call": false, "is_api": false, "request": {"HTTP_X_FORWARDED_FOR": "195.113.53.66", "HTTP_USER_AGENT": "Mozilla/5.0"}, "args": {"corpname": "syn2020", "qtype": "simple", "uses_context": 0}}
+2021-06-01 10:00:03,511 [QUERY] INFO: {"user_id": 12, "proc_time": 0.0815, "date": "2021-06-01 10:00:03.511004", "action": "view", "is_indirect_call": true, "is_api": false, "request": {"REMOTE_ADDR": "195.113.53.66", "HTTP_USER_AGENT": "Mozilla/5.0"}, "args": {"corpora": ["syn2020"]}}
+`
+	err := os.WriteFile(filepath.Join(dir, "application.log"), []byte(lines), 0644)
+	assert.NoError(t, err)
+	return dir
+}
+
+func TestResolveVersion(t *testing.T) {
+	conf := &Conf{
+		SrcPath: writeKonText015Log(t),
+		AppType: storage.AppTypeKontext,
+		Version: trfactory.AutoVersion,
+	}
+	err := ResolveVersion(conf, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, storage.AppVersionKontext017, conf.Version)
+}
+
+func TestResolveVersionUpToDateWorklog(t *testing.T) {
+	conf := &Conf{
+		SrcPath: writeKonText015Log(t),
+		AppType: storage.AppTypeKontext,
+		Version: trfactory.AutoVersion,
+	}
+	// the worklog already contains records newer than the whole log
+	err := ResolveVersion(conf, 1700000000)
+	assert.ErrorIs(t, err, ErrNothingToProcess)
+	assert.Equal(t, trfactory.AutoVersion, conf.Version)
+}
+
+func TestResolveVersionExplicit(t *testing.T) {
+	conf := &Conf{
+		SrcPath: t.TempDir(),
+		AppType: storage.AppTypeKontext,
+		Version: storage.AppVersionKontext018,
+	}
+	assert.NoError(t, ResolveVersion(conf, 1700000000))
+	assert.Equal(t, storage.AppVersionKontext018, conf.Version)
+}
@@ -0,0 +1,322 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package botreport collects traffic statistics of input records
+// processed by the `batch -analysis-only` action and produces reports
+// suitable for investigating scraping incidents.
+package botreport
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTopN         = 50
+	DefaultOutlierCoeff = 1.5
+	DefaultMinFreq      = 100
+	rateBucket          = time.Minute
+)
+
+// Record specifies methods the collector needs from an input record
+type Record interface {
+	GetTime() time.Time
+	GetClientIP() net.IP
+	GetUserAgent() string
+}
+
+type suspiciousRecord interface {
+	IsSuspicious() bool
+}
+
+type clusteredRecord interface {
+	ClusteringClientID() string
+	ClusterSize() int
+}
+
+type ipStats struct {
+	requests   int
+	suspicious int
+	userAgents map[string]int
+	perMinute  map[int64]int
+	first      time.Time
+	last       time.Time
+}
+
+type uaStats struct {
+	requests  int
+	ips       map[string]bool
+	botTitle  string
+	isKnown   bool
+	isMonitor bool
+}
+
+// Options configures thresholds used when evaluating collected data
+type Options struct {
+	// TopN specifies how many IPs/user agents are listed
+	TopN int
+
+	// OutlierCoeff specifies how far from the Q3 must a per-IP
+	// number of requests be to be considered an outlier
+	// (the formula is `Q3 + OutlierCoeff * IQR`)
+	OutlierCoeff float64
+
+	// MinFreq is a minimum number of requests of an IP to be
+	// considered suspicious because of its traffic
+	MinFreq int
+}
+
+// Collector gathers per-IP, per-user agent and per-time statistics
+// of observed records.
+type Collector struct {
+	opts       Options
+	patterns   *BotPatterns
+	ips        map[string]*ipStats
+	userAgents map[string]*uaStats
+	rates      map[int64]int
+	matched    map[string]int
+	clusters   []ClusterInfo
+	total      int
+	mutex      sync.Mutex
+}
+
+// AddRecord registers a single (raw) input record
+func (c *Collector) AddRecord(rec Record) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.total++
+	ip := rec.GetClientIP().String()
+	ua := rec.GetUserAgent()
+	t := rec.GetTime()
+	bucket := t.Truncate(rateBucket).Unix()
+	c.rates[bucket]++
+
+	ist, ok := c.ips[ip]
+	if !ok {
+		ist = &ipStats{
+			userAgents: make(map[string]int),
+			perMinute:  make(map[int64]int),
+			first:      t,
+		}
+		c.ips[ip] = ist
+	}
+	ist.requests++
+	ist.userAgents[ua]++
+	ist.perMinute[bucket]++
+	if t.Before(ist.first) {
+		ist.first = t
+	}
+	if t.After(ist.last) {
+		ist.last = t
+	}
+	if srec, ok := rec.(suspiciousRecord); ok && srec.IsSuspicious() {
+		ist.suspicious++
+	}
+
+	ust, ok := c.userAgents[ua]
+	if !ok {
+		ust = &uaStats{ips: make(map[string]bool)}
+		ust.botTitle, ust.isMonitor = c.patterns.Find(ua)
+		ust.isKnown = ust.botTitle != ""
+		c.userAgents[ua] = ust
+	}
+	ust.requests++
+	ust.ips[ip] = true
+	if ust.isKnown {
+		c.matched[ust.botTitle]++
+	}
+}
+
+// AddPreprocessed registers a record produced by a transformer's
+// preprocessing. Only records representing a cluster of activity
+// are taken into account.
+func (c *Collector) AddPreprocessed(rec Record) {
+	crec, ok := rec.(clusteredRecord)
+	if !ok || crec.ClusterSize() == 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusters = append(
+		c.clusters,
+		ClusterInfo{
+			ClientID:  crec.ClusteringClientID(),
+			IP:        rec.GetClientIP().String(),
+			UserAgent: rec.GetUserAgent(),
+			Time:      rec.GetTime(),
+			Size:      crec.ClusterSize(),
+		},
+	)
+}
+
+func quartiles(values []int) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	at := func(q float64) float64 {
+		pos := q * float64(len(sorted)-1)
+		lo := int(pos)
+		hi := lo + 1
+		if hi >= len(sorted) {
+			return float64(sorted[lo])
+		}
+		return float64(sorted[lo]) + (pos-float64(lo))*float64(sorted[hi]-sorted[lo])
+	}
+	return at(0.25), at(0.75)
+}
+
+// Report evaluates collected data and creates a report
+func (c *Collector) Report(appType, appVersion string) *Report {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ans := &Report{
+		AppType:      appType,
+		AppVersion:   appVersion,
+		Created:      time.Now(),
+		TotalRecords: c.total,
+		NumIPs:       len(c.ips),
+		NumUAs:       len(c.userAgents),
+		BotPatterns:  make([]PatternMatch, 0, len(c.matched)),
+		Clusters:     c.clusters,
+	}
+
+	counts := make([]int, 0, len(c.ips))
+	for _, v := range c.ips {
+		counts = append(counts, v.requests)
+	}
+	q1, q3 := quartiles(counts)
+	ans.OutlierLimit = q3 + c.opts.OutlierCoeff*(q3-q1)
+
+	ips := make([]IPInfo, 0, len(c.ips))
+	for ip, v := range c.ips {
+		item := IPInfo{
+			IP:            ip,
+			Requests:      v.requests,
+			Suspicious:    v.suspicious,
+			NumUserAgents: len(v.userAgents),
+			First:         v.first,
+			Last:          v.last,
+		}
+		for _, n := range v.perMinute {
+			if n > item.PeakPerMinute {
+				item.PeakPerMinute = n
+			}
+		}
+		var topUA int
+		monitorOnly := true
+		for ua, n := range v.userAgents {
+			if n > topUA {
+				topUA = n
+				item.TopUserAgent = ua
+			}
+			ust := c.userAgents[ua]
+			if !ust.isMonitor {
+				monitorOnly = false
+			}
+			if ust.isKnown && !ust.isMonitor {
+				item.addReason("bot pattern: " + ust.botTitle)
+			}
+		}
+		if float64(v.requests) > ans.OutlierLimit && v.requests >= c.opts.MinFreq {
+			item.addReason("traffic outlier")
+		}
+		if v.suspicious > 0 && v.suspicious*2 >= v.requests {
+			item.addReason("suspicious requests")
+		}
+		item.Monitor = monitorOnly
+		ips = append(ips, item)
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		if len(ips[i].Reasons) != len(ips[j].Reasons) {
+			return len(ips[i].Reasons) > len(ips[j].Reasons)
+		}
+		return ips[i].Requests > ips[j].Requests
+	})
+	for _, item := range ips {
+		// our own monitoring must never end up in the blocklists
+		if len(item.Reasons) > 0 && !item.Monitor {
+			ans.SuspiciousIPs = append(ans.SuspiciousIPs, item.IP)
+		}
+	}
+	if len(ips) > c.opts.TopN {
+		ips = ips[:c.opts.TopN]
+	}
+	ans.TopIPs = ips
+
+	uas := make([]UAInfo, 0, len(c.userAgents))
+	for ua, v := range c.userAgents {
+		uas = append(uas, UAInfo{
+			UserAgent: ua,
+			Requests:  v.requests,
+			NumIPs:    len(v.ips),
+			BotTitle:  v.botTitle,
+			Monitor:   v.isMonitor,
+		})
+		if v.isKnown && !v.isMonitor {
+			ans.SuspiciousUAs = append(ans.SuspiciousUAs, ua)
+		}
+	}
+	sort.Slice(uas, func(i, j int) bool { return uas[i].Requests > uas[j].Requests })
+	sort.Strings(ans.SuspiciousUAs)
+	if len(uas) > c.opts.TopN {
+		uas = uas[:c.opts.TopN]
+	}
+	ans.TopUserAgents = uas
+
+	ans.Rates = make([]RatePoint, 0, len(c.rates))
+	for t, n := range c.rates {
+		ans.Rates = append(ans.Rates, RatePoint{Time: time.Unix(t, 0), Requests: n})
+	}
+	sort.Slice(ans.Rates, func(i, j int) bool { return ans.Rates[i].Time.Before(ans.Rates[j].Time) })
+
+	for title, n := range c.matched {
+		ans.BotPatterns = append(ans.BotPatterns, PatternMatch{Title: title, Requests: n})
+	}
+	sort.Slice(ans.BotPatterns, func(i, j int) bool {
+		return ans.BotPatterns[i].Requests > ans.BotPatterns[j].Requests
+	})
+	return ans
+}
+
+// NewCollector creates a new Collector. The `patterns` argument
+// may be nil in which case no bot pattern matching is performed.
+// Zero values in opts are replaced by defaults.
+func NewCollector(patterns *BotPatterns, opts Options) *Collector {
+	if opts.TopN <= 0 {
+		opts.TopN = DefaultTopN
+	}
+	if opts.OutlierCoeff <= 0 {
+		opts.OutlierCoeff = DefaultOutlierCoeff
+	}
+	if opts.MinFreq <= 0 {
+		opts.MinFreq = DefaultMinFreq
+	}
+	return &Collector{
+		opts:       opts,
+		patterns:   patterns,
+		ips:        make(map[string]*ipStats),
+		userAgents: make(map[string]*uaStats),
+		rates:      make(map[int64]int),
+		matched:    make(map[string]int),
+		clusters:   make([]ClusterInfo, 0, 100),
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package botreport
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	ip  string
+	ua  string
+	dt  time.Time
+	sus bool
+}
+
+func (r *testRecord) GetTime() time.Time   { return r.dt }
+func (r *testRecord) GetClientIP() net.IP  { return net.ParseIP(r.ip) }
+func (r *testRecord) GetUserAgent() string { return r.ua }
+func (r *testRecord) IsSuspicious() bool   { return r.sus }
+
+func TestBotPatternMatchesAllParts(t *testing.T) {
+	patterns := &BotPatterns{
+		Bots: []BotPattern{{Title: "Yahoo! Slurp", Match: []string{"yahoo", "slurp"}}},
+	}
+	title, isMonitor := patterns.Find("Mozilla/5.0 (compatible; Yahoo! Slurp/3.0)")
+	assert.Equal(t, "Yahoo! Slurp", title)
+	assert.False(t, isMonitor)
+	title, _ = patterns.Find("Mozilla/5.0 (compatible; Yahoo!-AdCrawler)")
+	assert.Equal(t, "", title)
+}
+
+func TestCollectorReport(t *testing.T) {
+	patterns := &BotPatterns{
+		Bots: []BotPattern{{Title: "BingBot", Match: []string{"bingbot/"}}},
+	}
+	coll := NewCollector(patterns, Options{MinFreq: 10})
+	dt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		coll.AddRecord(&testRecord{ip: fmt.Sprintf("10.0.0.%d", i), ua: "Firefox", dt: dt})
+	}
+	for i := 0; i < 200; i++ {
+		coll.AddRecord(&testRecord{ip: "192.168.1.1", ua: "python-requests", dt: dt.Add(time.Duration(i) * time.Second)})
+	}
+	coll.AddRecord(&testRecord{ip: "10.0.1.1", ua: "Mozilla/5.0 (compatible; bingbot/2.0)", dt: dt})
+
+	report := coll.Report("kontext", "0.18")
+	assert.Equal(t, 211, report.TotalRecords)
+	assert.Equal(t, 12, report.NumIPs)
+	assert.Equal(t, "192.168.1.1", report.TopIPs[0].IP)
+	assert.Contains(t, report.TopIPs[0].Reasons, "traffic outlier")
+	assert.Equal(t, 60, report.TopIPs[0].PeakPerMinute)
+	assert.ElementsMatch(t, []string{"192.168.1.1", "10.0.1.1"}, report.SuspiciousIPs)
+	assert.Equal(t, []string{"Mozilla/5.0 (compatible; bingbot/2.0)"}, report.SuspiciousUAs)
+	assert.Equal(t, []PatternMatch{{Title: "BingBot", Requests: 1}}, report.BotPatterns)
+	assert.Equal(t, 4, len(report.Rates))
+}
+
+func TestCollectorReportSkipsMonitors(t *testing.T) {
+	patterns := &BotPatterns{
+		Bots:     []BotPattern{{Title: "BingBot", Match: []string{"bingbot/"}}},
+		Monitors: []BotPattern{{Title: "CNC zabbix", Match: []string{"zabbix"}}},
+	}
+	coll := NewCollector(patterns, Options{MinFreq: 10})
+	dt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		coll.AddRecord(&testRecord{ip: fmt.Sprintf("10.0.0.%d", i), ua: "Firefox", dt: dt})
+	}
+	// the monitor is also a traffic outlier
+	for i := 0; i < 200; i++ {
+		coll.AddRecord(&testRecord{ip: "10.0.2.1", ua: "Zabbix", dt: dt.Add(time.Duration(i) * time.Second)})
+	}
+	coll.AddRecord(&testRecord{ip: "10.0.1.1", ua: "Mozilla/5.0 (compatible; bingbot/2.0)", dt: dt})
+
+	report := coll.Report("kontext", "0.18")
+	assert.Equal(t, []string{"10.0.1.1"}, report.SuspiciousIPs)
+	assert.Equal(t, []string{"Mozilla/5.0 (compatible; bingbot/2.0)"}, report.SuspiciousUAs)
+	assert.Equal(t, "10.0.2.1", report.TopIPs[0].IP)
+	assert.True(t, report.TopIPs[0].Monitor)
+	assert.NotContains(t, report.TopIPs[0].Reasons, "bot pattern: CNC zabbix")
+	assert.Equal(t, "Zabbix", report.TopUserAgents[0].UserAgent)
+	assert.True(t, report.TopUserAgents[0].Monitor)
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package botreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"klogproc/common"
+)
+
+// BotPattern describes a known bot/monitoring client. A user agent
+// matches the pattern if it contains all the `Match` substrings
+// (case insensitive).
+type BotPattern struct {
+	Title   string   `json:"title"`
+	Match   []string `json:"match"`
+	Example *string  `json:"example"`
+}
+
+func (bp BotPattern) matches(lcUserAgent string) bool {
+	if len(bp.Match) == 0 {
+		return false
+	}
+	for _, m := range bp.Match {
+		if !strings.Contains(lcUserAgent, strings.ToLower(m)) {
+			return false
+		}
+	}
+	return true
+}
+
+// BotPatterns represents a file with known bots and monitors
+// (see bots.default.json)
+type BotPatterns struct {
+	Bots     []BotPattern `json:"bots"`
+	Monitors []BotPattern `json:"monitors"`
+}
+
+// Find returns title of a first matching bot or monitor pattern
+// along with a flag telling whether the pattern is a monitor
+// (i.e. our own monitoring client which should not be blocked).
+// If nothing matches, an empty string is returned.
+func (bps *BotPatterns) Find(userAgent string) (string, bool) {
+	if bps == nil {
+		return "", false
+	}
+	lcUA := strings.ToLower(userAgent)
+	for _, p := range bps.Bots {
+		if p.matches(lcUA) {
+			return p.Title, false
+		}
+	}
+	for _, p := range bps.Monitors {
+		if p.matches(lcUA) {
+			return p.Title, true
+		}
+	}
+	return "", false
+}
+
+// LoadBotPatterns loads bot definitions from a local file or an URL
+// (see common.LoadSupportedResource)
+func LoadBotPatterns(uri string) (*BotPatterns, error) {
+	rawData, err := common.LoadSupportedResource(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bot patterns: %w", err)
+	}
+	var ans BotPatterns
+	if err := json.Unmarshal(rawData, &ans); err != nil {
+		return nil, fmt.Errorf("failed to load bot patterns: %w", err)
+	}
+	return &ans, nil
+}
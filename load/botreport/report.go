@@ -0,0 +1,279 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package botreport
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	reportJSONFile  = "report.json"
+	reportHTMLFile  = "report.html"
+	blocklistIPFile = "suspicious-ips.txt"
+	blocklistUAFile = "suspicious-user-agents.txt"
+)
+
+// IPInfo contains aggregated data for a single client IP
+type IPInfo struct {
+	IP            string    `json:"ip"`
+	Requests      int       `json:"requests"`
+	Suspicious    int       `json:"suspicious"`
+	PeakPerMinute int       `json:"peakPerMinute"`
+	NumUserAgents int       `json:"numUserAgents"`
+	TopUserAgent  string    `json:"topUserAgent"`
+	First         time.Time `json:"first"`
+	Last          time.Time `json:"last"`
+	Reasons       []string  `json:"reasons,omitempty"`
+
+	// Monitor is true if all the requests of the IP come
+	// from known monitoring clients
+	Monitor bool `json:"monitor,omitempty"`
+}
+
+func (info *IPInfo) addReason(r string) {
+	for _, v := range info.Reasons {
+		if v == r {
+			return
+		}
+	}
+	info.Reasons = append(info.Reasons, r)
+}
+
+// UAInfo contains aggregated data for a single user agent
+type UAInfo struct {
+	UserAgent string `json:"userAgent"`
+	Requests  int    `json:"requests"`
+	NumIPs    int    `json:"numIps"`
+	BotTitle  string `json:"botTitle,omitempty"`
+	Monitor   bool   `json:"monitor,omitempty"`
+}
+
+// RatePoint is a number of requests within a single time bucket
+type RatePoint struct {
+	Time     time.Time `json:"time"`
+	Requests int       `json:"requests"`
+}
+
+// PatternMatch is a number of requests matching a known bot pattern
+type PatternMatch struct {
+	Title    string `json:"title"`
+	Requests int    `json:"requests"`
+}
+
+// ClusterInfo describes a cluster of user activity as detected
+// by a transformer's preprocessing
+type ClusterInfo struct {
+	ClientID  string    `json:"clientId"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Time      time.Time `json:"time"`
+	Size      int       `json:"size"`
+}
+
+// Report is a result of the bot analysis
+type Report struct {
+	AppType       string         `json:"appType"`
+	AppVersion    string         `json:"appVersion"`
+	Created       time.Time      `json:"created"`
+	TotalRecords  int            `json:"totalRecords"`
+	NumIPs        int            `json:"numIps"`
+	NumUAs        int            `json:"numUserAgents"`
+	OutlierLimit  float64        `json:"outlierLimit"`
+	TopIPs        []IPInfo       `json:"topIps"`
+	TopUserAgents []UAInfo       `json:"topUserAgents"`
+	Rates         []RatePoint    `json:"rates"`
+	BotPatterns   []PatternMatch `json:"botPatterns"`
+	Clusters      []ClusterInfo  `json:"clusters"`
+	SuspiciousIPs []string       `json:"suspiciousIps"`
+	SuspiciousUAs []string       `json:"suspiciousUserAgents"`
+
+	// BufferState contains a report provided by the log buffer
+	// analysis state (if available)
+	BufferState any `json:"bufferState,omitempty"`
+}
+
+// MaxRate returns the highest number of requests within
+// a single time bucket
+func (r *Report) MaxRate() int {
+	var ans int
+	for _, v := range r.Rates {
+		if v.Requests > ans {
+			ans = v.Requests
+		}
+	}
+	return ans
+}
+
+func writeLines(path string, lines []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		if _, err := w.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// WriteJSON stores the report as a JSON file
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write JSON bot report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON bot report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML stores the report as a standalone HTML page
+func (r *Report) WriteHTML(path string) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"barWidth": func(v int) int {
+			maxRate := r.MaxRate()
+			if maxRate == 0 {
+				return 0
+			}
+			return v * 600 / maxRate
+		},
+		"fmtTime": func(t time.Time) string {
+			return t.Format(time.RFC3339)
+		},
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to write HTML bot report: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write HTML bot report: %w", err)
+	}
+	defer f.Close()
+	if err := tmpl.Execute(f, r); err != nil {
+		return fmt.Errorf("failed to write HTML bot report: %w", err)
+	}
+	return nil
+}
+
+// WriteBlocklists stores suspicious IPs and user agents as plain
+// text files (one item per line) so they can be used by APIGuard
+// or a firewall.
+func (r *Report) WriteBlocklists(ipPath, uaPath string) error {
+	if err := writeLines(ipPath, r.SuspiciousIPs); err != nil {
+		return fmt.Errorf("failed to write IP blocklist: %w", err)
+	}
+	if err := writeLines(uaPath, r.SuspiciousUAs); err != nil {
+		return fmt.Errorf("failed to write user agent blocklist: %w", err)
+	}
+	return nil
+}
+
+// WriteAll stores all the report variants to a specified directory
+func (r *Report) WriteAll(dirPath string) error {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return fmt.Errorf("failed to create bot report directory: %w", err)
+	}
+	if err := r.WriteJSON(filepath.Join(dirPath, reportJSONFile)); err != nil {
+		return err
+	}
+	if err := r.WriteHTML(filepath.Join(dirPath, reportHTMLFile)); err != nil {
+		return err
+	}
+	return r.WriteBlocklists(
+		filepath.Join(dirPath, blocklistIPFile),
+		filepath.Join(dirPath, blocklistUAFile),
+	)
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Klogproc bot analysis - {{.AppType}} {{.AppVersion}}</title>
+<style>
+body { font-family: sans-serif; font-size: 0.9em; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+td.num { text-align: right; }
+.bar { background-color: #d9534f; height: 0.8em; display: inline-block; }
+.reason { color: #d9534f; }
+</style>
+</head>
+<body>
+<h1>Bot analysis - {{.AppType}} {{.AppVersion}}</h1>
+<p>created: {{fmtTime .Created}}, records: {{.TotalRecords}}, IPs: {{.NumIPs}},
+user agents: {{.NumUAs}}, outlier limit: {{printf "%.1f" .OutlierLimit}} req.</p>
+
+<h2>Top IP addresses</h2>
+<table>
+<tr><th>IP</th><th>requests</th><th>suspicious</th><th>peak/min</th><th>num. UAs</th>
+<th>top user agent</th><th>first</th><th>last</th><th>reasons</th></tr>
+{{range .TopIPs}}
+<tr><td>{{.IP}}</td><td class="num">{{.Requests}}</td><td class="num">{{.Suspicious}}</td>
+<td class="num">{{.PeakPerMinute}}</td><td class="num">{{.NumUserAgents}}</td>
+<td>{{.TopUserAgent}}</td><td>{{fmtTime .First}}</td><td>{{fmtTime .Last}}</td>
+<td class="reason">{{if .Monitor}}monitor (not exported)<br>{{end}}{{range .Reasons}}{{.}}<br>{{end}}</td></tr>
+{{end}}
+</table>
+
+<h2>Top user agents</h2>
+<table>
+<tr><th>user agent</th><th>requests</th><th>num. IPs</th><th>bot</th></tr>
+{{range .TopUserAgents}}
+<tr><td>{{.UserAgent}}</td><td class="num">{{.Requests}}</td><td class="num">{{.NumIPs}}</td>
+<td class="reason">{{.BotTitle}}{{if .Monitor}} (monitor){{end}}</td></tr>
+{{end}}
+</table>
+
+<h2>Matched bot patterns</h2>
+<table>
+<tr><th>bot</th><th>requests</th></tr>
+{{range .BotPatterns}}
+<tr><td>{{.Title}}</td><td class="num">{{.Requests}}</td></tr>
+{{end}}
+</table>
+
+<h2>Clusters</h2>
+<table>
+<tr><th>client ID</th><th>IP</th><th>user agent</th><th>time</th><th>size</th></tr>
+{{range .Clusters}}
+<tr><td>{{.ClientID}}</td><td>{{.IP}}</td><td>{{.UserAgent}}</td><td>{{fmtTime .Time}}</td>
+<td class="num">{{.Size}}</td></tr>
+{{end}}
+</table>
+
+<h2>Requests per minute</h2>
+<table>
+<tr><th>time</th><th>requests</th><th></th></tr>
+{{range .Rates}}
+<tr><td>{{fmtTime .Time}}</td><td class="num">{{.Requests}}</td>
+<td><span class="bar" style="width: {{barWidth .Requests}}px"></span></td></tr>
+{{end}}
+</table>
+</body>
+</html>
+`
@@ -0,0 +1,143 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sampling provides a deterministic selection of a subset
+// of input records. The same configuration (rate, seed, key) always
+// selects the same records which allows e.g. repeated testing of
+// Lua scripts against a representative part of a huge log.
+package sampling
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+)
+
+const (
+	// KeyIP keeps all the records of a single client IP together
+	KeyIP = "ip"
+
+	// KeyUser keeps all the records of a single user together.
+	// For records without user ID, client IP is used instead.
+	KeyUser = "user"
+
+	// KeyRecord samples individual records
+	KeyRecord = "record"
+)
+
+// Record specifies methods the sampler needs from an input record
+type Record interface {
+	GetClientIP() net.IP
+}
+
+// UserRecord is an input record providing an ID of a user
+// (required by KeyUser)
+type UserRecord interface {
+	Record
+
+	// GetUserID returns an ID of the user or an empty string
+	// if the user is not known
+	GetUserID() string
+}
+
+// RateRecord is an output record able to store the sampling
+// rate so the consumers of the data can scale the counts
+type RateRecord interface {
+	SetSamplingRate(rate float64)
+}
+
+// Sampler decides whether a record belongs to a sample
+type Sampler struct {
+	rate      float64
+	seed      string
+	key       string
+	threshold uint64
+}
+
+// Rate returns the sampling rate (0, 1]
+func (s *Sampler) Rate() float64 {
+	return s.rate
+}
+
+// Key returns the attribute used for hashing
+func (s *Sampler) Key() string {
+	return s.key
+}
+
+func (s *Sampler) keyValue(rec Record) string {
+	switch s.key {
+	case KeyUser:
+		if urec, ok := rec.(UserRecord); ok && urec.GetUserID() != "" {
+			return "u:" + urec.GetUserID()
+		}
+	case KeyRecord:
+		data, err := json.Marshal(rec)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return rec.GetClientIP().String()
+}
+
+// Supports tests whether records of the same type as rec
+// can be sampled using the configured key
+func (s *Sampler) Supports(rec Record) error {
+	if _, ok := rec.(UserRecord); s.key == KeyUser && !ok {
+		return fmt.Errorf("sampling key %s not supported by %T (no user ID)", s.key, rec)
+	}
+	return nil
+}
+
+// Accepts tests whether the record belongs to the sample
+func (s *Sampler) Accepts(rec Record) bool {
+	if s.rate >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(s.seed))
+	h.Write([]byte{0})
+	h.Write([]byte(s.keyValue(rec)))
+	return h.Sum64() < s.threshold
+}
+
+// NewSampler creates a new Sampler. The rate must be from the interval (0, 1],
+// the key must be one of KeyIP, KeyUser, KeyRecord (empty value means KeyIP).
+func NewSampler(rate float64, seed, key string) (*Sampler, error) {
+	if rate <= 0 || rate > 1 {
+		return nil, fmt.Errorf("invalid sampling rate %01.4f - must be from (0, 1]", rate)
+	}
+	if key == "" {
+		key = KeyIP
+	}
+	if key != KeyIP && key != KeyUser && key != KeyRecord {
+		return nil, fmt.Errorf("invalid sampling key %s - must be one of: ip, user, record", key)
+	}
+	var threshold uint64
+	if rate >= 1 {
+		threshold = math.MaxUint64
+
+	} else {
+		threshold = uint64(rate * float64(math.MaxUint64))
+	}
+	return &Sampler{
+		rate:      rate,
+		seed:      seed,
+		key:       key,
+		threshold: threshold,
+	}, nil
+}
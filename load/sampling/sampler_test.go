@@ -0,0 +1,100 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	IP string
+}
+
+func (r *testRecord) GetClientIP() net.IP {
+	return net.ParseIP(r.IP)
+}
+
+type testUserRecord struct {
+	testRecord
+	UserID string
+}
+
+func (r *testUserRecord) GetUserID() string {
+	return r.UserID
+}
+
+func TestInvalidRate(t *testing.T) {
+	_, err := NewSampler(0, "", KeyIP)
+	assert.Error(t, err)
+	_, err = NewSampler(1.5, "", KeyIP)
+	assert.Error(t, err)
+	_, err = NewSampler(0.5, "", "foo")
+	assert.Error(t, err)
+}
+
+func TestSamplingIsDeterministic(t *testing.T) {
+	s1, _ := NewSampler(0.1, "abc", KeyIP)
+	s2, _ := NewSampler(0.1, "abc", KeyIP)
+	var numAccepted int
+	for i := 0; i < 10000; i++ {
+		rec := &testRecord{IP: fmt.Sprintf("10.%d.%d.1", i/256, i%256)}
+		assert.Equal(t, s1.Accepts(rec), s2.Accepts(rec))
+		if s1.Accepts(rec) {
+			numAccepted++
+		}
+	}
+	assert.InDelta(t, 1000, numAccepted, 150)
+}
+
+func TestSamplingByUserKeepsUserTogether(t *testing.T) {
+	s, _ := NewSampler(0.5, "", KeyUser)
+	ref := s.Accepts(&testUserRecord{testRecord: testRecord{IP: "10.0.0.1"}, UserID: "42"})
+	for i := 0; i < 100; i++ {
+		rec := &testUserRecord{testRecord: testRecord{IP: fmt.Sprintf("10.0.1.%d", i)}, UserID: "42"}
+		assert.Equal(t, ref, s.Accepts(rec))
+	}
+}
+
+func TestSamplingByUserFallsBackToIP(t *testing.T) {
+	s, _ := NewSampler(0.5, "", KeyUser)
+	ipSampler, _ := NewSampler(0.5, "", KeyIP)
+	for i := 0; i < 100; i++ {
+		ip := fmt.Sprintf("10.0.1.%d", i)
+		assert.Equal(
+			t,
+			ipSampler.Accepts(&testRecord{IP: ip}),
+			s.Accepts(&testUserRecord{testRecord: testRecord{IP: ip}}),
+		)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	s, _ := NewSampler(0.5, "", KeyUser)
+	assert.NoError(t, s.Supports(&testUserRecord{}))
+	assert.Error(t, s.Supports(&testRecord{}))
+	s, _ = NewSampler(0.5, "", KeyIP)
+	assert.NoError(t, s.Supports(&testRecord{}))
+}
+
+func TestFullRateAcceptsAll(t *testing.T) {
+	s, _ := NewSampler(1, "", KeyRecord)
+	assert.True(t, s.Accepts(&testRecord{IP: "10.0.0.1"}))
+}
@@ -25,6 +25,18 @@ import (
 	"sync"
 	"time"
 
+	"klogproc/corpora"
+	"klogproc/errstream"
+	"klogproc/load/accesslog"
+	"klogproc/lookup"
+	"klogproc/luasandbox"
+	"klogproc/servicelog/custom"
+	"klogproc/servicelog/extproc"
+	"klogproc/servicelog/gokit"
+	"klogproc/servicelog/mapping"
+	"klogproc/trfactory"
+	"klogproc/users"
+
 	"github.com/czcorpus/klogproc-core/logbuffer"
 	"github.com/czcorpus/klogproc-core/save"
 	"github.com/czcorpus/klogproc-core/storage"
@@ -44,11 +56,52 @@ type FileConf struct {
 	Path    string `json:"path"`
 	AppType string `json:"appType"`
 	// Version represents a major and minor version signature as used in semantic versioning
-	// (e.g. 0.15, 1.2)
+	// (e.g. 0.15, 1.2). The value `auto` makes klogproc detect the version
+	// from log lines (for app types with multiple versions).
 	Version             string                `json:"version"`
 	Buffer              *logbuffer.BufferConf `json:"buffer"`
 	ScriptPath          string                `json:"scriptPath"`
 	InactivitySecsAlarm int                   `json:"inactivitySecsAlarm"`
+
+	// ScriptLimits configures resources available to the Lua script
+	// (if nil, default limits are applied)
+	ScriptLimits *luasandbox.Limits `json:"scriptLimits"`
+
+	// Mapping configures the `mapping` app type
+	Mapping *mapping.Conf `json:"mapping"`
+
+	// Gokit configures the `gokit` app type
+	Gokit *gokit.Conf `json:"gokit"`
+
+	// Exec configures the `exec` app type
+	Exec *extproc.Conf `json:"exec"`
+
+	// ErrorStream enables writing of application errors (KonText)
+	// as structured documents to a separate index
+	ErrorStream *errstream.Conf `json:"errorStream"`
+
+	// LookupTables are available to Lua scripts (as `lookup.<name>`)
+	// and to some transformers
+	LookupTables []lookup.Conf `json:"lookupTables"`
+
+	// Corpora assigns corpora to records of applications which
+	// do not log them (`syd`, `treq`)
+	Corpora corpora.Conf `json:"corpora"`
+
+	// Users configures resolution of usernames to user IDs
+	// for applications logging only usernames (`ske`, Shiny apps)
+	Users *users.Conf `json:"users"`
+
+	// AccessLogFormat specifies a layout of access log lines (`wag` 0.6,
+	// `mapka` 1 and 2, `ske`) using either nginx `log_format` or Apache
+	// `LogFormat` syntax. If empty, the Apache "combined" format followed
+	// by `rt=$request_time` is expected.
+	AccessLogFormat string `json:"accessLogFormat"`
+
+	// TrustedProxies lists IP addresses and CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header (`http_x_forwarded_for` in AccessLogFormat)
+	// is used to resolve client addresses. If empty, the header is ignored.
+	TrustedProxies []string `json:"trustedProxies"`
 }
 
 func (fc *FileConf) GetAppType() string {
@@ -59,6 +112,14 @@ func (fc *FileConf) GetVersion() string {
 	return fc.Version
 }
 
+// WithVersion returns a copy of the configuration with a different version
+// (this is used when detecting the version automatically)
+func (fc *FileConf) WithVersion(version string) storage.LogProcConf {
+	cp := *fc
+	cp.Version = version
+	return &cp
+}
+
 func (fc *FileConf) GetBuffer() *logbuffer.BufferConf {
 	return fc.Buffer
 }
@@ -71,6 +132,46 @@ func (fc *FileConf) GetPath() string {
 	return fc.Path
 }
 
+func (fc *FileConf) GetScriptLimits() *luasandbox.Limits {
+	return fc.ScriptLimits
+}
+
+func (fc *FileConf) GetMapping() *mapping.Conf {
+	return fc.Mapping
+}
+
+func (fc *FileConf) GetGokit() *gokit.Conf {
+	return fc.Gokit
+}
+
+func (fc *FileConf) GetExec() *extproc.Conf {
+	return fc.Exec
+}
+
+func (fc *FileConf) GetErrorStream() *errstream.Conf {
+	return fc.ErrorStream
+}
+
+func (fc *FileConf) GetUsers() *users.Conf {
+	return fc.Users
+}
+
+func (fc *FileConf) GetCorpora() corpora.Conf {
+	return fc.Corpora
+}
+
+func (fc *FileConf) GetAccessLogFormat() string {
+	return fc.AccessLogFormat
+}
+
+func (fc *FileConf) GetTrustedProxies() []string {
+	return fc.TrustedProxies
+}
+
+func (fc *FileConf) GetLookupTables() []lookup.Conf {
+	return fc.LookupTables
+}
+
 func (fc *FileConf) Validate() error {
 	if pathExists := fs.PathExists(fc.Path); !pathExists {
 		return fmt.Errorf("failed to validate FileConf for %s - path does not exist	", fc.Path)
@@ -80,6 +181,67 @@ func (fc *FileConf) Validate() error {
 			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
 		}
 	}
+	if fc.AppType == custom.AppType && fc.ScriptPath == "" {
+		return fmt.Errorf("failed to validate FileConf for %s: app type custom requires scriptPath", fc.Path)
+	}
+	if fc.Version == trfactory.AutoVersion && len(trfactory.DetectableVersions(fc.AppType)) == 0 {
+		return fmt.Errorf("failed to validate FileConf for %s: version of %s cannot be detected", fc.Path, fc.AppType)
+	}
+	if fc.AppType == mapping.AppType {
+		if fc.Mapping == nil {
+			return fmt.Errorf("failed to validate FileConf for %s: app type mapping requires mapping", fc.Path)
+		}
+		if err := fc.Mapping.Validate(); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
+	if fc.AppType == gokit.AppType {
+		if fc.Gokit == nil {
+			return fmt.Errorf("failed to validate FileConf for %s: app type gokit requires gokit", fc.Path)
+		}
+		if err := fc.Gokit.Validate(); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
+	if fc.AppType == extproc.AppType {
+		if fc.Exec == nil {
+			return fmt.Errorf("failed to validate FileConf for %s: app type exec requires exec", fc.Path)
+		}
+		if err := fc.Exec.Validate(); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
+	for _, lt := range fc.LookupTables {
+		if err := lt.Validate(); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
+	if fc.Users != nil {
+		if err := fc.Users.Validate(); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
+	if err := fc.Corpora.Validate(); err != nil {
+		return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+	}
+	if fc.ErrorStream != nil {
+		if err := fc.ErrorStream.Validate(); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
+	if fc.AccessLogFormat != "" {
+		if _, err := accesslog.ParseFormat(fc.AccessLogFormat); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
+	if _, err := accesslog.ParseTrustedProxies(fc.TrustedProxies); err != nil {
+		return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+	}
+	if fc.ScriptLimits != nil {
+		if err := fc.ScriptLimits.Validate(); err != nil {
+			return fmt.Errorf("failed to validate FileConf for %s: %w", fc.Path, err)
+		}
+	}
 	if fc.InactivitySecsAlarm == 0 {
 		log.Warn().
 			Str("appType", fc.AppType).
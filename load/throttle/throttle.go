@@ -0,0 +1,268 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package throttle limits the rate of records written to a target
+// database. The rate adapts to the database's condition - it backs
+// off when writes fail or their latency rises and it slowly returns
+// to the configured maximum once the database recovers.
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultLatencyLimitMs = 5000
+	DefaultMinRateRatio   = 0.05
+
+	errorBackoffCoeff   = 0.5
+	latencyBackoffCoeff = 0.8
+	recoveryStepRatio   = 0.1
+	adjustInterval      = time.Second
+)
+
+// Conf configures write throttling. Either MaxDocsPerSec or
+// MaxChunksPerSec should be set (if both are, the lower resulting
+// rate is used).
+type Conf struct {
+	MaxDocsPerSec   float64 `json:"maxDocsPerSec"`
+	MaxChunksPerSec float64 `json:"maxChunksPerSec"`
+
+	// MinDocsPerSec is the lowest rate the throttle backs off to.
+	// If not set, 5% of the maximum rate is used.
+	MinDocsPerSec float64 `json:"minDocsPerSec"`
+
+	// LatencyLimitMs specifies a latency of a bulk write request
+	// (i.e. time from sending a complete chunk to its confirmation)
+	// considered as a sign of an overloaded database.
+	LatencyLimitMs int `json:"latencyLimitMs"`
+}
+
+// MaxRate returns max. number of documents per second
+// for a provided chunk size
+func (conf *Conf) MaxRate(chunkSize int) float64 {
+	ans := conf.MaxDocsPerSec
+	if conf.MaxChunksPerSec > 0 {
+		chRate := conf.MaxChunksPerSec * float64(chunkSize)
+		if ans <= 0 || chRate < ans {
+			ans = chRate
+		}
+	}
+	return ans
+}
+
+// IsConfigured tests whether the throttling is enabled
+func (conf *Conf) IsConfigured() bool {
+	return conf != nil && (conf.MaxDocsPerSec > 0 || conf.MaxChunksPerSec > 0)
+}
+
+// ParseRate parses a rate as used by the `-max-rate` command line
+// argument. A plain number means documents per second, a number with
+// the `c` suffix (e.g. `2c`) means chunks per second.
+func ParseRate(v string) (*Conf, error) {
+	v = strings.TrimSpace(v)
+	isChunks := strings.HasSuffix(v, "c")
+	num, err := strconv.ParseFloat(strings.TrimSuffix(v, "c"), 64)
+	if err != nil || num <= 0 {
+		return nil, fmt.Errorf("invalid rate %s - expected a positive number optionally followed by 'c'", v)
+	}
+	if isChunks {
+		return &Conf{MaxChunksPerSec: num}, nil
+	}
+	return &Conf{MaxDocsPerSec: num}, nil
+}
+
+// Throttle paces outgoing records and adapts the pace
+// based on write confirmations.
+type Throttle struct {
+	maxRate      float64
+	minRate      float64
+	currRate     float64
+	latencyLimit time.Duration
+	chunkSize    int
+
+	// pending contains send times of records waiting for confirmation
+	pending []time.Time
+
+	// numConfirmed is the number of confirmed records
+	// (used to find chunk boundaries)
+	numConfirmed int
+
+	// chunkLatency is the latency of the bulk request
+	// of the chunk currently being confirmed
+	chunkLatency time.Duration
+
+	nextSlot    time.Time
+	lastAdjust  time.Time
+	numBackoffs int
+	now         func() time.Time
+	mutex       sync.Mutex
+}
+
+// Rate returns the current rate in documents per second
+func (t *Throttle) Rate() float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.currRate
+}
+
+// NumBackoffs returns how many times the throttle has slowed down
+func (t *Throttle) NumBackoffs() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.numBackoffs
+}
+
+// Wait blocks until a next record can be sent. The caller is expected
+// to send the record right after the method returns.
+func (t *Throttle) Wait(ctx context.Context) error {
+	t.mutex.Lock()
+	now := t.now()
+	if t.nextSlot.Before(now) {
+		t.nextSlot = now
+	}
+	sendAt := t.nextSlot
+	delay := sendAt.Sub(now)
+	t.nextSlot = sendAt.Add(time.Duration(float64(time.Second) / t.currRate))
+	t.pending = append(t.pending, sendAt)
+	t.mutex.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (t *Throttle) backoff(coeff float64, reason string) {
+	now := t.now()
+	if now.Sub(t.lastAdjust) < adjustInterval {
+		return
+	}
+	t.lastAdjust = now
+	t.currRate *= coeff
+	if t.currRate < t.minRate {
+		t.currRate = t.minRate
+	}
+	t.numBackoffs++
+	log.Warn().
+		Str("reason", reason).
+		Float64("docsPerSec", t.currRate).
+		Msg("throttling writes")
+}
+
+func (t *Throttle) recover() {
+	now := t.now()
+	if t.currRate >= t.maxRate || now.Sub(t.lastAdjust) < adjustInterval {
+		return
+	}
+	t.lastAdjust = now
+	t.currRate += t.maxRate * recoveryStepRatio
+	if t.currRate > t.maxRate {
+		t.currRate = t.maxRate
+	}
+	log.Debug().Float64("docsPerSec", t.currRate).Msg("increasing write rate")
+}
+
+// Confirm registers a write confirmation of a record. Confirmations
+// are expected to arrive in the same order the records were sent.
+//
+// Records are written in chunks so the latency is measured per chunk - from
+// sending its last record (once the chunk is complete, it is flushed to
+// the database) to the confirmation of its first record. Time spent
+// on filling the chunk is not included as it depends on the current
+// rate and it would make the throttle slow down even more.
+func (t *Throttle) Confirm(err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.numConfirmed%t.chunkSize == 0 {
+		t.chunkLatency = 0
+		if len(t.pending) > 0 {
+			// an incomplete chunk is flushed along with its last available record
+			flushedAt := t.pending[min(t.chunkSize, len(t.pending))-1]
+			t.chunkLatency = t.now().Sub(flushedAt)
+		}
+	}
+	t.numConfirmed++
+	if len(t.pending) > 0 {
+		t.pending = t.pending[1:]
+	}
+	if err != nil {
+		t.backoff(errorBackoffCoeff, "write error")
+
+	} else if t.chunkLatency > t.latencyLimit {
+		t.backoff(latencyBackoffCoeff, fmt.Sprintf("write latency %s", t.chunkLatency))
+
+	} else {
+		t.recover()
+	}
+}
+
+// Relay passes items from `in` to `out` at the pace given by the throttle.
+// Once `in` is closed (or the context is cancelled), `out` is closed too.
+func Relay[T any](ctx context.Context, t *Throttle, in <-chan T, out chan<- T) {
+	defer close(out)
+	for item := range in {
+		if err := t.Wait(ctx); err != nil {
+			log.Warn().Err(err).Msg("throttled writing interrupted")
+			for range in {
+			}
+			return
+		}
+		out <- item
+	}
+}
+
+// NewThrottle creates a new Throttle for a specified configuration
+// and the size of write chunks
+func NewThrottle(conf *Conf, chunkSize int) (*Throttle, error) {
+	maxRate := conf.MaxRate(chunkSize)
+	if maxRate <= 0 {
+		return nil, fmt.Errorf("failed to create write throttle: max. rate must be positive")
+	}
+	minRate := conf.MinDocsPerSec
+	if minRate <= 0 || minRate > maxRate {
+		minRate = maxRate * DefaultMinRateRatio
+	}
+	latencyLimit := conf.LatencyLimitMs
+	if latencyLimit <= 0 {
+		latencyLimit = DefaultLatencyLimitMs
+	}
+	if chunkSize <= 0 {
+		chunkSize = 1
+	}
+	return &Throttle{
+		maxRate:      maxRate,
+		minRate:      minRate,
+		currRate:     maxRate,
+		latencyLimit: time.Duration(latencyLimit) * time.Millisecond,
+		chunkSize:    chunkSize,
+		pending:      make([]time.Time, 0, chunkSize*2),
+		now:          time.Now,
+	}, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRate(t *testing.T) {
+	conf, err := ParseRate("500")
+	assert.NoError(t, err)
+	assert.Equal(t, 500.0, conf.MaxRate(100))
+
+	conf, err = ParseRate("2c")
+	assert.NoError(t, err)
+	assert.Equal(t, 200.0, conf.MaxRate(100))
+
+	_, err = ParseRate("-1")
+	assert.Error(t, err)
+	_, err = ParseRate("foo")
+	assert.Error(t, err)
+}
+
+func TestConfMaxRateUsesLowerValue(t *testing.T) {
+	conf := Conf{MaxDocsPerSec: 1000, MaxChunksPerSec: 2}
+	assert.Equal(t, 200.0, conf.MaxRate(100))
+	assert.Equal(t, 1000.0, conf.MaxRate(1000))
+}
+
+func TestBackoffAndRecovery(t *testing.T) {
+	th, err := NewThrottle(&Conf{MaxDocsPerSec: 100, LatencyLimitMs: 1000}, 10)
+	assert.NoError(t, err)
+	curr := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	th.now = func() time.Time { return curr }
+
+	th.Confirm(errors.New("write failed"))
+	assert.Equal(t, 50.0, th.Rate())
+
+	// backoff is applied at most once per adjust interval
+	th.Confirm(errors.New("write failed"))
+	assert.Equal(t, 50.0, th.Rate())
+
+	curr = curr.Add(2 * time.Second)
+	th.Confirm(nil)
+	assert.Equal(t, 60.0, th.Rate())
+
+	for i := 0; i < 10; i++ {
+		curr = curr.Add(2 * time.Second)
+		th.Confirm(nil)
+	}
+	assert.Equal(t, 100.0, th.Rate())
+	assert.Equal(t, 1, th.NumBackoffs())
+}
+
+func TestBackoffOnLatency(t *testing.T) {
+	th, err := NewThrottle(&Conf{MaxDocsPerSec: 100, LatencyLimitMs: 1000}, 10)
+	assert.NoError(t, err)
+	curr := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	th.now = func() time.Time { return curr }
+	assert.NoError(t, th.Wait(context.Background()))
+	curr = curr.Add(3 * time.Second)
+	th.Confirm(nil)
+	assert.Equal(t, 80.0, th.Rate())
+}
+
+func TestLatencyExcludesChunkFilling(t *testing.T) {
+	th, err := NewThrottle(&Conf{MaxDocsPerSec: 1, LatencyLimitMs: 1000}, 10)
+	assert.NoError(t, err)
+	curr := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	th.now = func() time.Time { return curr }
+	for round := 0; round < 3; round++ {
+		// filling the chunk takes 10 seconds at the rate of 1 doc/s
+		for i := 0; i < 10; i++ {
+			curr = curr.Add(time.Second)
+			assert.NoError(t, th.Wait(context.Background()))
+		}
+		curr = curr.Add(200 * time.Millisecond)
+		for i := 0; i < 10; i++ {
+			th.Confirm(nil)
+		}
+	}
+	assert.Equal(t, 1.0, th.Rate())
+	assert.Equal(t, 0, th.NumBackoffs())
+}
+
+func TestBackoffOnChunkLatency(t *testing.T) {
+	th, err := NewThrottle(&Conf{MaxDocsPerSec: 1, LatencyLimitMs: 1000}, 10)
+	assert.NoError(t, err)
+	curr := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	th.now = func() time.Time { return curr }
+	for i := 0; i < 10; i++ {
+		curr = curr.Add(time.Second)
+		assert.NoError(t, th.Wait(context.Background()))
+	}
+	curr = curr.Add(1500 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		th.Confirm(nil)
+	}
+	assert.Equal(t, 0.8, th.Rate())
+	assert.Equal(t, 1, th.NumBackoffs())
+}
+
+func TestMinRate(t *testing.T) {
+	th, err := NewThrottle(&Conf{MaxDocsPerSec: 100}, 10)
+	assert.NoError(t, err)
+	curr := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	th.now = func() time.Time { return curr }
+	for i := 0; i < 20; i++ {
+		curr = curr.Add(2 * time.Second)
+		th.Confirm(errors.New("write failed"))
+	}
+	assert.Equal(t, 5.0, th.Rate())
+}
+
+func TestRelay(t *testing.T) {
+	th, err := NewThrottle(&Conf{MaxDocsPerSec: 1000}, 10)
+	assert.NoError(t, err)
+	in := make(chan int)
+	out := make(chan int, 10)
+	go func() {
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	Relay(context.Background(), th, in, out)
+	ans := make([]int, 0, 5)
+	for v := range out {
+		ans = append(ans, v)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, ans)
+}
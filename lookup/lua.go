@@ -0,0 +1,129 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"strconv"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	luaRegistryTypeName = "klogproc.lookup"
+	luaTableTypeName    = "klogproc.lookup.table"
+	luaValueTypeName    = "klogproc.lookup.value"
+)
+
+func luaReadOnly(L *lua.LState) int {
+	L.RaiseError("lookup tables are read-only")
+	return 0
+}
+
+// luaMetatable returns a (per-state cached) metatable of read-only
+// userdata with the provided __index function
+func luaMetatable(L *lua.LState, typeName string, index lua.LGFunction) lua.LValue {
+	if mt, ok := L.GetTypeMetatable(typeName).(*lua.LTable); ok {
+		return mt
+	}
+	mt := L.NewTypeMetatable(typeName)
+	L.SetField(mt, "__index", L.NewFunction(index))
+	L.SetField(mt, "__newindex", L.NewFunction(luaReadOnly))
+	L.SetField(mt, "__metatable", lua.LFalse)
+	return mt
+}
+
+// luaValue converts a table value to Lua. Nested objects and arrays
+// are exported as read-only proxies.
+func luaValue(L *lua.LState, v any) lua.LValue {
+	switch tv := v.(type) {
+	case string:
+		return lua.LString(tv)
+	case float64:
+		return lua.LNumber(tv)
+	case bool:
+		return lua.LBool(tv)
+	case map[string]any, []any:
+		ud := L.NewUserData()
+		ud.Value = tv
+		ud.Metatable = luaMetatable(L, luaValueTypeName, luaValueIndex)
+		return ud
+	default:
+		return lua.LNil
+	}
+}
+
+func luaValueIndex(L *lua.LState) int {
+	ud := L.CheckUserData(1)
+	key := L.Get(2)
+	switch tv := ud.Value.(type) {
+	case map[string]any:
+		L.Push(luaValue(L, tv[lua.LVAsString(key)]))
+	case []any:
+		idx, ok := key.(lua.LNumber)
+		if ok && int(idx) >= 1 && int(idx) <= len(tv) {
+			L.Push(luaValue(L, tv[int(idx)-1]))
+
+		} else {
+			L.Push(lua.LNil)
+		}
+	default:
+		L.Push(lua.LNil)
+	}
+	return 1
+}
+
+func luaTableIndex(L *lua.LState) int {
+	tbl := L.CheckUserData(1).Value.(*Table)
+	key := L.Get(2)
+	if n, ok := key.(lua.LNumber); ok {
+		// keys are always strings (e.g. numeric user IDs in JSON objects)
+		key = lua.LString(strconv.FormatFloat(float64(n), 'f', -1, 64))
+	}
+	v, _ := tbl.Get(lua.LVAsString(key))
+	L.Push(luaValue(L, v))
+	return 1
+}
+
+func luaRegistryIndex(L *lua.LState) int {
+	tables := L.CheckUserData(1).Value.(map[string]*lua.LUserData)
+	if ud, ok := tables[L.CheckString(2)]; ok {
+		L.Push(ud)
+
+	} else {
+		L.Push(lua.LNil)
+	}
+	return 1
+}
+
+// Register defines the global `lookup` value in a Lua state. It provides
+// read-only access to all the tables in the registry (e.g. `lookup.users["john"]`).
+// Values are read from the current version of a table so scripts always see
+// reloaded data. The tables support only indexing (i.e. neither the `#` operator
+// nor `pairs` can be used with them).
+func (r *Registry) Register(L *lua.LState) {
+	tables := make(map[string]*lua.LUserData)
+	for _, name := range r.Names() {
+		ud := L.NewUserData()
+		ud.Value = r.Get(name)
+		ud.Metatable = luaMetatable(L, luaTableTypeName, luaTableIndex)
+		tables[name] = ud
+	}
+	ud := L.NewUserData()
+	ud.Value = tables
+	ud.Metatable = luaMetatable(L, luaRegistryTypeName, luaRegistryIndex)
+	L.SetGlobal("lookup", ud)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestRegister(t *testing.T) {
+	users := writeFile(t, "users.json", `{"alice": 12, "b\"o\nb": "x", "carol": {"groups": ["a", "b"]}, "7": "seven"}`)
+	ips := writeFile(t, "ips.json", `["10.0.0.1"]`)
+	r, err := NewRegistry([]Conf{{Name: "users", Path: users}, {Name: "ips", Path: ips}})
+	assert.NoError(t, err)
+
+	L := lua.NewState()
+	defer L.Close()
+	r.Register(L)
+	assert.NoError(t, L.DoString(`
+		assert(lookup.users.alice == 12)
+		assert(lookup.users["b\"o\nb"] == "x")
+		assert(lookup.users.carol.groups[2] == "b")
+		assert(lookup.users.carol.groups[3] == nil)
+		assert(lookup.users[7] == "seven")
+		assert(lookup.ips["10.0.0.1"] == true)
+		assert(lookup.ips["10.0.0.2"] == nil)
+		assert(lookup.foo == nil)
+	`))
+	assert.Error(t, L.DoString(`lookup.users.dave = 1`))
+	assert.Error(t, L.DoString(`lookup.users = {}`))
+	assert.Error(t, L.DoString(`lookup.users.carol.groups[1] = "x"`))
+	assert.Error(t, L.DoString(`lookup.users.carol.name = "x"`))
+	assert.NoError(t, L.DoString(`assert(getmetatable(lookup.users) == false)`))
+}
+
+func TestRegisterSeesReloadedData(t *testing.T) {
+	path := writeFile(t, "users.json", `{"alice": 12}`)
+	r, err := NewRegistry([]Conf{{Name: "reloaded", Path: path}})
+	assert.NoError(t, err)
+	L := lua.NewState()
+	defer L.Close()
+	r.Register(L)
+	assert.NoError(t, L.DoString(`assert(lookup.reloaded.alice == 12)`))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"alice": 13}`), 0644))
+	future := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(path, future, future))
+	changed, err := r.ReloadIfChanged()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, L.DoString(`assert(lookup.reloaded.alice == 13)`))
+}
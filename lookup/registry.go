@@ -0,0 +1,110 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// tableCache makes sure the same file is loaded only once
+	// even if it is configured for multiple log files
+	tableCache      = make(map[Conf]*Table)
+	tableCacheMutex sync.Mutex
+)
+
+// Registry is a set of named lookup tables
+type Registry struct {
+	tables map[string]*Table
+}
+
+// Get returns a table by its name (or nil if not found)
+func (r *Registry) Get(name string) *Table {
+	if r == nil {
+		return nil
+	}
+	return r.tables[name]
+}
+
+// Names returns sorted names of all the tables
+func (r *Registry) Names() []string {
+	if r == nil {
+		return []string{}
+	}
+	ans := make([]string, 0, len(r.tables))
+	for k := range r.tables {
+		ans = append(ans, k)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+// Version returns a value which changes each time some
+// of the tables is reloaded
+func (r *Registry) Version() int64 {
+	if r == nil {
+		return 0
+	}
+	var ans int64
+	for _, t := range r.tables {
+		ans += t.Version()
+	}
+	return ans
+}
+
+// ReloadIfChanged reloads all the modified tables. It returns
+// true if at least one table has been reloaded.
+func (r *Registry) ReloadIfChanged() (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+	var ans bool
+	for _, t := range r.tables {
+		changed, err := t.ReloadIfChanged()
+		if err != nil {
+			return ans, err
+		}
+		ans = ans || changed
+	}
+	return ans, nil
+}
+
+// NewRegistry loads configured tables. Tables with the same configuration
+// are shared within the process.
+func NewRegistry(confs []Conf) (*Registry, error) {
+	tableCacheMutex.Lock()
+	defer tableCacheMutex.Unlock()
+	ans := &Registry{tables: make(map[string]*Table, len(confs))}
+	for _, conf := range confs {
+		if _, ok := ans.tables[conf.Name]; ok {
+			return nil, fmt.Errorf("duplicate lookup table %s", conf.Name)
+		}
+		tbl, ok := tableCache[conf]
+		if !ok {
+			var err error
+			tbl, err = NewTable(conf)
+			if err != nil {
+				return nil, err
+			}
+			tableCache[conf] = tbl
+		}
+		ans.tables[conf.Name] = tbl
+	}
+	return ans, nil
+}
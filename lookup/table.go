@@ -0,0 +1,319 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lookup provides named read-only lookup tables loaded from JSON
+// or CSV files (e.g. user maps, corpus aliases, lists of internal IP
+// addresses). The tables are available both to Go transformers and
+// to Lua scripts and they are reloaded once their files change.
+package lookup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+
+	// DefaultCheckIntervalSecs specifies how often a table file
+	// is tested for changes when accessing the table
+	DefaultCheckIntervalSecs = 30
+)
+
+// Conf configures a single lookup table.
+//
+// JSON files must contain either an object (keys are mapped to values)
+// or an array of strings (a set - all the values are mapped to true).
+//
+// CSV files must contain a header. Keys are taken from KeyColumn
+// (the first column by default). Values are taken from ValueColumn.
+// If ValueColumn is empty, a file with two columns maps keys to the
+// second column and a file with more columns maps keys to whole rows
+// (header => value).
+type Conf struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Format      string `json:"format"`
+	KeyColumn   string `json:"keyColumn"`
+	ValueColumn string `json:"valueColumn"`
+	Delimiter   string `json:"delimiter"`
+}
+
+// GetFormat returns the configured format or a format derived
+// from the file suffix
+func (conf Conf) GetFormat() string {
+	if conf.Format != "" {
+		return conf.Format
+	}
+	if filepath.Ext(conf.Path) == ".csv" {
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+// Validate checks the configuration
+func (conf Conf) Validate() error {
+	if conf.Name == "" {
+		return errors.New("invalid lookup table: missing name")
+	}
+	if conf.Path == "" {
+		return fmt.Errorf("invalid lookup table %s: missing path", conf.Name)
+	}
+	switch conf.GetFormat() {
+	case FormatJSON:
+		if conf.KeyColumn != "" || conf.ValueColumn != "" || conf.Delimiter != "" {
+			return fmt.Errorf("invalid lookup table %s: columns and delimiter can be used only with CSV", conf.Name)
+		}
+	case FormatCSV:
+		if len([]rune(conf.Delimiter)) > 1 {
+			return fmt.Errorf("invalid lookup table %s: delimiter must be a single character", conf.Name)
+		}
+	default:
+		return fmt.Errorf("invalid lookup table %s: unsupported format %s", conf.Name, conf.Format)
+	}
+	return nil
+}
+
+// Table is a read-only key-value table. It is safe for concurrent use.
+type Table struct {
+	conf          Conf
+	data          atomic.Pointer[map[string]any]
+	mtime         time.Time
+	lastCheck     time.Time
+	checkInterval time.Duration
+	version       atomic.Int64
+	mutex         sync.Mutex
+}
+
+func loadJSON(r io.Reader) (map[string]any, error) {
+	var tmp any
+	if err := json.NewDecoder(r).Decode(&tmp); err != nil {
+		return nil, err
+	}
+	switch tTmp := tmp.(type) {
+	case map[string]any:
+		return tTmp, nil
+	case []any:
+		ans := make(map[string]any, len(tTmp))
+		for _, v := range tTmp {
+			sv, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("set items must be strings, found %v", v)
+			}
+			ans[sv] = true
+		}
+		return ans, nil
+	default:
+		return nil, errors.New("expected an object or an array")
+	}
+}
+
+func columnIndex(header []string, name string) int {
+	for i, v := range header {
+		if v == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func loadCSV(r io.Reader, conf Conf) (map[string]any, error) {
+	cr := csv.NewReader(r)
+	if conf.Delimiter != "" {
+		cr.Comma = []rune(conf.Delimiter)[0]
+	}
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	keyIdx := 0
+	if conf.KeyColumn != "" {
+		keyIdx = columnIndex(header, conf.KeyColumn)
+		if keyIdx < 0 {
+			return nil, fmt.Errorf("key column %s not found", conf.KeyColumn)
+		}
+	}
+	valIdx := -1
+	if conf.ValueColumn != "" {
+		valIdx = columnIndex(header, conf.ValueColumn)
+		if valIdx < 0 {
+			return nil, fmt.Errorf("value column %s not found", conf.ValueColumn)
+		}
+
+	} else if len(header) == 2 {
+		valIdx = 1 - keyIdx
+	}
+	ans := make(map[string]any)
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if valIdx >= 0 {
+			ans[row[keyIdx]] = row[valIdx]
+			continue
+		}
+		item := make(map[string]any, len(header))
+		for i, h := range header {
+			item[h] = row[i]
+		}
+		ans[row[keyIdx]] = item
+	}
+	return ans, nil
+}
+
+func (t *Table) load() error {
+	f, err := os.Open(t.conf.Path)
+	if err != nil {
+		return fmt.Errorf("failed to load lookup table %s: %w", t.conf.Name, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to load lookup table %s: %w", t.conf.Name, err)
+	}
+	var data map[string]any
+	if t.conf.GetFormat() == FormatCSV {
+		data, err = loadCSV(f, t.conf)
+
+	} else {
+		data, err = loadJSON(f)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load lookup table %s from %s: %w", t.conf.Name, t.conf.Path, err)
+	}
+	t.data.Store(&data)
+	t.mtime = info.ModTime()
+	t.version.Add(1)
+	return nil
+}
+
+// Name returns the name of the table
+func (t *Table) Name() string {
+	return t.conf.Name
+}
+
+// Version returns a number increased with each (re)load of the table
+func (t *Table) Version() int64 {
+	return t.version.Load()
+}
+
+// ReloadIfChanged reloads the table in case its file has been modified.
+// In case of an error, the previous data are kept.
+func (t *Table) ReloadIfChanged() (bool, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.lastCheck = time.Now()
+	info, err := os.Stat(t.conf.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check lookup table %s: %w", t.conf.Name, err)
+	}
+	if !info.ModTime().After(t.mtime) {
+		return false, nil
+	}
+	if err := t.load(); err != nil {
+		// prevent repeated reloading of the same broken file
+		t.mtime = info.ModTime()
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *Table) current() map[string]any {
+	if t.checkInterval > 0 {
+		t.mutex.Lock()
+		needsCheck := time.Since(t.lastCheck) > t.checkInterval
+		t.mutex.Unlock()
+		if needsCheck {
+			// errors are ignored here as the previous data remain valid
+			t.ReloadIfChanged()
+		}
+	}
+	return *t.data.Load()
+}
+
+// Get returns a value for a key
+func (t *Table) Get(key string) (any, bool) {
+	v, ok := t.current()[key]
+	return v, ok
+}
+
+// Has tests whether the table contains a key
+func (t *Table) Has(key string) bool {
+	_, ok := t.current()[key]
+	return ok
+}
+
+// GetString returns a value as a string. Non-string values
+// are reported as missing.
+func (t *Table) GetString(key string) (string, bool) {
+	v, ok := t.current()[key].(string)
+	return v, ok
+}
+
+// GetInt returns a value as an integer. Numeric strings
+// are converted.
+func (t *Table) GetInt(key string) (int, bool) {
+	switch v := t.current()[key].(type) {
+	case float64:
+		return int(v), true
+	case string:
+		ans, err := strconv.Atoi(v)
+		return ans, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Len returns the number of items in the table
+func (t *Table) Len() int {
+	return len(t.current())
+}
+
+// Snapshot returns current data of the table. The returned map
+// must not be modified.
+func (t *Table) Snapshot() map[string]any {
+	return t.current()
+}
+
+// NewTable loads a table according to the configuration
+func NewTable(conf Conf) (*Table, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	ans := &Table{
+		conf:          conf,
+		checkInterval: time.Duration(DefaultCheckIntervalSecs) * time.Second,
+		lastCheck:     time.Now(),
+	}
+	if err := ans.load(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	return path
+}
+
+func TestLoadJSONMap(t *testing.T) {
+	path := writeFile(t, "users.json", `{"alice": 12, "bob": "13", "carol": true}`)
+	tbl, err := NewTable(Conf{Name: "users", Path: path})
+	assert.NoError(t, err)
+	v, ok := tbl.GetInt("alice")
+	assert.True(t, ok)
+	assert.Equal(t, 12, v)
+	v, ok = tbl.GetInt("bob")
+	assert.True(t, ok)
+	assert.Equal(t, 13, v)
+	_, ok = tbl.GetInt("carol")
+	assert.False(t, ok)
+	assert.False(t, tbl.Has("dave"))
+	assert.Equal(t, 3, tbl.Len())
+}
+
+func TestLoadJSONSet(t *testing.T) {
+	path := writeFile(t, "ips.json", `["10.0.0.1", "10.0.0.2"]`)
+	tbl, err := NewTable(Conf{Name: "internalIPs", Path: path})
+	assert.NoError(t, err)
+	assert.True(t, tbl.Has("10.0.0.2"))
+	assert.False(t, tbl.Has("10.0.0.3"))
+}
+
+func TestLoadCSV(t *testing.T) {
+	path := writeFile(t, "corpora.csv", "alias,name,size\nsyn,syn2020,100\nintercorp,intercorp_v16,200\n")
+	tbl, err := NewTable(Conf{Name: "corpora", Path: path, ValueColumn: "name"})
+	assert.NoError(t, err)
+	v, ok := tbl.GetString("syn")
+	assert.True(t, ok)
+	assert.Equal(t, "syn2020", v)
+
+	tbl, err = NewTable(Conf{Name: "corpora", Path: path, KeyColumn: "name"})
+	assert.NoError(t, err)
+	row, ok := tbl.Get("syn2020")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"alias": "syn", "name": "syn2020", "size": "100"}, row)
+
+	_, err = NewTable(Conf{Name: "corpora", Path: path, KeyColumn: "foo"})
+	assert.Error(t, err)
+}
+
+func TestLoadCSVTwoColumns(t *testing.T) {
+	path := writeFile(t, "users.tsv", "username;id\nalice;12\n")
+	tbl, err := NewTable(Conf{Name: "users", Path: path, Format: FormatCSV, Delimiter: ";"})
+	assert.NoError(t, err)
+	v, ok := tbl.GetInt("alice")
+	assert.True(t, ok)
+	assert.Equal(t, 12, v)
+}
+
+func TestReloadIfChanged(t *testing.T) {
+	path := writeFile(t, "users.json", `{"alice": 12}`)
+	tbl, err := NewTable(Conf{Name: "users", Path: path})
+	assert.NoError(t, err)
+	changed, err := tbl.ReloadIfChanged()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"bob": 13}`), 0644))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, future, future))
+	changed, err = tbl.ReloadIfChanged()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, tbl.Has("bob"))
+	assert.False(t, tbl.Has("alice"))
+	assert.Equal(t, int64(2), tbl.Version())
+
+	// broken file keeps the previous data
+	assert.NoError(t, os.WriteFile(path, []byte(`{"bob": `), 0644))
+	future = future.Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, future, future))
+	_, err = tbl.ReloadIfChanged()
+	assert.Error(t, err)
+	assert.True(t, tbl.Has("bob"))
+}
+
+func TestConfValidate(t *testing.T) {
+	assert.NoError(t, Conf{Name: "a", Path: "a.csv", KeyColumn: "x"}.Validate())
+	assert.Error(t, Conf{Name: "a", Path: "a.json", KeyColumn: "x"}.Validate())
+	assert.Error(t, Conf{Path: "a.json"}.Validate())
+	assert.Error(t, Conf{Name: "a", Path: "a.xml", Format: "xml"}.Validate())
+}
+
+func TestRegistry(t *testing.T) {
+	path := writeFile(t, "users.json", `{"alice": 12}`)
+	r1, err := NewRegistry([]Conf{{Name: "users", Path: path}})
+	assert.NoError(t, err)
+	r2, err := NewRegistry([]Conf{{Name: "users", Path: path}})
+	assert.NoError(t, err)
+	assert.Same(t, r1.Get("users"), r2.Get("users"))
+	assert.Nil(t, r1.Get("foo"))
+	_, err = NewRegistry([]Conf{{Name: "users", Path: path}, {Name: "users", Path: path}})
+	assert.Error(t, err)
+}
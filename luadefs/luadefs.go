@@ -0,0 +1,272 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package luadefs generates LuaLS (EmmyLua) annotation files describing
+// input and output records of an application along with the functions
+// and global values available to Klogproc Lua scripts.
+package luadefs
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const builtins = `---@class ServiceLogBuffer
+ServiceLogBuffer = {}
+
+---Applies the hardcoded (Go) transformation
+---@param input_rec InputRecord
+---@param tz_shift_mins? integer
+---@return OutputRecord
+function transform_default(input_rec, tz_shift_mins) end
+
+---Applies the hardcoded (Go) preprocessing
+---@param input_rec InputRecord
+---@param buffer ServiceLogBuffer
+---@return InputRecord[]
+function preprocess_default(input_rec, buffer) end
+
+---Creates a new empty output record
+---@return OutputRecord
+function new_out_record() end
+
+---Sets a property of an output record (fails if there is no such property)
+---@param rec OutputRecord
+---@param name string
+---@param value any
+function set_out_prop(rec, name, value) end
+
+---Tests whether a record has a property
+---@param rec InputRecord|OutputRecord
+---@param name string
+---@return boolean
+function record_prop_exists(rec, name) end
+
+---Creates a deterministic ID of an output record (the ID property is ignored)
+---@param rec OutputRecord
+---@return string
+function out_rec_deterministic_id(rec) end
+
+---Shifts the record time by the specified number of minutes
+---@param rec OutputRecord
+---@param num_min integer
+function datetime_add_minutes(rec, num_min) end
+
+---Tests whether the record time is before datetime (2006-01-02T15:04:05-07:00)
+---@param rec OutputRecord
+---@param datetime string
+---@return boolean
+function is_before_datetime(rec, datetime) end
+
+---Tests whether the record time is after datetime (2006-01-02T15:04:05-07:00)
+---@param rec OutputRecord
+---@param datetime string
+---@return boolean
+function is_after_datetime(rec, datetime) end
+
+---@class Log
+---@field debug fun(msg: string, args?: table<string, any>)
+---@field info fun(msg: string, args?: table<string, any>)
+---@field warn fun(msg: string, args?: table<string, any>)
+---@field error fun(msg: string, args?: table<string, any>)
+log = {}
+
+---Read-only lookup tables configured via lookupTables
+---@type table<string, table<string, any>>
+lookup = {}
+
+---@type string
+app_type = ""
+
+---@type string
+app_version = ""
+
+---@type integer[]
+anonymous_users = {}
+
+---Decides whether and how the input record should be processed
+---@param input_rec InputRecord
+---@param buffer ServiceLogBuffer
+---@return InputRecord[]
+function preprocess(input_rec, buffer) end
+
+---Converts an input record into an output record
+---@param input_rec InputRecord
+---@return OutputRecord
+function transform(input_rec) end
+`
+
+const parseLineDefs = `
+---@class ParsedLine
+---@field time string|integer
+---@field ip? string
+---@field user_agent? string
+---@field user_id? string
+---@field is_query? boolean
+---@field ignore? boolean
+---@field suspicious? boolean
+---@field [string] any
+
+---Parses a raw log line (return nil to ignore the line)
+---@param line string
+---@return ParsedLine?
+function parse_line(line) end
+`
+
+// Options configure the generated definitions
+type Options struct {
+	// WithParseLine adds a definition of the `parse_line` function
+	// (used by the `custom` app type)
+	WithParseLine bool
+}
+
+type classDef struct {
+	name   string
+	fields []string
+}
+
+type generator struct {
+	classes []classDef
+	seen    map[string]bool
+}
+
+func (g *generator) luaType(t reflect.Type, className string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return g.luaType(t.Elem(), className) + "[]"
+	case reflect.Map:
+		return fmt.Sprintf("table<%s, %s>", g.luaType(t.Key(), className), g.luaType(t.Elem(), className))
+	case reflect.Struct:
+		if t.PkgPath() == "time" && t.Name() == "Time" {
+			return "string"
+		}
+		g.addClass(t, className)
+		return className
+	default:
+		return "any"
+	}
+}
+
+// collectFields returns annotations of all the exported fields
+// including the ones promoted from embedded structs
+func (g *generator) collectFields(t reflect.Type, className string) []string {
+	var ans []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			ans = append(ans, g.collectFields(field.Type, className)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		ftype := g.luaType(field.Type, className+"."+field.Name)
+		ans = append(ans, fmt.Sprintf("---@field %s %s", field.Name, ftype))
+	}
+	return ans
+}
+
+func (g *generator) addClass(t reflect.Type, name string) {
+	if g.seen[name] {
+		return
+	}
+	g.seen[name] = true
+	idx := len(g.classes)
+	g.classes = append(g.classes, classDef{name: name})
+	g.classes[idx].fields = g.collectFields(t, name)
+}
+
+func (g *generator) write(w io.Writer) error {
+	for _, c := range g.classes {
+		if _, err := fmt.Fprintf(w, "---@class %s\n", c.name); err != nil {
+			return err
+		}
+		if len(c.fields) > 0 {
+			if _, err := fmt.Fprintln(w, strings.Join(c.fields, "\n")); err != nil {
+				return err
+			}
+		}
+		if c.name == "InputRecord" {
+			if _, err := fmt.Fprintln(w, "---@field GetTime fun(): string record time in RFC3339 format"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s = {}\n\n", strings.ReplaceAll(c.name, ".", "_")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func structType(v any) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %v", t)
+	}
+	return t, nil
+}
+
+// Write writes annotations for a respective input and output records
+// and all the built-in functions and global values
+func Write(w io.Writer, appType, version string, inputRec, outputRec any, opts Options) error {
+	t1, err := structType(inputRec)
+	if err != nil {
+		return fmt.Errorf("failed to generate Lua definitions: %w", err)
+	}
+	t2, err := structType(outputRec)
+	if err != nil {
+		return fmt.Errorf("failed to generate Lua definitions: %w", err)
+	}
+	g := &generator{seen: make(map[string]bool)}
+	g.addClass(t1, "InputRecord")
+	g.addClass(t2, "OutputRecord")
+	header := fmt.Sprintf("---@meta\n\n-- Klogproc Lua definitions for %s", appType)
+	if version != "" {
+		header += " " + version
+	}
+	if _, err := fmt.Fprintf(w, "%s\n-- (generated by `klogproc mkscript -defs`)\n\n", header); err != nil {
+		return fmt.Errorf("failed to generate Lua definitions: %w", err)
+	}
+	if err := g.write(w); err != nil {
+		return fmt.Errorf("failed to generate Lua definitions: %w", err)
+	}
+	if _, err := io.WriteString(w, builtins); err != nil {
+		return fmt.Errorf("failed to generate Lua definitions: %w", err)
+	}
+	if opts.WithParseLine {
+		if _, err := io.WriteString(w, parseLineDefs); err != nil {
+			return fmt.Errorf("failed to generate Lua definitions: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package luadefs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequest struct {
+	HTTPUserAgent string
+	RemoteAddr    string
+}
+
+type testCommon struct {
+	Level string
+}
+
+type testInput struct {
+	testCommon
+	Time    string
+	Args    map[string]string
+	Aligned []string
+	Request testRequest
+	Latency *float64
+	private int
+}
+
+type testOutput struct {
+	ID      string
+	IsQuery bool
+	Size    int64
+	Props   map[string]any
+	Items   []testRequest
+}
+
+func TestWrite(t *testing.T) {
+	var sb strings.Builder
+	err := Write(&sb, "kontext", "0.18", &testInput{}, &testOutput{}, Options{})
+	assert.NoError(t, err)
+	ans := sb.String()
+	assert.Contains(t, ans, "---@meta\n")
+	assert.Contains(t, ans, "-- Klogproc Lua definitions for kontext 0.18\n")
+	assert.Contains(t, ans, "---@class InputRecord\n---@field Level string\n---@field Time string\n")
+	assert.Contains(t, ans, "---@field Args table<string, string>\n")
+	assert.Contains(t, ans, "---@field Aligned string[]\n")
+	assert.Contains(t, ans, "---@field Request InputRecord.Request\n")
+	assert.Contains(t, ans, "---@field Latency number\n")
+	assert.NotContains(t, ans, "private")
+	assert.Contains(t, ans, "---@class InputRecord.Request\n---@field HTTPUserAgent string\n")
+	assert.Contains(t, ans, "---@class OutputRecord\n---@field ID string\n---@field IsQuery boolean\n---@field Size integer\n")
+	assert.Contains(t, ans, "---@field Props table<string, any>\n")
+	assert.Contains(t, ans, "---@field Items OutputRecord.Items[]\n")
+	assert.Contains(t, ans, "function transform_default(input_rec, tz_shift_mins) end")
+	assert.Contains(t, ans, "function set_out_prop(rec, name, value) end")
+	assert.Contains(t, ans, "---@field info fun(msg: string, args?: table<string, any>)")
+	assert.NotContains(t, ans, "parse_line")
+	assert.Less(t, strings.Index(ans, "---@class InputRecord.Request"), strings.Index(ans, "---@class OutputRecord\n"))
+}
+
+func TestWriteWithParseLine(t *testing.T) {
+	var sb strings.Builder
+	err := Write(&sb, "custom", "", &testInput{}, &testOutput{}, Options{WithParseLine: true})
+	assert.NoError(t, err)
+	assert.Contains(t, sb.String(), "-- Klogproc Lua definitions for custom\n")
+	assert.Contains(t, sb.String(), "function parse_line(line) end")
+}
+
+func TestWriteInvalidRecord(t *testing.T) {
+	var sb strings.Builder
+	err := Write(&sb, "custom", "", "foo", &testOutput{}, Options{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package luasandbox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	dumpFn = "dump"
+)
+
+// dumpValue writes a human readable representation of a Lua value.
+// Already visited tables are not expanded again so cyclic structures
+// are supported.
+func dumpValue(sb *strings.Builder, v lua.LValue, indent int, visited map[*lua.LTable]bool) {
+	switch tv := v.(type) {
+	case lua.LString:
+		sb.WriteString(fmt.Sprintf("%q", string(tv)))
+	case *lua.LTable:
+		if visited[tv] {
+			sb.WriteString("<cycle>")
+			return
+		}
+		visited[tv] = true
+		defer delete(visited, tv)
+		type item struct {
+			key   string
+			value lua.LValue
+		}
+		var items []item
+		tv.ForEach(func(key, value lua.LValue) {
+			var kb strings.Builder
+			dumpValue(&kb, key, 0, visited)
+			items = append(items, item{key: kb.String(), value: value})
+		})
+		if len(items) == 0 {
+			sb.WriteString("{}")
+			return
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].key < items[j].key })
+		pad := strings.Repeat("  ", indent+1)
+		sb.WriteString("{\n")
+		for _, it := range items {
+			sb.WriteString(pad + "[" + it.key + "] = ")
+			dumpValue(sb, it.value, indent+1, visited)
+			sb.WriteString(",\n")
+		}
+		sb.WriteString(strings.Repeat("  ", indent) + "}")
+	case *lua.LUserData:
+		sb.WriteString(fmt.Sprintf("userdata(%+v)", tv.Value))
+	default:
+		sb.WriteString(v.String())
+	}
+}
+
+// dump is a Lua function returning a human readable representation
+// of its argument (e.g. for debugging with `print(dump(value))`)
+func dump(L *lua.LState) int {
+	var sb strings.Builder
+	dumpValue(&sb, L.CheckAny(1), 0, make(map[*lua.LTable]bool))
+	L.Push(lua.LString(sb.String()))
+	return 1
+}
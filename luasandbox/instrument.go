@@ -0,0 +1,141 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package luasandbox
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	tickCall = "__klp_tick();"
+)
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// longBracketLevel returns a level of a long bracket (`[[`, `[=[`, ...)
+// starting at src[i] or -1 if there is no long bracket
+func longBracketLevel(src string, i int) int {
+	if i >= len(src) || src[i] != '[' {
+		return -1
+	}
+	j := i + 1
+	for j < len(src) && src[j] == '=' {
+		j++
+	}
+	if j < len(src) && src[j] == '[' {
+		return j - i - 1
+	}
+	return -1
+}
+
+// skipLongBracket returns a position right after a long bracket
+// string/comment starting at src[i]
+func skipLongBracket(src string, i, level int) (int, error) {
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(src[i+level+2:], closing)
+	if end < 0 {
+		return 0, fmt.Errorf("unfinished long string or comment")
+	}
+	return i + level + 2 + end + len(closing), nil
+}
+
+// instrument inserts calls of the `__klp_tick` function to the beginning
+// of each loop body and each function body. The source is not parsed
+// completely, only strings and comments are recognized so keywords are
+// not searched in them. Line numbers of the original source are preserved.
+func instrument(src string) (string, error) {
+	var ans strings.Builder
+	ans.Grow(len(src) + len(src)/10)
+	// awaitParams is true if we have seen the `function` keyword
+	// and we wait for the end of its parameter list
+	var awaitParams bool
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			end := strings.IndexByte(src[i:], '\n')
+			if level := longBracketLevel(src, i+2); level >= 0 {
+				var err error
+				end, err = skipLongBracket(src, i+2, level)
+				if err != nil {
+					return "", err
+				}
+				end -= i
+			}
+			if end < 0 {
+				end = len(src) - i
+			}
+			ans.WriteString(src[i : i+end])
+			i += end
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				if j < len(src) && src[j] == '\n' {
+					return "", fmt.Errorf("unfinished string")
+				}
+				j++
+			}
+			if j >= len(src) {
+				return "", fmt.Errorf("unfinished string")
+			}
+			ans.WriteString(src[i : j+1])
+			i = j + 1
+		case c == '[' && longBracketLevel(src, i) >= 0:
+			end, err := skipLongBracket(src, i, longBracketLevel(src, i))
+			if err != nil {
+				return "", err
+			}
+			ans.WriteString(src[i:end])
+			i = end
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			word := src[i:j]
+			ans.WriteString(word)
+			// a field access like `x.do` is not possible in Lua (keywords
+			// cannot be used as names) so we do not have to check context
+			switch word {
+			case "do", "repeat":
+				ans.WriteString(" " + tickCall)
+			case "function":
+				awaitParams = true
+			}
+			i = j
+		case c == ')' && awaitParams:
+			ans.WriteString(") " + tickCall)
+			awaitParams = false
+			i++
+		default:
+			ans.WriteByte(c)
+			i++
+		}
+	}
+	return ans.String(), nil
+}
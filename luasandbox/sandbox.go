@@ -0,0 +1,434 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package luasandbox limits resources available to Lua scripts:
+//
+//   - unsafe libraries and functions (`require`, `package`, `load*`, `dofile`,
+//     `io`, `os` etc.) are removed from a Lua state in Go before any script
+//     code runs,
+//   - each loop body and function body of a script starts with a call
+//     of a Go function counting instructions,
+//   - each call runs with a context cancelled once the time budget
+//     is exhausted,
+//   - the tick function also periodically counts items of tables reachable
+//     by the script and `string.rep` is limited in size of created strings,
+//   - states created by NewState have a bounded call stack and data stack.
+//
+// Violations are reported as errors wrapping ErrLimitExceeded.
+package luasandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	// ErrorMarker is a prefix of all the errors raised due to
+	// exceeded limits
+	ErrorMarker = "klogproc script limit exceeded"
+
+	DefaultMaxInstructions = 10000000
+	DefaultMaxCallTimeMs   = 1000
+	DefaultMaxTableItems   = 1000000
+	DefaultMaxStringLen    = 16 * 1024 * 1024
+
+	// tableCheckInterval specifies how often (in ticks) the size
+	// of reachable tables is checked
+	tableCheckInterval = 1000
+
+	// callStackSize and maxRegistrySize limit stacks of states created
+	// by NewState (the data stack starts at lua.RegistrySize)
+	callStackSize   = 256
+	maxRegistrySize = 64 * 1024
+
+	tickFn = "__klp_tick"
+
+	// localTick makes the tick function a local value of a script chunk.
+	// It does not contain a new line so line numbers are preserved.
+	localTick = "local " + tickFn + " = " + tickFn + "; "
+)
+
+var (
+	// ErrLimitExceeded is returned when a script exceeds some of its limits
+	ErrLimitExceeded = errors.New(ErrorMarker)
+
+	// safeOsFuncs are `os` functions available even if `os` is not allowed
+	safeOsFuncs = []string{"time", "date", "clock", "difftime"}
+
+	// overflowErrors are messages of gopher-lua errors raised
+	// once a Lua stack reaches its maximum size
+	overflowErrors = []string{"stack overflow", "registry overflow"}
+
+	// removedGlobals are never available to scripts
+	removedGlobals = []string{"require", "module", "package"}
+
+	// restrictedGlobals specifies global names for each library
+	// which is removed unless explicitly allowed
+	restrictedGlobals = map[string][]string{
+		"io":      {"io"},
+		"debug":   {"debug"},
+		"channel": {"channel"},
+		"load":    {"load", "loadstring", "dofile", "loadfile"},
+	}
+
+	// safeLibs are opened by NewState (`os` is restricted unless allowed)
+	safeLibs = []stdLib{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+		{lua.CoroutineLibName, lua.OpenCoroutine},
+		{lua.OsLibName, lua.OpenOs},
+	}
+
+	// optionalLibs are opened by NewState only if allowed
+	optionalLibs = []stdLib{
+		{lua.IoLibName, lua.OpenIo},
+		{lua.DebugLibName, lua.OpenDebug},
+		{lua.ChannelLibName, lua.OpenChannel},
+	}
+)
+
+type stdLib struct {
+	name string
+	open lua.LGFunction
+}
+
+// Limits configures resources available to a Lua script
+type Limits struct {
+	// MaxInstructions is an approximate per-call instruction budget.
+	// Only loop iterations and function calls are counted.
+	// Zero means a default value, negative value means no limit.
+	MaxInstructions int `json:"maxInstructions"`
+
+	// MaxCallTimeMs is a time budget for a single call of `preprocess`,
+	// `transform` or `parse_line`. Zero means a default value, negative
+	// value means no limit.
+	MaxCallTimeMs int `json:"maxCallTimeMs"`
+
+	// MaxTableItems limits the total number of items of all the tables
+	// reachable by a script (globals, locals and upvalues). The limit is
+	// checked periodically along with the instruction budget.
+	// Zero means a default value, negative value means no limit.
+	MaxTableItems int `json:"maxTableItems"`
+
+	// MaxStringLen limits a size (in bytes) of strings created
+	// by `string.rep`. Zero means a default value, negative value
+	// means no limit.
+	MaxStringLen int `json:"maxStringLen"`
+
+	// AllowedLibs lists libraries/functions disabled by default
+	// which should be available. Supported values are: os, io, debug,
+	// channel, load (load, loadstring, dofile, loadfile).
+	AllowedLibs []string `json:"allowedLibs"`
+}
+
+func (lim *Limits) isAllowed(lib string) bool {
+	for _, v := range lim.AllowedLibs {
+		if v == lib {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the configuration
+func (lim *Limits) Validate() error {
+	for _, v := range lim.AllowedLibs {
+		if _, ok := restrictedGlobals[v]; !ok && v != "os" {
+			return fmt.Errorf("invalid Lua library in allowedLibs: %s", v)
+		}
+	}
+	return nil
+}
+
+// WithDefaults returns a copy of the limits with zero values
+// replaced by defaults. A nil receiver is allowed.
+func (lim *Limits) WithDefaults() Limits {
+	var ans Limits
+	if lim != nil {
+		ans = *lim
+	}
+	if ans.MaxInstructions == 0 {
+		ans.MaxInstructions = DefaultMaxInstructions
+	}
+	if ans.MaxCallTimeMs == 0 {
+		ans.MaxCallTimeMs = DefaultMaxCallTimeMs
+	}
+	if ans.MaxTableItems == 0 {
+		ans.MaxTableItems = DefaultMaxTableItems
+	}
+	if ans.MaxStringLen == 0 {
+		ans.MaxStringLen = DefaultMaxStringLen
+	}
+	return ans
+}
+
+// Sandbox runs code of a single Lua state within configured limits.
+// Similarly to the state itself, it is not safe for concurrent use.
+type Sandbox struct {
+	L      *lua.LState
+	limits Limits
+	ticks  int
+}
+
+func (sb *Sandbox) tick(L *lua.LState) int {
+	sb.ticks++
+	if sb.limits.MaxInstructions > 0 && sb.ticks > sb.limits.MaxInstructions {
+		L.RaiseError("%s: instruction budget", ErrorMarker)
+	}
+	if sb.limits.MaxTableItems > 0 && sb.ticks%tableCheckInterval == 0 {
+		if n := countTableItems(L, sb.limits.MaxTableItems); n > sb.limits.MaxTableItems {
+			L.RaiseError("%s: table size (more than %d items)", ErrorMarker, sb.limits.MaxTableItems)
+		}
+	}
+	return 0
+}
+
+// countTableItems counts items of all the tables reachable from globals
+// and from locals and upvalues of running functions. Once the count
+// exceeds the limit, the counting stops.
+func countTableItems(L *lua.LState, limit int) int {
+	var count int
+	visited := make(map[lua.LValue]bool)
+	queue := []lua.LValue{L.G.Global}
+	push := func(v lua.LValue) {
+		switch v.(type) {
+		case *lua.LTable, *lua.LFunction:
+			if !visited[v] {
+				visited[v] = true
+				queue = append(queue, v)
+			}
+		}
+	}
+	for level := 0; ; level++ {
+		dbg, ok := L.GetStack(level)
+		if !ok {
+			break
+		}
+		if fn, err := L.GetInfo("f", dbg, lua.LNil); err == nil {
+			push(fn)
+		}
+		for i := 1; ; i++ {
+			name, v := L.GetLocal(dbg, i)
+			if name == "" {
+				break
+			}
+			push(v)
+		}
+	}
+	for len(queue) > 0 && count <= limit {
+		v := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		switch tv := v.(type) {
+		case *lua.LTable:
+			tv.ForEach(func(key, value lua.LValue) {
+				count++
+				push(key)
+				push(value)
+			})
+		case *lua.LFunction:
+			for i := 1; i <= len(tv.Upvalues); i++ {
+				_, uv := L.GetUpvalue(tv, i)
+				push(uv)
+			}
+		}
+	}
+	return count
+}
+
+// limitedStringRep wraps `string.rep` so it cannot create
+// strings larger than MaxStringLen
+func (sb *Sandbox) limitedStringRep(rep *lua.LFunction) *lua.LFunction {
+	return sb.L.NewFunction(func(L *lua.LState) int {
+		str := L.CheckString(1)
+		n := L.CheckInt(2)
+		if n > 0 && int64(len(str))*int64(n) > int64(sb.limits.MaxStringLen) {
+			L.RaiseError("%s: string size (more than %d bytes)", ErrorMarker, sb.limits.MaxStringLen)
+		}
+		return rep.GFunction(L)
+	})
+}
+
+// restrict removes unsafe libraries and functions both from globals
+// and from the table of loaded modules
+func (sb *Sandbox) restrict() {
+	loaded, _ := sb.L.GetField(sb.L.Get(lua.RegistryIndex), "_LOADED").(*lua.LTable)
+	remove := func(name string) {
+		sb.L.SetGlobal(name, lua.LNil)
+		if loaded != nil {
+			loaded.RawSetString(name, lua.LNil)
+		}
+	}
+	for _, name := range removedGlobals {
+		remove(name)
+	}
+	for lib, names := range restrictedGlobals {
+		if sb.limits.isAllowed(lib) {
+			continue
+		}
+		for _, name := range names {
+			remove(name)
+		}
+	}
+	if !sb.limits.isAllowed("os") {
+		safeOs := sb.L.NewTable()
+		if osTbl, ok := sb.L.GetGlobal("os").(*lua.LTable); ok {
+			for _, fn := range safeOsFuncs {
+				safeOs.RawSetString(fn, osTbl.RawGetString(fn))
+			}
+		}
+		sb.L.SetGlobal("os", safeOs)
+		if loaded != nil {
+			loaded.RawSetString("os", safeOs)
+		}
+	}
+	if sb.limits.MaxStringLen > 0 {
+		if strTbl, ok := sb.L.GetGlobal("string").(*lua.LTable); ok {
+			if rep, ok := strTbl.RawGetString("rep").(*lua.LFunction); ok && rep.IsG {
+				strTbl.RawSetString("rep", sb.limitedStringRep(rep))
+			}
+		}
+	}
+	// as `require` is not available, `dump` is provided as a global
+	sb.L.SetGlobal(dumpFn, sb.L.NewFunction(dump))
+	sb.L.SetGlobal(tickFn, sb.L.NewFunction(sb.tick))
+}
+
+func (sb *Sandbox) isLimitError(err error, ctx context.Context) bool {
+	return IsLimitError(err) || ctx != nil && ctx.Err() != nil
+}
+
+func (sb *Sandbox) limitError(err error, ctx context.Context) error {
+	if ctx != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w: time budget (%s)", ErrLimitExceeded, err)
+	}
+	return fmt.Errorf("%w: %s", ErrLimitExceeded, err)
+}
+
+// Call runs fn (which is expected to call some functions of the state)
+// with fresh budgets. Errors and panics caused by exceeded limits are
+// reported as errors wrapping ErrLimitExceeded. Other panics are propagated.
+func (sb *Sandbox) Call(fn func() error) (err error) {
+	sb.ticks = 0
+	var ctx context.Context
+	if sb.limits.MaxCallTimeMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(
+			context.Background(), time.Duration(sb.limits.MaxCallTimeMs)*time.Millisecond)
+		defer cancel()
+		sb.L.SetContext(ctx)
+		defer sb.L.RemoveContext()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rErr, ok := r.(error)
+			if !ok || !sb.isLimitError(rErr, ctx) {
+				panic(r)
+			}
+			err = sb.limitError(rErr, ctx)
+		}
+	}()
+	err = fn()
+	if err != nil && sb.isLimitError(err, ctx) {
+		err = sb.limitError(err, ctx)
+	}
+	return
+}
+
+// DoString instruments a script source and runs it within the limits.
+// The name is used in error messages.
+func (sb *Sandbox) DoString(src, name string) error {
+	code, err := instrument(src)
+	if err != nil {
+		return fmt.Errorf("failed to apply Lua script limits: %w", err)
+	}
+	fn, err := sb.L.Load(strings.NewReader(localTick+code), name)
+	if err != nil {
+		return err
+	}
+	return sb.Call(func() error {
+		sb.L.Push(fn)
+		return sb.L.PCall(0, lua.MultRet, nil)
+	})
+}
+
+// DoFile reads a script and runs it (see DoString)
+func (sb *Sandbox) DoFile(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read Lua script: %w", err)
+	}
+	return sb.DoString(string(src), path)
+}
+
+// Wrap restricts an existing Lua state (e.g. a state created by klogproc-core
+// with all the standard libraries opened). It must be called before any script
+// code is run in the state.
+func Wrap(L *lua.LState, limits Limits) *Sandbox {
+	ans := &Sandbox{L: L, limits: limits}
+	ans.restrict()
+	return ans
+}
+
+// NewState creates a Lua state with only the safe standard libraries
+// and the libraries allowed by the limits
+func NewState(limits Limits) *Sandbox {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   callStackSize,
+		RegistrySize:    lua.RegistrySize,
+		RegistryMaxSize: maxRegistrySize,
+	})
+	open := func(lib stdLib) {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, lib := range safeLibs {
+		open(lib)
+	}
+	for _, lib := range optionalLibs {
+		if limits.isAllowed(lib.name) {
+			open(lib)
+		}
+	}
+	return Wrap(L, limits)
+}
+
+// IsLimitError tests whether an error (possibly coming from the Lua
+// environment as a plain message) was caused by exceeded limits
+// (including overflows of the bounded Lua stacks)
+func IsLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrLimitExceeded) || strings.Contains(err.Error(), ErrorMarker) {
+		return true
+	}
+	for _, msg := range overflowErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
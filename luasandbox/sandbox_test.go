@@ -0,0 +1,240 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package luasandbox
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func callTransform(sb *Sandbox, arg lua.LValue) (lua.LValue, error) {
+	err := sb.Call(func() error {
+		return sb.L.CallByParam(
+			lua.P{Fn: sb.L.GetGlobal("transform"), NRet: 1, Protect: true}, arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ans := sb.L.Get(-1)
+	sb.L.Pop(1)
+	return ans, nil
+}
+
+func runTransform(t *testing.T, lim Limits, src string) (lua.LValue, error) {
+	sb := NewState(lim)
+	defer sb.L.Close()
+	if err := sb.DoString(src, "test.lua"); err != nil {
+		return nil, err
+	}
+	return callTransform(sb, lua.LNumber(3))
+}
+
+func TestInstrumentKeepsLines(t *testing.T) {
+	src := "local s = \"do not\" -- while x do\nfor i = 1, 3 do\n  x = [[repeat]]\nend\n"
+	ans, err := instrument(src)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Count(src, "\n"), strings.Count(ans, "\n"))
+	assert.Equal(t, 1, strings.Count(ans, tickCall))
+	assert.Contains(t, ans, "\"do not\"")
+}
+
+func TestInstrumentFunctions(t *testing.T) {
+	ans, err := instrument("function f(a, b) return a end\nlocal g = function() repeat until true end")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(ans, tickCall))
+}
+
+func TestRegularScript(t *testing.T) {
+	v, err := runTransform(
+		t,
+		(&Limits{}).WithDefaults(),
+		"function transform(x)\n  local s = 0\n  for i = 1, x do s = s + i end\n  return s\nend\n",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, lua.LNumber(6), v)
+}
+
+func TestInstructionBudget(t *testing.T) {
+	_, err := runTransform(
+		t,
+		Limits{MaxInstructions: 1000, MaxCallTimeMs: -1},
+		"function transform(x)\n  while true do end\nend\n",
+	)
+	assert.True(t, IsLimitError(err))
+}
+
+func TestTimeBudget(t *testing.T) {
+	_, err := runTransform(
+		t,
+		Limits{MaxInstructions: -1, MaxCallTimeMs: 50},
+		"function transform(x)\n  local i = 0\n  while true do i = i + 1 end\nend\n",
+	)
+	assert.True(t, IsLimitError(err))
+}
+
+func TestTimeBudgetWithoutTicks(t *testing.T) {
+	// a busy loop within a single function call is stopped
+	// by the context even without instrumentation
+	sb := NewState(Limits{MaxInstructions: -1, MaxCallTimeMs: 50})
+	defer sb.L.Close()
+	assert.NoError(t, sb.L.DoString("function transform(x) while true do end end"))
+	_, err := callTransform(sb, lua.LNumber(1))
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestBudgetIsPerCall(t *testing.T) {
+	sb := NewState(Limits{MaxInstructions: 20, MaxCallTimeMs: -1})
+	defer sb.L.Close()
+	assert.NoError(t, sb.DoString("function transform(x)\n  for i = 1, 10 do end\n  return x\nend\n", "test.lua"))
+	for i := 0; i < 5; i++ {
+		_, err := callTransform(sb, lua.LNumber(1))
+		assert.NoError(t, err)
+	}
+}
+
+func TestTopLevelCodeIsLimited(t *testing.T) {
+	sb := NewState(Limits{MaxInstructions: 1000, MaxCallTimeMs: -1})
+	defer sb.L.Close()
+	err := sb.DoString("while true do end\n", "test.lua")
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestTableSizeLimit(t *testing.T) {
+	for _, src := range []string{
+		// a global table
+		"function transform(x)\n  data = {}\n  while true do data[#data + 1] = x end\nend\n",
+		// a local table
+		"function transform(x)\n  local data = {}\n  while true do data[#data + 1] = x end\nend\n",
+		// a table reachable only via an upvalue
+		"local data = {}\nfunction transform(x)\n  while true do table.insert(data, x) end\nend\n",
+	} {
+		_, err := runTransform(t, Limits{MaxTableItems: 10000, MaxCallTimeMs: -1}, src)
+		assert.ErrorIs(t, err, ErrLimitExceeded, src)
+		assert.ErrorContains(t, err, "table size", src)
+	}
+}
+
+func TestTableSizeLimitAllowsRegularTables(t *testing.T) {
+	v, err := runTransform(
+		t,
+		Limits{MaxTableItems: 10000, MaxCallTimeMs: -1},
+		"function transform(x)\n  local data = {}\n  for i = 1, 5000 do data[i] = i end\n  return #data\nend\n",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, lua.LNumber(5000), v)
+}
+
+func TestStringRepLimit(t *testing.T) {
+	_, err := runTransform(
+		t,
+		Limits{MaxStringLen: 1024},
+		"function transform(x)\n  return string.rep('abc', 1000)\nend\n",
+	)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	v, err := runTransform(
+		t,
+		Limits{MaxStringLen: 1024},
+		"function transform(x)\n  return ('ab'):rep(x)\nend\n",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, lua.LString("ababab"), v)
+}
+
+func TestStackSizeLimit(t *testing.T) {
+	_, err := runTransform(
+		t,
+		Limits{MaxInstructions: -1, MaxCallTimeMs: -1},
+		"local function f(n) return f(n + 1) + 1 end\nfunction transform(x)\n  return f(x)\nend\n",
+	)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestOtherPanicsPropagate(t *testing.T) {
+	sb := NewState((&Limits{}).WithDefaults())
+	defer sb.L.Close()
+	assert.PanicsWithValue(t, "static transformer failed", func() {
+		sb.Call(func() error { panic("static transformer failed") })
+	})
+}
+
+func TestDisabledLibs(t *testing.T) {
+	for _, src := range []string{
+		"function transform(x)\n  return io.open('/etc/passwd')\nend\n",
+		"function transform(x)\n  return os.execute('true')\nend\n",
+		"function transform(x)\n  return require('io').open('/etc/passwd')\nend\n",
+		"function transform(x)\n  return package.loaded.os.execute('true')\nend\n",
+		"function transform(x)\n  return loadstring('return 1')()\nend\n",
+		"function transform(x)\n  return dofile('/etc/passwd')\nend\n",
+		"function transform(x)\n  return debug.getregistry()\nend\n",
+	} {
+		_, err := runTransform(t, (&Limits{}).WithDefaults(), src)
+		assert.Error(t, err, src)
+	}
+
+	v, err := runTransform(
+		t,
+		(&Limits{}).WithDefaults(),
+		"function transform(x)\n  return os.time() > 0\nend\n",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, lua.LTrue, v)
+}
+
+func TestWrappedStateIsRestricted(t *testing.T) {
+	// a state with all the standard libraries (as created by klogproc-core)
+	sb := Wrap(lua.NewState(), (&Limits{}).WithDefaults())
+	defer sb.L.Close()
+	for _, expr := range []string{
+		"require", "package", "io", "debug", "load", "loadstring", "dofile", "loadfile", "os.execute",
+	} {
+		assert.NoError(t, sb.DoString("function transform(x) return "+expr+" end", "test.lua"))
+		v, err := callTransform(sb, lua.LNil)
+		assert.NoError(t, err)
+		assert.Equal(t, lua.LNil, v, expr)
+	}
+	assert.Equal(t, lua.LNil, sb.L.GetField(sb.L.GetField(sb.L.Get(lua.RegistryIndex), "_LOADED"), "io"))
+}
+
+func TestAllowedLibs(t *testing.T) {
+	lim := Limits{AllowedLibs: []string{"os", "io"}}
+	v, err := runTransform(
+		t,
+		lim.WithDefaults(),
+		"function transform(x)\n  return os.getenv ~= nil and io.open ~= nil\nend\n",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, lua.LTrue, v)
+	assert.Error(t, (&Limits{AllowedLibs: []string{"foo"}}).Validate())
+}
+
+func TestDump(t *testing.T) {
+	v, err := runTransform(
+		t,
+		(&Limits{}).WithDefaults(),
+		"function transform(x)\n  local t = {x, b = {c = 'd'}}\n  t.self = t\n  return dump(t)\nend\n",
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		lua.LString("{\n  [\"b\"] = {\n    [\"c\"] = \"d\",\n  },\n  [\"self\"] = <cycle>,\n  [1] = 3,\n}"),
+		v,
+	)
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"klogproc/config"
+	"klogproc/esmapping"
+
+	"github.com/czcorpus/klogproc-core/save/elastic"
+)
+
+const (
+	indexPatternAppPlaceholder = "{app}"
+	defaultIndexPattern        = indexPatternAppPlaceholder + "*"
+)
+
+// generateIndexTemplates creates composable index templates for output
+// records of all the known applications (or just for appType/version
+// if specified). There is a single template per application type covering
+// all its versions as the versions share the same indices (and templates
+// with the same pattern and priority would be rejected as overlapping).
+// In index patterns, the "{app}" placeholder is replaced by an application
+// type. With outputDir set, each template is stored to a separate file
+// ([app].template.json), otherwise a single JSON object with all
+// the templates is printed to stdout.
+func generateIndexTemplates(appType, version, indexPattern, outputDir string) error {
+	if indexPattern == "" {
+		indexPattern = defaultIndexPattern
+	}
+	matching, err := filterAppVersions(appType, version)
+	if err != nil {
+		return fmt.Errorf("failed to generate index template: %w", err)
+	}
+	versions := make(map[string][]string)
+	outputRecs := make(map[string][]any)
+	for _, app := range matching {
+		versions[app.Type] = append(versions[app.Type], app.Version)
+		// versions are sorted in ascending order and we want field
+		// types of newer versions to win in case of a conflict
+		outputRecs[app.Type] = append([]any{app.NewOutputRecord()}, outputRecs[app.Type]...)
+	}
+	ans := make(map[string]*esmapping.IndexTemplate)
+	for appType, recs := range outputRecs {
+		ans[appType] = esmapping.NewIndexTemplate(
+			appType,
+			strings.ReplaceAll(indexPattern, indexPatternAppPlaceholder, appType),
+			versions[appType],
+			recs,
+		)
+	}
+	if outputDir == "" {
+		data, err := json.MarshalIndent(ans, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to generate index template: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to generate index template: %w", err)
+	}
+	for name, tpl := range ans {
+		data, err := json.MarshalIndent(tpl, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to generate index template: %w", err)
+		}
+		path := filepath.Join(outputDir, name+".template.json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write index template %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// runCheckMappingAction compares a mapping generated from an output record
+// with the mapping of the live index and prints the differences. It returns
+// false if there are conflicting field types.
+func runCheckMappingAction(
+	ctx context.Context, w io.Writer, conf *config.Main, appType, version string,
+) (bool, error) {
+	if !conf.ElasticSearch.IsConfigured() {
+		return false, fmt.Errorf("failed to check mapping: Elasticsearch not configured")
+	}
+	_, outputRec, err := getRecordTypes(appType, version)
+	if err != nil {
+		return false, fmt.Errorf("failed to check mapping: %w", err)
+	}
+	esclient := elastic.NewClient(&conf.ElasticSearch, appType)
+	live, err := esmapping.FetchMapping(ctx, conf.ElasticSearch.Server, esclient.Index())
+	if err != nil {
+		return false, err
+	}
+	conflicts := esmapping.Compare(esmapping.FromValue(outputRec), live)
+	fmt.Fprintf(w, "Mapping check of index %s (%s %s)\n\n", esclient.Index(), appType, version)
+	var numErrors int
+	for _, c := range conflicts {
+		if c.IsError() {
+			numErrors++
+			fmt.Fprintf(w, "\tCONFLICT %s\n", c)
+
+		} else {
+			fmt.Fprintf(w, "\t%s\n", c)
+		}
+	}
+	fmt.Fprintf(w, "\nconflicts: %d, other differences: %d\n", numErrors, len(conflicts)-numErrors)
+	return numErrors == 0, nil
+}
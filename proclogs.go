@@ -40,10 +40,18 @@ func applyLocation(rec storage.InputRecord, db *geoip2.Reader, outRec storage.Ou
 }
 
 type ProcessOptions struct {
-	worklogReset  bool
-	dryRun        bool
-	analysisOnly  bool
-	datetimeRange batch.DatetimeRange
-	scriptPath    string
-	appType       string
+	worklogReset      bool
+	dryRun            bool
+	analysisOnly      bool
+	datetimeRange     batch.DatetimeRange
+	scriptPath        string
+	appType           string
+	reportPath        string
+	analysisReportDir string
+	botPatternsPath   string
+	sampleRate        float64
+	sampleSeed        string
+	sampleKey         string
+	reindex           bool
+	maxRate           string
 }
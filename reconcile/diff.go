@@ -0,0 +1,177 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reconcile compares document IDs expected from source logs
+// with the ones actually stored in an Elasticsearch index.
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+const (
+	// DayFormat is a format of day keys used in reports
+	DayFormat = "2006-01-02"
+)
+
+// DayDiff contains comparison results for a single day
+type DayDiff struct {
+	Day        string   `json:"day"`
+	Expected   int      `json:"expected"`
+	Indexed    int      `json:"indexed"`
+	Missing    []string `json:"missing"`
+	Unexpected []string `json:"unexpected"`
+}
+
+// IsOK returns true if there are no missing and no unexpected documents
+func (dd *DayDiff) IsOK() bool {
+	return len(dd.Missing) == 0 && len(dd.Unexpected) == 0
+}
+
+// IDSet is a set of document IDs grouped by days
+type IDSet map[string]map[string]bool
+
+// Add inserts an ID to a day matching provided time
+func (s IDSet) Add(id string, t time.Time, loc *time.Location) {
+	day := t.In(loc).Format(DayFormat)
+	ids, ok := s[day]
+	if !ok {
+		ids = make(map[string]bool)
+		s[day] = ids
+	}
+	ids[id] = true
+}
+
+// AddToDay inserts an ID to a specified day (in the YYYY-MM-DD format)
+func (s IDSet) AddToDay(id string, day string) {
+	ids, ok := s[day]
+	if !ok {
+		ids = make(map[string]bool)
+		s[day] = ids
+	}
+	ids[id] = true
+}
+
+// Days returns sorted list of days with at least one ID
+func (s IDSet) Days() []string {
+	ans := make([]string, 0, len(s))
+	for k := range s {
+		ans = append(ans, k)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+// Compare creates per-day differences between expected and indexed IDs
+func Compare(expected, indexed IDSet) []DayDiff {
+	days := make(map[string]bool)
+	for d := range expected {
+		days[d] = true
+	}
+	for d := range indexed {
+		days[d] = true
+	}
+	sortedDays := make([]string, 0, len(days))
+	for d := range days {
+		sortedDays = append(sortedDays, d)
+	}
+	sort.Strings(sortedDays)
+
+	ans := make([]DayDiff, 0, len(sortedDays))
+	for _, day := range sortedDays {
+		item := DayDiff{
+			Day:        day,
+			Expected:   len(expected[day]),
+			Indexed:    len(indexed[day]),
+			Missing:    []string{},
+			Unexpected: []string{},
+		}
+		for id := range expected[day] {
+			if !indexed[day][id] {
+				item.Missing = append(item.Missing, id)
+			}
+		}
+		for id := range indexed[day] {
+			if !expected[day][id] {
+				item.Unexpected = append(item.Unexpected, id)
+			}
+		}
+		sort.Strings(item.Missing)
+		sort.Strings(item.Unexpected)
+		ans = append(ans, item)
+	}
+	return ans
+}
+
+// DayRange returns all the days (as local midnights) between from and to
+// (both inclusive)
+func DayRange(from, to time.Time, loc *time.Location) []time.Time {
+	from = from.In(loc)
+	to = to.In(loc)
+	curr := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	ans := make([]time.Time, 0, 32)
+	for !curr.After(to) {
+		ans = append(ans, curr)
+		curr = curr.AddDate(0, 0, 1)
+	}
+	return ans
+}
+
+// WriteJSON stores the comparison results to a file
+func WriteJSON(path string, diffs []DayDiff) error {
+	data, err := json.MarshalIndent(diffs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write reconciliation report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write reconciliation report: %w", err)
+	}
+	return nil
+}
+
+// PrintSummary writes a per-day overview of the comparison results
+// as a table
+func PrintSummary(w io.Writer, appType string, diffs []DayDiff) {
+	headerFmt := color.New(color.FgGreen).SprintfFunc()
+	columnFmt := color.New(color.FgHiMagenta).SprintfFunc()
+	tbl := table.New("Day", "Expected", "Indexed", "Missing", "Unexpected", "Status")
+	tbl.
+		WithWriter(w).
+		WithHeaderFormatter(headerFmt).
+		WithFirstColumnFormatter(columnFmt).
+		WithHeaderSeparatorRow('\u2550')
+	var numMissing, numUnexpected int
+	for _, d := range diffs {
+		status := "OK"
+		if !d.IsOK() {
+			status = "DIFF"
+		}
+		tbl.AddRow(d.Day, d.Expected, d.Indexed, len(d.Missing), len(d.Unexpected), status)
+		numMissing += len(d.Missing)
+		numUnexpected += len(d.Unexpected)
+	}
+	fmt.Fprintf(w, "\nReconciliation report for %s\n\n", appType)
+	tbl.Print()
+	fmt.Fprintf(w, "\nmissing: %d, unexpected: %d\n\n", numMissing, numUnexpected)
+}
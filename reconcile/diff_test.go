@@ -0,0 +1,56 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	loc := time.UTC
+	expected := make(IDSet)
+	expected.Add("a", time.Date(2026, 1, 1, 10, 0, 0, 0, loc), loc)
+	expected.Add("b", time.Date(2026, 1, 1, 11, 0, 0, 0, loc), loc)
+	expected.Add("c", time.Date(2026, 1, 2, 11, 0, 0, 0, loc), loc)
+	indexed := make(IDSet)
+	indexed.AddToDay("a", "2026-01-01")
+	indexed.AddToDay("x", "2026-01-01")
+	indexed.AddToDay("c", "2026-01-02")
+	indexed.AddToDay("y", "2026-01-03")
+
+	diffs := Compare(expected, indexed)
+	assert.Equal(t, 3, len(diffs))
+	assert.Equal(t, []string{"b"}, diffs[0].Missing)
+	assert.Equal(t, []string{"x"}, diffs[0].Unexpected)
+	assert.True(t, diffs[1].IsOK())
+	assert.Equal(t, "2026-01-03", diffs[2].Day)
+	assert.Equal(t, []string{"y"}, diffs[2].Unexpected)
+}
+
+func TestDayRange(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Prague")
+	days := DayRange(
+		time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC),
+		time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC),
+		loc,
+	)
+	assert.Equal(t, 2, len(days))
+	assert.Equal(t, "2026-01-02", days[0].Format(DayFormat))
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultScrollTTL  = "3m"
+	scrollChunkSize   = 5000
+	datetimeFieldName = "datetime"
+)
+
+type searchHit struct {
+	ID string `json:"_id"`
+}
+
+type searchResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []searchHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// IDFetcher loads IDs of documents stored in an Elasticsearch index
+type IDFetcher struct {
+	server      string
+	index       string
+	recordTypes []string
+	scrollTTL   string
+	client      *http.Client
+}
+
+func (f *IDFetcher) request(ctx context.Context, method, path string, body any) (*searchResponse, error) {
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, f.server+path, rd)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("elasticsearch responded with %s: %s", resp.Status, string(data))
+	}
+	var ans searchResponse
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &ans); err != nil {
+			return nil, err
+		}
+	}
+	return &ans, nil
+}
+
+// FetchIDs returns IDs of all the documents of the configured record types
+// with datetime within [from, to).
+func (f *IDFetcher) FetchIDs(ctx context.Context, from, to time.Time) ([]string, error) {
+	query := map[string]any{
+		"size":    scrollChunkSize,
+		"_source": false,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []any{
+					map[string]any{
+						"range": map[string]any{
+							datetimeFieldName: map[string]any{
+								"gte": from.Format(time.RFC3339),
+								"lt":  to.Format(time.RFC3339),
+							},
+						},
+					},
+					map[string]any{
+						"terms": map[string]any{"type": f.recordTypes},
+					},
+				},
+			},
+		},
+	}
+	resp, err := f.request(
+		ctx, http.MethodPost, fmt.Sprintf("/%s/_search?scroll=%s", f.index, f.scrollTTL), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch document IDs: %w", err)
+	}
+	ans := make([]string, 0, len(resp.Hits.Hits))
+	for len(resp.Hits.Hits) > 0 {
+		for _, hit := range resp.Hits.Hits {
+			ans = append(ans, hit.ID)
+		}
+		resp, err = f.request(
+			ctx,
+			http.MethodPost,
+			"/_search/scroll",
+			map[string]any{"scroll": f.scrollTTL, "scroll_id": resp.ScrollID},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch document IDs: %w", err)
+		}
+	}
+	if resp.ScrollID != "" {
+		// we don't care much about possible errors here as the scroll
+		// will expire anyway
+		f.request(
+			ctx, http.MethodDelete, "/_search/scroll", map[string]any{"scroll_id": resp.ScrollID})
+	}
+	return ans, nil
+}
+
+// NewIDFetcher creates a new IDFetcher for documents of provided record
+// types (the `type` property of output records which may differ from
+// the app type). An empty scrollTTL is replaced by a default value.
+func NewIDFetcher(server, index string, recordTypes []string, scrollTTL string) *IDFetcher {
+	if scrollTTL == "" {
+		scrollTTL = defaultScrollTTL
+	}
+	return &IDFetcher{
+		server:      strings.TrimRight(server, "/"),
+		index:       index,
+		recordTypes: recordTypes,
+		scrollTTL:   scrollTTL,
+		client:      &http.Client{Timeout: 60 * time.Second},
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchIDsFiltersRecordTypes(t *testing.T) {
+	var query map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logs/_search":
+			json.NewDecoder(r.Body).Decode(&query)
+			w.Write([]byte(`{"_scroll_id": "s1", "hits": {"hits": [{"_id": "a"}, {"_id": "b"}]}}`))
+		case "/_search/scroll":
+			w.Write([]byte(`{"_scroll_id": "s1", "hits": {"hits": []}}`))
+		}
+	}))
+	defer srv.Close()
+
+	fetcher := NewIDFetcher(srv.URL, "logs", []string{"ske", "vlo"}, "")
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids, err := fetcher.FetchIDs(context.Background(), from, from.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, ids)
+
+	filter := query["query"].(map[string]any)["bool"].(map[string]any)["filter"].([]any)
+	assert.Equal(
+		t,
+		map[string]any{"terms": map[string]any{"type": []any{"ske", "vlo"}}},
+		filter[1],
+	)
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"klogproc/config"
+	"klogproc/errstream"
+	"klogproc/load/batch"
+	"klogproc/reconcile"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/czcorpus/cnc-gokit/collections"
+	"github.com/czcorpus/klogproc-core/save/elastic"
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/oschwald/geoip2-golang"
+	"github.com/rs/zerolog/log"
+)
+
+// fetchIndexedIDs loads IDs of indexed documents for all the days
+// between `from` and `to`. The boundary days are trimmed to the
+// provided interval so records outside of the processed range
+// are not reported as unexpected.
+func fetchIndexedIDs(
+	ctx context.Context,
+	fetcher *reconcile.IDFetcher,
+	from, to time.Time,
+	loc *time.Location,
+) (reconcile.IDSet, error) {
+	ans := make(reconcile.IDSet)
+	for _, day := range reconcile.DayRange(from, to, loc) {
+		dayFrom := day
+		if dayFrom.Before(from) {
+			dayFrom = from
+		}
+		dayTo := day.AddDate(0, 0, 1)
+		if dayTo.After(to) {
+			dayTo = to.Add(time.Second)
+		}
+		ids, err := fetcher.FetchIDs(ctx, dayFrom, dayTo)
+		if err != nil {
+			return nil, err
+		}
+		dayKey := day.Format(reconcile.DayFormat)
+		for _, id := range ids {
+			ans.AddToDay(id, dayKey)
+		}
+		log.Debug().Str("day", dayKey).Int("numIds", len(ids)).Msg("fetched indexed IDs")
+	}
+	return ans, nil
+}
+
+// reindexMissing writes records with provided IDs to Elasticsearch
+// and returns number of failed writes
+func reindexMissing(
+	ctx context.Context,
+	conf *config.Main,
+	records map[string]*storage.BoundOutputRecord,
+	diffs []reconcile.DayDiff,
+) int {
+	channelWriteES := make(chan *storage.BoundOutputRecord, conf.ElasticSearch.PushChunkSize*2)
+	wch := elastic.RunWriteConsumer(ctx, conf.LogFiles.AppType, &conf.ElasticSearch, channelWriteES)
+	go func() {
+		defer close(channelWriteES)
+		for _, d := range diffs {
+			for _, id := range d.Missing {
+				if rec, ok := records[id]; ok {
+					channelWriteES <- rec
+				}
+			}
+		}
+	}()
+	var numFailed int
+	for confirm := range wch {
+		if confirm.Error != nil {
+			log.Error().Err(confirm.Error).Msg("failed to save data to ElasticSearch database")
+			numFailed++
+		}
+	}
+	return numFailed
+}
+
+func runReconcileAction(
+	conf *config.Main,
+	options *ProcessOptions,
+	geoDB *geoip2.Reader,
+) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var minTimestamp int64
+	if options.datetimeRange.From != nil {
+		minTimestamp = options.datetimeRange.From.Unix()
+	}
+	// the buffer state belongs to the batch/tail processing
+	// so it must not be affected by reconciliation
+	processor, err := newBatchProcessor(conf, geoDB, false, false, minTimestamp)
+	if errors.Is(err, batch.ErrNothingToProcess) {
+		log.Info().Err(err).Msg("reconcile action finished")
+		return
+
+	} else if err != nil {
+		log.Fatal().Err(err).Msg("failed to run reconcile action")
+		return
+	}
+	loc := conf.TimezoneLocation()
+	expected := make(reconcile.IDSet)
+	records := make(map[string]*storage.BoundOutputRecord)
+	// types of produced records (they may differ from the app type,
+	// e.g. for custom or mapping based apps)
+	recordTypes := collections.NewSet[string]()
+	var minTime, maxTime time.Time
+
+	channelOut := make(chan *storage.BoundOutputRecord, conf.ElasticSearch.PushChunkSize*2)
+	wait := make(chan any)
+	go func() {
+		for rec := range channelOut {
+			t := rec.Rec.GetTime()
+			expected.Add(rec.Rec.GetID(), t, loc)
+			recordTypes.Add(rec.Rec.GetType())
+			if options.reindex {
+				records[rec.Rec.GetID()] = rec
+			}
+			if minTime.IsZero() || t.Before(minTime) {
+				minTime = t
+			}
+			if t.After(maxTime) {
+				maxTime = t
+			}
+		}
+		wait <- struct{}{}
+	}()
+
+	report := batch.NewRunReport(conf.LogFiles.AppType, conf.LogFiles.Version)
+	proc := batch.CreateLogFileProcFunc(
+		ctx, processor, options.datetimeRange, report, errstream.NullSink{}, channelOut)
+	proc(conf.LogFiles, minTimestamp)
+	<-wait
+
+	if options.datetimeRange.From != nil {
+		minTime = *options.datetimeRange.From
+	}
+	if options.datetimeRange.To != nil {
+		maxTime = *options.datetimeRange.To
+	}
+	if minTime.IsZero() {
+		log.Warn().Msg("no records found in source logs and no time range specified - nothing to reconcile")
+		return
+	}
+	esclient := elastic.NewClient(&conf.ElasticSearch, conf.LogFiles.AppType)
+	fetcher := reconcile.NewIDFetcher(
+		conf.ElasticSearch.Server,
+		esclient.Index(),
+		recordTypes.ToOrderedSlice(),
+		conf.ElasticSearch.ScrollTTL,
+	)
+	indexed, err := fetchIndexedIDs(ctx, fetcher, minTime, maxTime, loc)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to run reconcile action")
+		return
+	}
+	diffs := reconcile.Compare(expected, indexed)
+	reconcile.PrintSummary(os.Stderr, conf.LogFiles.AppType, diffs)
+	if options.reportPath != "" {
+		if err := reconcile.WriteJSON(options.reportPath, diffs); err != nil {
+			log.Error().Err(err).Msg("failed to save reconciliation report")
+
+		} else {
+			log.Info().Str("path", options.reportPath).Msg("saved reconciliation report")
+		}
+	}
+	if options.reindex {
+		if options.dryRun {
+			log.Warn().Msg("using dry-run mode, missing records won't be re-indexed")
+			return
+		}
+		numFailed := reindexMissing(ctx, conf, records, diffs)
+		log.Info().Int("failed", numFailed).Msg("re-indexed missing records")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"klogproc/apps"
+	"klogproc/jsonschema"
+)
+
+// filterAppVersions returns known applications (with defined record types)
+// matching appType and version. Empty values match any application (version).
+func filterAppVersions(appType, version string) ([]apps.App, error) {
+	ans := make([]apps.App, 0, 5)
+	for _, app := range apps.All() {
+		if !app.HasRecordTypes() {
+			continue
+		}
+		if (appType == "" || app.Type == appType) && (version == "" || app.Version == version) {
+			ans = append(ans, app)
+		}
+	}
+	if len(ans) == 0 {
+		return nil, fmt.Errorf("unknown application %s %s", appType, version)
+	}
+	return ans, nil
+}
+
+// recordSchemas contains schemas of input and output records
+// of a single application
+type recordSchemas struct {
+	Input  *jsonschema.Schema `json:"input"`
+	Output *jsonschema.Schema `json:"output"`
+}
+
+func writeSchemaFile(path string, schema *jsonschema.Schema) error {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// generateSchemas creates JSON Schema documents for input and output records
+// of all the known applications (or just for appType/version if specified).
+// With outputDir set, each schema is stored to a separate file
+// ([app]-[version]-input.schema.json, [app]-[version]-output.schema.json),
+// otherwise a single JSON object with all the schemas is printed to stdout.
+func generateSchemas(appType, version, outputDir string) error {
+	matching, err := filterAppVersions(appType, version)
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON schema: %w", err)
+	}
+	ans := make(map[string]recordSchemas)
+	for _, app := range matching {
+		ans[app.String()] = recordSchemas{
+			Input:  jsonschema.FromValue(app.NewInputRecord(), fmt.Sprintf("%s input record", app)),
+			Output: jsonschema.FromValue(app.NewOutputRecord(), fmt.Sprintf("%s output record", app)),
+		}
+	}
+	if outputDir == "" {
+		data, err := json.MarshalIndent(ans, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to generate JSON schema: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to generate JSON schema: %w", err)
+	}
+	for name, schemas := range ans {
+		inPath := filepath.Join(outputDir, name+"-input.schema.json")
+		if err := writeSchemaFile(inPath, schemas.Input); err != nil {
+			return fmt.Errorf("failed to write JSON schema %s: %w", inPath, err)
+		}
+		outPath := filepath.Join(outputDir, name+"-output.schema.json")
+		if err := writeSchemaFile(outPath, schemas.Output); err != nil {
+			return fmt.Errorf("failed to write JSON schema %s: %w", outPath, err)
+		}
+	}
+	return nil
+}
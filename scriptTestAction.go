@@ -0,0 +1,209 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"klogproc/errstream"
+	"klogproc/load/alarm"
+	"klogproc/load/batch"
+	"klogproc/scripttest"
+	"klogproc/trfactory"
+	"os"
+
+	"github.com/czcorpus/klogproc-core/analysis"
+	"github.com/czcorpus/klogproc-core/logbuffer"
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+type scriptTestOptions struct {
+	fixturesDir    string
+	updateFixtures bool
+	diffOnly       bool
+	maxLines       int
+}
+
+type scriptTestSummary struct {
+	lines           int
+	skipped         int
+	scriptErrors    int
+	fixturesOK      int
+	fixturesDiff    int
+	fixturesMissing int
+	fixturesSaved   int
+}
+
+func newScriptTestBuffer() storage.ServiceLogBuffer {
+	return logbuffer.NewDummyStorage[storage.InputRecord, logbuffer.SerializableState](
+		func() logbuffer.SerializableState {
+			return &analysis.SimpleAnalysisState{}
+		},
+	)
+}
+
+func toJSONString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<failed to serialize: %s>", err)
+	}
+	return string(data)
+}
+
+// transformAll runs both preprocessing and transformation
+// of an input record
+func transformAll(
+	tr storage.LogItemTransformer,
+	rec storage.InputRecord,
+	buff storage.ServiceLogBuffer,
+) ([]storage.OutputRecord, error) {
+	prep, err := tr.Preprocess(rec, buff)
+	if err != nil {
+		return nil, fmt.Errorf("preprocess failed: %w", err)
+	}
+	ans := make([]storage.OutputRecord, 0, len(prep))
+	for _, precord := range prep {
+		buff.AddRecord(precord)
+		out, err := tr.Transform(precord)
+		if err != nil {
+			return nil, fmt.Errorf("transform failed: %w", err)
+		}
+		ans = append(ans, out)
+	}
+	return ans, nil
+}
+
+func runScriptTestAction(
+	w io.Writer,
+	appType, version, scriptPath, logPath string,
+	options scriptTestOptions,
+) (bool, error) {
+	conf := &batch.Conf{
+		AppType:    appType,
+		Version:    version,
+		ScriptPath: scriptPath,
+		SrcPath:    logPath,
+	}
+	lineParser, err := trfactory.NewLineParser(conf, &alarm.NullAlarm{}, errstream.NullSink{})
+	if err != nil {
+		return false, fmt.Errorf("failed to run script test: %w", err)
+	}
+	defaultTr, err := trfactory.GetStaticLogTransformer(conf, []int{}, false, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to run script test: %w", err)
+	}
+	scriptTr, err := trfactory.GetLogTransformer(conf, []int{}, false, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to run script test: %w", err)
+	}
+	var fixtures *scripttest.Fixtures
+	if options.fixturesDir != "" {
+		fixtures = scripttest.NewFixtures(options.fixturesDir)
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to run script test: %w", err)
+	}
+	defer f.Close()
+
+	defaultBuff := newScriptTestBuffer()
+	scriptBuff := newScriptTestBuffer()
+	var summary scriptTestSummary
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		if options.maxLines > 0 && lineNum > options.maxLines {
+			break
+		}
+		summary.lines++
+		rec, err := lineParser.ParseLine(sc.Text(), int64(lineNum))
+		if err != nil {
+			fmt.Fprintf(w, "--- line %d: parsing error: %s\n", lineNum, err)
+			summary.skipped++
+			continue
+		}
+		if !rec.IsProcessable() {
+			if !options.diffOnly {
+				fmt.Fprintf(w, "--- line %d: not processable\n", lineNum)
+			}
+			summary.skipped++
+			continue
+		}
+		defaultOut, err := transformAll(defaultTr, rec, defaultBuff)
+		if err != nil {
+			fmt.Fprintf(w, "--- line %d: default transformation error: %s\n", lineNum, err)
+		}
+		scriptOut, err := transformAll(scriptTr, rec, scriptBuff)
+		if err != nil {
+			fmt.Fprintf(w, "--- line %d: script error: %s\n", lineNum, err)
+			summary.scriptErrors++
+			continue
+		}
+		changes, err := scripttest.DiffValues(defaultOut, scriptOut)
+		if err != nil {
+			return false, fmt.Errorf("failed to run script test: %w", err)
+		}
+		if !options.diffOnly || len(changes) > 0 {
+			fmt.Fprintf(w, "--- line %d\n", lineNum)
+			fmt.Fprintf(w, "input:   %s\n", toJSONString(rec))
+			fmt.Fprintf(w, "default: %s\n", toJSONString(defaultOut))
+			fmt.Fprintln(w, "script (diff against default):")
+			scripttest.WriteChanges(w, changes, "    ")
+		}
+
+		if fixtures == nil {
+			continue
+		}
+		if options.updateFixtures {
+			if err := fixtures.Save(lineNum, scriptOut); err != nil {
+				return false, err
+			}
+			summary.fixturesSaved++
+			continue
+		}
+		fxChanges, found, err := fixtures.Check(lineNum, scriptOut)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			fmt.Fprintf(w, "!!! line %d: fixture not found\n", lineNum)
+			summary.fixturesMissing++
+			continue
+		}
+		if len(fxChanges) > 0 {
+			fmt.Fprintf(w, "!!! line %d: output does not match fixture:\n", lineNum)
+			scripttest.WriteChanges(w, fxChanges, "    ")
+			summary.fixturesDiff++
+
+		} else {
+			summary.fixturesOK++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return false, fmt.Errorf("failed to read log file: %w", err)
+	}
+	fmt.Fprintf(
+		w,
+		"\nlines: %d, skipped: %d, script errors: %d, fixtures OK: %d, fixtures failed: %d, fixtures missing: %d, fixtures saved: %d\n",
+		summary.lines, summary.skipped, summary.scriptErrors,
+		summary.fixturesOK, summary.fixturesDiff, summary.fixturesMissing, summary.fixturesSaved,
+	)
+	return summary.scriptErrors == 0 && summary.fixturesDiff == 0 && summary.fixturesMissing == 0, nil
+}
@@ -24,8 +24,6 @@ Error (table (struct))
     Anchor (string)
 ]]--
 
-dmp = require('dump')
-
 function preprocess (log_rec, buffer)
     return {log_rec}
 end
@@ -0,0 +1,170 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scripttest provides tools for testing Lua transformation
+// scripts - comparing JSON documents and handling fixtures with
+// expected outputs.
+package scripttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangeAdded   = "+"
+	ChangeRemoved = "-"
+	ChangeChanged = "~"
+)
+
+// Change describes a single difference between two JSON documents
+type Change struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+func (ch Change) String() string {
+	switch ch.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("%s %s: %s", ch.Kind, ch.Path, valueStr(ch.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("%s %s: %s", ch.Kind, ch.Path, valueStr(ch.Old))
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", ch.Kind, ch.Path, valueStr(ch.Old), valueStr(ch.New))
+	}
+}
+
+func valueStr(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func joinPath(base, key string) string {
+	if base == "" {
+		return key
+	}
+	return base + "." + key
+}
+
+func diffValues(path string, a, b any, ans []Change) []Change {
+	switch ta := a.(type) {
+	case map[string]any:
+		tb, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for k := range ta {
+			keys[k] = true
+		}
+		for k := range tb {
+			keys[k] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+		for _, k := range sortedKeys {
+			va, inA := ta[k]
+			vb, inB := tb[k]
+			if !inA {
+				ans = append(ans, Change{Kind: ChangeAdded, Path: joinPath(path, k), New: vb})
+
+			} else if !inB {
+				ans = append(ans, Change{Kind: ChangeRemoved, Path: joinPath(path, k), Old: va})
+
+			} else {
+				ans = diffValues(joinPath(path, k), va, vb, ans)
+			}
+		}
+		return ans
+	case []any:
+		tb, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(ta) || i < len(tb); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(ta) {
+				ans = append(ans, Change{Kind: ChangeAdded, Path: itemPath, New: tb[i]})
+
+			} else if i >= len(tb) {
+				ans = append(ans, Change{Kind: ChangeRemoved, Path: itemPath, Old: ta[i]})
+
+			} else {
+				ans = diffValues(itemPath, ta[i], tb[i], ans)
+			}
+		}
+		return ans
+	}
+	if !reflect.DeepEqual(a, b) {
+		ans = append(ans, Change{Kind: ChangeChanged, Path: path, Old: a, New: b})
+	}
+	return ans
+}
+
+// Diff compares two JSON documents and returns a list of changes needed
+// to get `b` from `a`. Paths use dot notation for object keys and
+// brackets for array indices.
+func Diff(a, b []byte) ([]Change, error) {
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		return nil, fmt.Errorf("failed to compare JSON documents: %w", err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return nil, fmt.Errorf("failed to compare JSON documents: %w", err)
+	}
+	return diffValues("", va, vb, []Change{}), nil
+}
+
+// DiffValues is like Diff but it accepts arbitrary values serializable
+// to JSON
+func DiffValues(a, b any) ([]Change, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare values: %w", err)
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare values: %w", err)
+	}
+	return Diff(ja, jb)
+}
+
+// WriteChanges writes changes (one per line) with a provided indentation
+func WriteChanges(w io.Writer, changes []Change, indent string) {
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "%s(no changes)\n", indent)
+		return
+	}
+	var buf strings.Builder
+	for _, ch := range changes {
+		buf.WriteString(indent)
+		buf.WriteString(ch.String())
+		buf.WriteString("\n")
+	}
+	io.WriteString(w, buf.String())
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scripttest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff(
+		[]byte(`{"a": 1, "b": {"c": "x", "d": [1, 2]}, "e": true}`),
+		[]byte(`{"a": 2, "b": {"c": "x", "d": [1]}, "f": null}`),
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"~ a: 1 -> 2",
+			"- b.d[1]: 2",
+			"- e: true",
+			"+ f: null",
+		},
+		func() []string {
+			ans := make([]string, len(changes))
+			for i, ch := range changes {
+				ans[i] = ch.String()
+			}
+			return ans
+		}(),
+	)
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	changes, err := DiffValues(map[string]any{"a": []int{1}}, map[string]any{"a": []int{1}})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestFixtures(t *testing.T) {
+	fx := NewFixtures(t.TempDir())
+	_, found, err := fx.Check(1, []any{map[string]any{"a": 1}})
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, fx.Save(1, []any{map[string]any{"a": 1}}))
+	changes, found, err := fx.Check(1, []any{map[string]any{"a": 3}})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "[0].a", changes[0].Path)
+}
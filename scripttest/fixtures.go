@@ -0,0 +1,83 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scripttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Fixtures is a directory with expected script outputs. Each processed
+// log line has its own file `line-<num>.json` containing a JSON array
+// of output records.
+type Fixtures struct {
+	dirPath string
+}
+
+func (f *Fixtures) path(lineNum int) string {
+	return filepath.Join(f.dirPath, fmt.Sprintf("line-%06d.json", lineNum))
+}
+
+// Load returns expected output for a line. If there is no fixture
+// for the line, ok is false.
+func (f *Fixtures) Load(lineNum int) (data []byte, ok bool, err error) {
+	data, err = os.ReadFile(f.path(lineNum))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load fixture: %w", err)
+	}
+	return data, true, nil
+}
+
+// Save stores an output for a line as a new expected value
+func (f *Fixtures) Save(lineNum int, output any) error {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save fixture: %w", err)
+	}
+	if err := os.MkdirAll(f.dirPath, 0755); err != nil {
+		return fmt.Errorf("failed to save fixture: %w", err)
+	}
+	if err := os.WriteFile(f.path(lineNum), data, 0644); err != nil {
+		return fmt.Errorf("failed to save fixture: %w", err)
+	}
+	return nil
+}
+
+// Check compares an output for a line with its fixture (if any)
+func (f *Fixtures) Check(lineNum int, output any) (changes []Change, found bool, err error) {
+	expected, found, err := f.Load(lineNum)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	actual, err := json.Marshal(output)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to check fixture: %w", err)
+	}
+	changes, err = Diff(expected, actual)
+	return changes, true, err
+}
+
+// NewFixtures creates a new Fixtures instance for a directory
+func NewFixtures(dirPath string) *Fixtures {
+	return &Fixtures{dirPath: dirPath}
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/czcorpus/klogproc-core/storage"
@@ -126,3 +127,11 @@ func (rec *InputRecord) IsProcessable() bool {
 func (rec *InputRecord) IsSuspicious() bool {
 	return false
 }
+
+// GetUserID returns an ID of the user (if known)
+func (rec *InputRecord) GetUserID() string {
+	if rec.UserID == nil {
+		return ""
+	}
+	return strconv.Itoa(*rec.UserID)
+}
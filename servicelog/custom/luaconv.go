@@ -0,0 +1,54 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	lua "github.com/yuin/gopher-lua"
+)
+
+// luaToGo converts a Lua value into a JSON-compatible Go value.
+// Tables with keys 1..n are converted to slices, other tables
+// to maps with string keys.
+func luaToGo(value lua.LValue) any {
+	switch v := value.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if n := v.Len(); n > 0 {
+			var numKeys int
+			v.ForEach(func(lua.LValue, lua.LValue) { numKeys++ })
+			if numKeys == n {
+				ans := make([]any, 0, n)
+				for i := 1; i <= n; i++ {
+					ans = append(ans, luaToGo(v.RawGetInt(i)))
+				}
+				return ans
+			}
+		}
+		ans := make(map[string]any)
+		v.ForEach(func(k, item lua.LValue) {
+			ans[k.String()] = luaToGo(item)
+		})
+		return ans
+	default:
+		return nil
+	}
+}
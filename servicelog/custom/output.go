@@ -0,0 +1,183 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// GeoData contains a geographical location of a client
+type GeoData struct {
+	IP          string     `json:"ip,omitempty"`
+	CountryName string     `json:"country_name,omitempty"`
+	Latitude    float32    `json:"latitude,omitempty"`
+	Longitude   float32    `json:"longitude,omitempty"`
+	Location    [2]float32 `json:"location,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+}
+
+// OutputRecord is a generic output record for the `custom` app type.
+// Besides a few common properties, it can contain any number of
+// additional properties (Props) which are exported as top-level
+// properties of the resulting JSON document.
+type OutputRecord struct {
+	ID          string  `json:"-"`
+	Type        string  `json:"type"`
+	Datetime    string  `json:"datetime"`
+	IPAddress   string  `json:"ipAddress,omitempty"`
+	UserAgent   string  `json:"userAgent,omitempty"`
+	UserID      string  `json:"userId,omitempty"`
+	IsAnonymous bool    `json:"isAnonymous"`
+	IsQuery     bool    `json:"isQuery"`
+	GeoIP       GeoData `json:"geoip,omitempty"`
+
+	// SamplingRate is set in the sampling mode (see SetSamplingRate)
+	SamplingRate float64        `json:"samplingRate,omitempty"`
+	Props        map[string]any `json:"-"`
+	time         time.Time
+}
+
+// SetTime sets both the internal time value and the exported
+// datetime string
+func (r *OutputRecord) SetTime(t time.Time) {
+	r.time = t
+	r.Datetime = t.Format(time.RFC3339)
+}
+
+// GetTime returns the record time
+func (r *OutputRecord) GetTime() time.Time {
+	if r.time.IsZero() && r.Datetime != "" {
+		t, err := time.Parse(time.RFC3339, r.Datetime)
+		if err == nil {
+			r.time = t
+		}
+	}
+	return r.time
+}
+
+func (r *OutputRecord) GetID() string {
+	return r.ID
+}
+
+func (r *OutputRecord) GetType() string {
+	return r.Type
+}
+
+// SetSamplingRate sets a rate of the sample the record belongs to
+// (see load/sampling)
+func (r *OutputRecord) SetSamplingRate(rate float64) {
+	r.SamplingRate = rate
+}
+
+// SetLocation sets geographical location of the client
+func (r *OutputRecord) SetLocation(countryName string, latitude float32, longitude float32, timezone string) {
+	r.GeoIP.IP = r.IPAddress
+	r.GeoIP.CountryName = countryName
+	r.GeoIP.Latitude = latitude
+	r.GeoIP.Longitude = longitude
+	r.GeoIP.Location[0] = longitude
+	r.GeoIP.Location[1] = latitude
+	r.GeoIP.Timezone = timezone
+}
+
+// ToJSON exports the record to JSON. Additional properties
+// are stored along with the common ones but they cannot
+// overwrite them.
+func (r *OutputRecord) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export custom output record: %w", err)
+	}
+	if len(r.Props) == 0 {
+		return data, nil
+	}
+	var tmp map[string]any
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return nil, fmt.Errorf("failed to export custom output record: %w", err)
+	}
+	for k, v := range r.Props {
+		if _, ok := tmp[k]; !ok {
+			tmp[k] = v
+		}
+	}
+	return json.Marshal(tmp)
+}
+
+// GenerateDeterministicID creates an ID based on the record values
+// so the same log line always produces the same ID
+func (r *OutputRecord) GenerateDeterministicID() string {
+	props, _ := json.Marshal(r.Props) // map keys are sorted by encoding/json
+	str := r.Type + r.Datetime + r.IPAddress + r.UserID + r.UserAgent + string(props)
+	sum := sha1.Sum([]byte(str))
+	return hex.EncodeToString(sum[:])
+}
+
+// LSetProperty sets a property from within a Lua script. Properties
+// not matching any common property are stored as additional ones.
+func (r *OutputRecord) LSetProperty(name string, value lua.LValue) error {
+	switch name {
+	case "ID", "Type", "Datetime", "IPAddress", "UserAgent", "UserID":
+		if value.Type() != lua.LTString {
+			return fmt.Errorf("failed to set property %s: expected string, got %s", name, value.Type())
+		}
+		switch name {
+		case "ID":
+			r.ID = value.String()
+		case "Type":
+			r.Type = value.String()
+		case "Datetime":
+			t, err := time.Parse(time.RFC3339, value.String())
+			if err != nil {
+				return fmt.Errorf("failed to set property %s: %w", name, err)
+			}
+			r.SetTime(t)
+		case "IPAddress":
+			r.IPAddress = value.String()
+		case "UserAgent":
+			r.UserAgent = value.String()
+		case "UserID":
+			r.UserID = value.String()
+		}
+	case "IsAnonymous", "IsQuery":
+		if value.Type() != lua.LTBool {
+			return fmt.Errorf("failed to set property %s: expected boolean, got %s", name, value.Type())
+		}
+		if name == "IsAnonymous" {
+			r.IsAnonymous = lua.LVAsBool(value)
+
+		} else {
+			r.IsQuery = lua.LVAsBool(value)
+		}
+	default:
+		if r.Props == nil {
+			r.Props = make(map[string]any)
+		}
+		if value == lua.LNil {
+			delete(r.Props, name)
+
+		} else {
+			r.Props[name] = luaToGo(value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestLSetProperty(t *testing.T) {
+	rec := &OutputRecord{}
+	L := lua.NewState()
+	defer L.Close()
+	tbl := L.NewTable()
+	tbl.RawSetString("corpus", lua.LString("syn2020"))
+	assert.NoError(t, rec.LSetProperty("Datetime", lua.LString("2026-03-01T10:11:12+01:00")))
+	assert.NoError(t, rec.LSetProperty("IsQuery", lua.LTrue))
+	assert.NoError(t, rec.LSetProperty("args", tbl))
+	assert.Error(t, rec.LSetProperty("UserID", lua.LNumber(10)))
+	assert.Equal(t, int64(1772356272), rec.GetTime().Unix())
+	assert.True(t, rec.IsQuery)
+	assert.Equal(t, map[string]any{"corpus": "syn2020"}, rec.Props["args"])
+}
+
+func TestToJSONKeepsCommonProps(t *testing.T) {
+	rec := &OutputRecord{
+		Type:  AppType,
+		Props: map[string]any{"type": "other", "path": "/search"},
+	}
+	rec.SetTime(time.Date(2026, 3, 1, 9, 11, 12, 0, time.UTC))
+	data, err := rec.ToJSON()
+	assert.NoError(t, err)
+	var tmp map[string]any
+	assert.NoError(t, json.Unmarshal(data, &tmp))
+	assert.Equal(t, AppType, tmp["type"])
+	assert.Equal(t, "/search", tmp["path"])
+	assert.Equal(t, "2026-03-01T09:11:12Z", tmp["datetime"])
+}
+
+func TestDeterministicID(t *testing.T) {
+	rec1 := &OutputRecord{Type: AppType, Props: map[string]any{"a": 1, "b": 2}}
+	rec2 := &OutputRecord{Type: AppType, Props: map[string]any{"b": 2, "a": 1}}
+	assert.Equal(t, rec1.GenerateDeterministicID(), rec2.GenerateDeterministicID())
+	rec2.Props["c"] = 3
+	assert.NotEqual(t, rec1.GenerateDeterministicID(), rec2.GenerateDeterministicID())
+}
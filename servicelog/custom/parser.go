@@ -0,0 +1,177 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"time"
+
+	"klogproc/lookup"
+	"klogproc/luasandbox"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	// AppType is a config code of applications fully defined
+	// by a Lua script
+	AppType = "custom"
+
+	parseLineFn = "parse_line"
+)
+
+// LineParser parses log lines using the `parse_line` function
+// of a Lua script. The function receives a raw line and returns
+// either a table with record properties or nil in case the line
+// should be ignored. Known keys are:
+//
+//   - time (string in ISO 8601 format or a number with UNIX time; required)
+//   - ip, user_agent, user_id (strings)
+//   - is_query, ignore, suspicious (booleans)
+//
+// All the other keys are stored in InputRecord.Fields.
+//
+// The parser is not safe for concurrent use.
+type LineParser struct {
+	lstate  *lua.LState
+	sandbox *luasandbox.Sandbox
+}
+
+var logLevels = map[string]zerolog.Level{
+	"debug": zerolog.DebugLevel,
+	"info":  zerolog.InfoLevel,
+	"warn":  zerolog.WarnLevel,
+	"error": zerolog.ErrorLevel,
+}
+
+// newLuaState creates a sandboxed Lua state with a minimal `log` module
+// so scripts can log from within `parse_line`
+func newLuaState(limits luasandbox.Limits) *luasandbox.Sandbox {
+	sandbox := luasandbox.NewState(limits)
+	L := sandbox.L
+	logTbl := L.NewTable()
+	for name, level := range logLevels {
+		L.SetField(logTbl, name, L.NewFunction(func(L *lua.LState) int {
+			log.WithLevel(level).Str("appType", AppType).Msg(L.CheckString(1))
+			return 0
+		}))
+	}
+	L.SetGlobal("log", logTbl)
+	return sandbox
+}
+
+func importTime(v lua.LValue) (string, error) {
+	switch tv := v.(type) {
+	case lua.LString:
+		return string(tv), nil
+	case lua.LNumber:
+		return time.Unix(int64(tv), 0).Format(time.RFC3339), nil
+	case *lua.LNilType:
+		return "", fmt.Errorf("missing time")
+	default:
+		return "", fmt.Errorf("invalid time type %s", v.Type())
+	}
+}
+
+func importRecord(tbl *lua.LTable) (*InputRecord, error) {
+	ans := &InputRecord{Fields: make(map[string]any)}
+	var err error
+	ans.Time, err = importTime(tbl.RawGetString("time"))
+	if err != nil {
+		return nil, err
+	}
+	if ans.GetTime().IsZero() {
+		return nil, fmt.Errorf("invalid time %s", ans.Time)
+	}
+	tbl.ForEach(func(k, v lua.LValue) {
+		switch k.String() {
+		case "time":
+		case "ip":
+			ans.IPAddress = lua.LVAsString(v)
+		case "user_agent":
+			ans.UserAgent = lua.LVAsString(v)
+		case "user_id":
+			ans.UserID = lua.LVAsString(v)
+		case "is_query":
+			ans.IsQuery = lua.LVAsBool(v)
+		case "ignore":
+			ans.Ignore = lua.LVAsBool(v)
+		case "suspicious":
+			ans.Suspicious = lua.LVAsBool(v)
+		default:
+			ans.Fields[k.String()] = luaToGo(v)
+		}
+	})
+	return ans, nil
+}
+
+// ParseLine parses a log line using the script's `parse_line` function
+func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
+	err := lp.sandbox.Call(func() error {
+		return lp.lstate.CallByParam(
+			lua.P{Fn: lp.lstate.GetGlobal(parseLineFn), NRet: 1, Protect: true},
+			lua.LString(s),
+		)
+	})
+	if err != nil {
+		if luasandbox.IsLimitError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to parse line %d: %w", lineNum, err)
+	}
+	ret := lp.lstate.Get(-1)
+	lp.lstate.Pop(1)
+	switch tret := ret.(type) {
+	case *lua.LNilType:
+		return nil, storage.NewLineParsingError(lineNum, "ignored by parse_line")
+	case *lua.LTable:
+		rec, err := importRecord(tret)
+		if err != nil {
+			return nil, storage.NewLineParsingError(lineNum, err.Error())
+		}
+		return rec, nil
+	default:
+		return nil, fmt.Errorf(
+			"failed to parse line %d: parse_line returned %s instead of a table", lineNum, ret.Type())
+	}
+}
+
+// Close releases the Lua state
+func (lp *LineParser) Close() {
+	lp.lstate.Close()
+}
+
+// NewLineParser is a factory for LineParser. The script is loaded
+// with the limits applied and with the lookup tables available
+// as `lookup.<name>` (lookups may be nil).
+func NewLineParser(scriptPath string, lookups *lookup.Registry, limits luasandbox.Limits) (*LineParser, error) {
+	sandbox := newLuaState(limits)
+	L := sandbox.L
+	lookups.Register(L)
+	if err := sandbox.DoFile(scriptPath); err != nil {
+		L.Close()
+		return nil, fmt.Errorf("failed to create custom line parser: %w", err)
+	}
+	if L.GetGlobal(parseLineFn).Type() != lua.LTFunction {
+		L.Close()
+		return nil, fmt.Errorf("failed to create custom line parser: function %s not found in %s", parseLineFn, scriptPath)
+	}
+	return &LineParser{lstate: L, sandbox: sandbox}, nil
+}
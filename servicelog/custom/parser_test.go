@@ -0,0 +1,131 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"klogproc/luasandbox"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const testScript = `
+function parse_line(line)
+    local ts, ip, path, status = string.match(line, "^(%S+) (%S+) (%S+) (%d+)")
+    if ts == nil then
+        return nil
+    end
+    return {
+        time = ts,
+        ip = ip,
+        is_query = path == "/search",
+        path = path,
+        status = tonumber(status),
+        tags = {"a", "b"}
+    }
+end
+`
+
+func createParser(t *testing.T, script string, limits luasandbox.Limits) *LineParser {
+	scriptPath := filepath.Join(t.TempDir(), "custom.lua")
+	assert.NoError(t, os.WriteFile(scriptPath, []byte(script), 0644))
+	p, err := NewLineParser(scriptPath, nil, limits)
+	assert.NoError(t, err)
+	return p
+}
+
+func TestParseLine(t *testing.T) {
+	p := createParser(t, testScript, (*luasandbox.Limits)(nil).WithDefaults())
+	defer p.Close()
+	rec, err := p.ParseLine("2026-03-01T10:11:12+01:00 192.168.1.10 /search 200", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-01T10:11:12+01:00", rec.Time)
+	assert.Equal(t, "192.168.1.10", rec.IPAddress)
+	assert.True(t, rec.IsQuery)
+	assert.True(t, rec.IsProcessable())
+	assert.Equal(t, "/search", rec.Fields["path"])
+	assert.Equal(t, float64(200), rec.Fields["status"])
+	assert.Equal(t, []any{"a", "b"}, rec.Fields["tags"])
+}
+
+func TestParseLineIgnored(t *testing.T) {
+	p := createParser(t, testScript, (*luasandbox.Limits)(nil).WithDefaults())
+	defer p.Close()
+	_, err := p.ParseLine("garbage", 7)
+	assert.Error(t, err)
+	_, ok := err.(storage.LineParsingError)
+	assert.True(t, ok)
+}
+
+func TestParseLineNumericTime(t *testing.T) {
+	p := createParser(
+		t,
+		"function parse_line(line) return {time = tonumber(line)} end",
+		(*luasandbox.Limits)(nil).WithDefaults(),
+	)
+	defer p.Close()
+	rec, err := p.ParseLine("1772356272", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1772356272), rec.GetTime().Unix())
+}
+
+func TestParseLineInvalidTime(t *testing.T) {
+	p := createParser(
+		t,
+		"function parse_line(line) return {time = line} end",
+		(*luasandbox.Limits)(nil).WithDefaults(),
+	)
+	defer p.Close()
+	_, err := p.ParseLine("yesterday", 3)
+	assert.ErrorAs(t, err, &storage.LineParsingError{})
+}
+
+func TestParseLineSandbox(t *testing.T) {
+	for _, expr := range []string{"require", "package", "io", "loadstring", "dofile", "os.execute"} {
+		p := createParser(
+			t,
+			"function parse_line(line) return {time = line, found = "+expr+" ~= nil} end",
+			(*luasandbox.Limits)(nil).WithDefaults(),
+		)
+		rec, err := p.ParseLine("2026-03-01T10:11:12+01:00", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, false, rec.Fields["found"], expr)
+		p.Close()
+	}
+}
+
+func TestParseLineLimits(t *testing.T) {
+	p := createParser(
+		t,
+		"function parse_line(line) while true do end end",
+		luasandbox.Limits{MaxInstructions: 1000, MaxCallTimeMs: -1},
+	)
+	defer p.Close()
+	_, err := p.ParseLine("x", 1)
+	assert.True(t, luasandbox.IsLimitError(err))
+}
+
+func TestMissingParseLine(t *testing.T) {
+	scriptPath := filepath.Join(t.TempDir(), "custom.lua")
+	assert.NoError(t, os.WriteFile(scriptPath, []byte("function transform(x) return x end"), 0644))
+	_, err := NewLineParser(scriptPath, nil, (*luasandbox.Limits)(nil).WithDefaults())
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extproc
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// AppType is a config code of applications parsed by
+	// an external process
+	AppType = "exec"
+
+	defaultTimeoutMs      = 5000
+	defaultRestartDelayMs = 1000
+	maxRestartDelay       = time.Minute
+)
+
+// Conf configures the `exec` app type
+type Conf struct {
+	// Command is the parser executable along with its arguments (required).
+	// The process reads requests from its stdin and writes responses
+	// to its stdout, one JSON object per line.
+	Command []string `json:"command"`
+
+	// Dir is a working directory of the process
+	Dir string `json:"dir"`
+
+	// Env contains additional environment variables of the process
+	Env map[string]string `json:"env"`
+
+	// TimeoutMs is a maximum time to wait for a response
+	// to a single line (default 5000)
+	TimeoutMs int `json:"timeoutMs"`
+
+	// RestartDelayMs is a delay before the process is restarted after
+	// it crashes or times out. The delay doubles with each consecutive
+	// failure, up to one minute (default 1000).
+	RestartDelayMs int `json:"restartDelayMs"`
+}
+
+// Timeout returns a maximum time to wait for a response
+func (conf *Conf) Timeout() time.Duration {
+	if conf.TimeoutMs == 0 {
+		return defaultTimeoutMs * time.Millisecond
+	}
+	return time.Duration(conf.TimeoutMs) * time.Millisecond
+}
+
+// RestartDelay returns a delay before the process is restarted
+// after numFailures consecutive failures
+func (conf *Conf) RestartDelay(numFailures int) time.Duration {
+	delay := time.Duration(conf.RestartDelayMs) * time.Millisecond
+	if conf.RestartDelayMs == 0 {
+		delay = defaultRestartDelayMs * time.Millisecond
+	}
+	for i := 1; i < numFailures && delay < maxRestartDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRestartDelay)
+}
+
+// Validate checks the configuration
+func (conf *Conf) Validate() error {
+	if len(conf.Command) == 0 || conf.Command[0] == "" {
+		return errors.New("invalid exec configuration: missing command")
+	}
+	if conf.TimeoutMs < 0 {
+		return errors.New("invalid exec configuration: timeoutMs must not be negative")
+	}
+	if conf.RestartDelayMs < 0 {
+		return errors.New("invalid exec configuration: restartDelayMs must not be negative")
+	}
+	return nil
+}
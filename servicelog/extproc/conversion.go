@@ -0,0 +1,74 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extproc
+
+import (
+	"klogproc/servicelog/custom"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// Transformer converts records returned by a parser process
+// into generic output records. All the fields of the input record
+// are copied to the output record.
+type Transformer struct {
+	AnonymousUsers []int
+}
+
+func (t *Transformer) AppType() string {
+	return AppType
+}
+
+func (t *Transformer) Transform(
+	logRecord storage.InputRecord,
+) (storage.OutputRecord, error) {
+	tLogRecord, ok := logRecord.(*InputRecord)
+	if !ok {
+		panic(storage.ErrFailedTypeAssertion)
+	}
+	userID := tLogRecord.GetNumericUserID()
+	rec := &custom.OutputRecord{
+		Type:        t.AppType(),
+		IPAddress:   tLogRecord.IPAddress,
+		UserAgent:   tLogRecord.UserAgent,
+		UserID:      tLogRecord.UserID,
+		IsAnonymous: userID == -1 || storage.UserBelongsToList(userID, t.AnonymousUsers),
+		IsQuery:     tLogRecord.IsQuery,
+		Props:       make(map[string]any, len(tLogRecord.Fields)),
+	}
+	for k, v := range tLogRecord.Fields {
+		rec.Props[k] = v
+	}
+	rec.SetTime(tLogRecord.GetTime())
+	rec.ID = rec.GenerateDeterministicID()
+	return rec, nil
+}
+
+func (t *Transformer) HistoryLookupItems() int {
+	return 0
+}
+
+func (t *Transformer) Preprocess(
+	rec storage.InputRecord, prevRecs storage.ServiceLogBuffer,
+) ([]storage.InputRecord, error) {
+	return []storage.InputRecord{rec}, nil
+}
+
+// NewTransformer is a factory for Transformer
+func NewTransformer(anonymousUsers []int) *Transformer {
+	return &Transformer{AnonymousUsers: anonymousUsers}
+}
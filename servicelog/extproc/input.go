@@ -0,0 +1,86 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extproc
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// InputRecord is a normalized record returned by a parser process.
+// All the application specific values are stored in Fields.
+type InputRecord struct {
+	Time        string         `json:"time"`
+	IPAddress   string         `json:"ip"`
+	UserAgent   string         `json:"userAgent"`
+	UserID      string         `json:"userId"`
+	Processable bool           `json:"processable"`
+	IsQuery     bool           `json:"isQuery"`
+	Suspicious  bool           `json:"suspicious"`
+	Fields      map[string]any `json:"fields"`
+	time        time.Time
+}
+
+// GetTime returns a normalized log date and time information
+func (r *InputRecord) GetTime() time.Time {
+	return r.time
+}
+
+func (r *InputRecord) GetClientIP() net.IP {
+	return net.ParseIP(r.IPAddress)
+}
+
+func (r *InputRecord) ClusteringClientID() string {
+	return storage.GenerateRandomClusteringID()
+}
+
+func (r *InputRecord) ClusterSize() int {
+	return 0
+}
+
+func (r *InputRecord) SetCluster(size int) {
+}
+
+func (r *InputRecord) GetUserAgent() string {
+	return r.UserAgent
+}
+
+func (r *InputRecord) IsProcessable() bool {
+	return r.Processable
+}
+
+func (r *InputRecord) IsSuspicious() bool {
+	return r.Suspicious
+}
+
+// GetNumericUserID returns the user ID as a number or -1
+// if the ID is not numeric
+func (r *InputRecord) GetNumericUserID() int {
+	ans, err := strconv.Atoi(r.UserID)
+	if err != nil {
+		return -1
+	}
+	return ans
+}
+
+// GetUserID returns an ID of the user (if known)
+func (r *InputRecord) GetUserID() string {
+	return r.UserID
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extproc
+
+import (
+	"encoding/json"
+	"fmt"
+	"klogproc/apps"
+	"sync"
+	"time"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// request is a single line sent to the parser process
+type request struct {
+	Line    string `json:"line"`
+	LineNum int64  `json:"lineNum"`
+}
+
+// response is a parsed line returned by the parser process.
+// Error means the line could not be parsed.
+type response struct {
+	InputRecord
+
+	// Processable shadows the embedded field so a missing
+	// value can be treated as `true`
+	Processable *bool  `json:"processable"`
+	Error       string `json:"error"`
+}
+
+// LineParser sends log lines to an external process and reads back
+// normalized records. The process is started lazily and it is restarted
+// (with an increasing delay) once it crashes or does not respond in time.
+// While the process is not available, ParseLine returns errors wrapping
+// apps.ErrParserUnavailable so callers can retry the line (batch mode)
+// or raise an alarm (tail mode).
+type LineParser struct {
+	conf *Conf
+	mu   sync.Mutex
+	proc *process
+
+	// numFailures is a number of consecutive failures of the process
+	numFailures int
+
+	// restartAt specifies the earliest time the process can be started again
+	restartAt time.Time
+}
+
+func (lp *LineParser) registerFailure(err error) {
+	lp.numFailures++
+	delay := lp.conf.RestartDelay(lp.numFailures)
+	lp.restartAt = time.Now().Add(delay)
+	log.Error().
+		Err(err).
+		Strs("command", lp.conf.Command).
+		Int("failures", lp.numFailures).
+		Dur("restartDelay", delay).
+		Msg("parser process failed")
+}
+
+func (lp *LineParser) ensureProcess() (*process, error) {
+	if lp.proc != nil && !lp.proc.exited() {
+		return lp.proc, nil
+	}
+	if lp.proc != nil {
+		lp.registerFailure(fmt.Errorf("parser process exited: %v", lp.proc.exitErr))
+		lp.proc = nil
+	}
+	if time.Now().Before(lp.restartAt) {
+		return nil, fmt.Errorf(
+			"%w: next start of the process at %s",
+			apps.ErrParserUnavailable, lp.restartAt.Format(time.RFC3339))
+	}
+	proc, err := startProcess(lp.conf)
+	if err != nil {
+		lp.registerFailure(err)
+		return nil, fmt.Errorf("%w: %w", apps.ErrParserUnavailable, err)
+	}
+	lp.proc = proc
+	return proc, nil
+}
+
+// ParseLine sends a line to the parser process and converts its response
+// into an input record. In case the process is not available or it fails
+// while handling the line, an error wrapping apps.ErrParserUnavailable
+// is returned.
+func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	proc, err := lp.ensureProcess()
+	if err != nil {
+		return nil, err
+	}
+	req, err := json.Marshal(request{Line: s, LineNum: lineNum})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode exec parser request: %w", err)
+	}
+	resp, err := proc.call(append(req, '\n'), lp.conf.Timeout())
+	if err != nil {
+		// after a timeout, a late response could be mismatched
+		// with the next request so the process must be replaced
+		proc.kill()
+		lp.proc = nil
+		lp.registerFailure(err)
+		return nil, fmt.Errorf("%w: %w", apps.ErrParserUnavailable, err)
+	}
+	lp.numFailures = 0
+	return parseResponse(resp, lineNum)
+}
+
+// Close stops the parser process
+func (lp *LineParser) Close() error {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	if lp.proc != nil {
+		lp.proc.stop()
+		lp.proc = nil
+	}
+	return nil
+}
+
+func parseResponse(data []byte, lineNum int64) (*InputRecord, error) {
+	var resp response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, storage.NewLineParsingError(
+			lineNum, fmt.Sprintf("invalid response of parser process: %s", err))
+	}
+	if resp.Error != "" {
+		return nil, storage.NewLineParsingError(lineNum, resp.Error)
+	}
+	rec := resp.InputRecord
+	rec.Processable = resp.Processable == nil || *resp.Processable
+	if rec.Time == "" {
+		if rec.Processable {
+			return nil, storage.NewLineParsingError(lineNum, "missing time in response of parser process")
+		}
+		return &rec, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, rec.Time)
+	if err != nil {
+		return nil, storage.NewLineParsingError(lineNum, fmt.Sprintf("invalid time: %s", err))
+	}
+	rec.time = t
+	return &rec, nil
+}
+
+// NewLineParser is a factory for LineParser. The process is started
+// along with parsing of the first line.
+func NewLineParser(conf *Conf) *LineParser {
+	return &LineParser{conf: conf}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extproc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"klogproc/apps"
+	"klogproc/servicelog/custom"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHelperProcess is not a real test - it is a parser process
+// started by other tests (see helperConf)
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("KLOGPROC_HELPER_PROCESS") != "1" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid request: %s\n", err)
+			os.Exit(1)
+		}
+		var resp map[string]any
+		switch req.Line {
+		case "crash":
+			os.Exit(1)
+		case "hang":
+			time.Sleep(time.Hour)
+		case "stall":
+			// respond but stop reading requests
+			fmt.Println(`{"processable": false}`)
+			time.Sleep(time.Hour)
+		case "bad":
+			resp = map[string]any{"error": "cannot parse"}
+		case "skip":
+			resp = map[string]any{"processable": false}
+		default:
+			items := strings.SplitN(req.Line, " ", 3)
+			resp = map[string]any{
+				"time":      items[0],
+				"ip":        items[1],
+				"userAgent": "test",
+				"userId":    "42",
+				"isQuery":   true,
+				"fields":    map[string]any{"text": items[2], "lineNum": req.LineNum},
+			}
+		}
+		data, _ := json.Marshal(resp)
+		fmt.Println(string(data))
+	}
+	os.Exit(0)
+}
+
+func helperConf() *Conf {
+	return &Conf{
+		Command:        []string{os.Args[0], "-test.run=TestHelperProcess"},
+		Env:            map[string]string{"KLOGPROC_HELPER_PROCESS": "1"},
+		TimeoutMs:      500,
+		RestartDelayMs: 10,
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	lp := NewLineParser(helperConf())
+	defer lp.Close()
+	rec, err := lp.ParseLine("2026-03-01T09:11:12.123Z 10.0.0.1 foo bar", 7)
+	assert.NoError(t, err)
+	assert.True(t, rec.IsProcessable())
+	assert.True(t, rec.IsQuery)
+	assert.Equal(t, "10.0.0.1", rec.GetClientIP().String())
+	assert.Equal(t, "test", rec.GetUserAgent())
+	assert.Equal(t, 42, rec.GetNumericUserID())
+	assert.Equal(t, "2026-03-01T09:11:12.123Z", rec.GetTime().Format("2006-01-02T15:04:05.000Z07:00"))
+	assert.Equal(t, "foo bar", rec.Fields["text"])
+	assert.Equal(t, float64(7), rec.Fields["lineNum"])
+
+	rec, err = lp.ParseLine("2026-03-01T09:11:13Z 10.0.0.2 baz", 8)
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", rec.Fields["text"])
+}
+
+func TestParseLineRejected(t *testing.T) {
+	lp := NewLineParser(helperConf())
+	defer lp.Close()
+	_, err := lp.ParseLine("bad", 1)
+	assert.ErrorAs(t, err, &storage.LineParsingError{})
+
+	rec, err := lp.ParseLine("skip", 2)
+	assert.NoError(t, err)
+	assert.False(t, rec.IsProcessable())
+
+	_, err = lp.ParseLine("invalid-time 10.0.0.1 foo", 3)
+	assert.ErrorAs(t, err, &storage.LineParsingError{})
+}
+
+func TestParseLineRestartAfterCrash(t *testing.T) {
+	lp := NewLineParser(helperConf())
+	defer lp.Close()
+	_, err := lp.ParseLine("crash", 1)
+	assert.ErrorIs(t, err, apps.ErrParserUnavailable)
+	assert.Equal(t, 1, lp.numFailures)
+
+	_, err = lp.ParseLine("2026-03-01T09:11:12Z 10.0.0.1 foo", 2)
+	assert.ErrorIs(t, err, apps.ErrParserUnavailable) // the restart delay has not passed yet
+
+	time.Sleep(20 * time.Millisecond)
+	rec, err := lp.ParseLine("2026-03-01T09:11:12Z 10.0.0.1 foo", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", rec.Fields["text"])
+	assert.Equal(t, 0, lp.numFailures)
+}
+
+func TestParseLineTimeout(t *testing.T) {
+	conf := helperConf()
+	conf.TimeoutMs = 100
+	lp := NewLineParser(conf)
+	defer lp.Close()
+	_, err := lp.ParseLine("hang", 1)
+	assert.ErrorIs(t, err, errTimeout)
+	assert.ErrorIs(t, err, apps.ErrParserUnavailable)
+
+	time.Sleep(20 * time.Millisecond)
+	rec, err := lp.ParseLine("2026-03-01T09:11:12Z 10.0.0.1 foo", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", rec.Fields["text"])
+}
+
+func TestParseLineWriteTimeout(t *testing.T) {
+	conf := helperConf()
+	conf.TimeoutMs = 100
+	lp := NewLineParser(conf)
+	defer lp.Close()
+	_, err := lp.ParseLine("stall", 1)
+	assert.NoError(t, err)
+
+	// the request is larger than the pipe buffer so writing it blocks
+	done := make(chan error)
+	go func() {
+		_, err := lp.ParseLine(strings.Repeat("x", 4*1024*1024), 2)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, errTimeout)
+		assert.ErrorIs(t, err, apps.ErrParserUnavailable)
+	case <-time.After(5 * time.Second):
+		t.Fatal("writing to a stalled parser process did not time out")
+	}
+}
+
+func TestParseLineMissingCommand(t *testing.T) {
+	lp := NewLineParser(&Conf{Command: []string{"/nonexistent/parser"}})
+	_, err := lp.ParseLine("foo", 1)
+	assert.ErrorIs(t, err, apps.ErrParserUnavailable)
+	assert.Equal(t, 1, lp.numFailures)
+}
+
+func TestRestartDelay(t *testing.T) {
+	conf := &Conf{RestartDelayMs: 500}
+	assert.Equal(t, 500*time.Millisecond, conf.RestartDelay(1))
+	assert.Equal(t, time.Second, conf.RestartDelay(2))
+	assert.Equal(t, 4*time.Second, conf.RestartDelay(4))
+	assert.Equal(t, time.Minute, conf.RestartDelay(20))
+	assert.Equal(t, time.Second, (&Conf{}).RestartDelay(1))
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, (&Conf{}).Validate())
+	assert.Error(t, (&Conf{Command: []string{"parser"}, TimeoutMs: -1}).Validate())
+	assert.NoError(t, (&Conf{Command: []string{"parser", "--json"}}).Validate())
+}
+
+func TestTransform(t *testing.T) {
+	lp := NewLineParser(helperConf())
+	defer lp.Close()
+	rec, err := lp.ParseLine("2026-03-01T09:11:12Z 10.0.0.1 foo", 1)
+	assert.NoError(t, err)
+	out, err := NewTransformer([]int{42}).Transform(rec)
+	assert.NoError(t, err)
+	tOut := out.(*custom.OutputRecord)
+	assert.Equal(t, AppType, tOut.Type)
+	assert.Equal(t, "foo", tOut.Props["text"])
+	assert.True(t, tOut.IsAnonymous)
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extproc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxResponseSize limits the length of a single response line
+	maxResponseSize = 16 * 1024 * 1024
+
+	// stopGracePeriod is a time the process has to exit once its stdin is closed
+	stopGracePeriod = 5 * time.Second
+)
+
+var (
+	errTimeout = errors.New("parser process did not respond in time")
+)
+
+// process is a running parser process. Requests are written to its stdin
+// and responses are read from its stdout by a separate goroutine.
+type process struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan []byte
+
+	// stopping is closed once the process is being stopped
+	// so no more responses are expected
+	stopping chan struct{}
+
+	// done is closed once the process exits
+	done chan struct{}
+
+	exitErr  error
+	stopOnce sync.Once
+}
+
+func (p *process) readStdout(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResponseSize)
+	for scanner.Scan() {
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		select {
+		case p.responses <- line:
+		case <-p.stopping:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Error().Err(err).Int("pid", p.cmd.Process.Pid).Msg("failed to read parser process output")
+	}
+}
+
+func (p *process) forwardStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		log.Warn().
+			Str("command", p.cmd.Path).
+			Int("pid", p.cmd.Process.Pid).
+			Msgf("parser process: %s", scanner.Text())
+	}
+}
+
+// call sends a request and waits for the respective response.
+// The timeout applies to both writing the request (a process not reading
+// its stdin would block the write) and reading the response. After any
+// error, the process should be killed (see kill) which also releases
+// a pending write.
+func (p *process) call(req []byte, timeout time.Duration) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	written := make(chan error, 1)
+	go func() {
+		_, err := p.stdin.Write(req)
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			return nil, fmt.Errorf("failed to write to parser process: %w", err)
+		}
+	case <-p.done:
+		return nil, fmt.Errorf("parser process exited unexpectedly: %v", p.exitErr)
+	case <-timer.C:
+		return nil, errTimeout
+	}
+	select {
+	case resp := <-p.responses:
+		return resp, nil
+	case <-p.done:
+		return nil, fmt.Errorf("parser process exited unexpectedly: %v", p.exitErr)
+	case <-timer.C:
+		return nil, errTimeout
+	}
+}
+
+func (p *process) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop closes stdin of the process and waits for it to exit.
+// If the process does not exit in time, it is killed.
+func (p *process) stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopping)
+		p.stdin.Close()
+		select {
+		case <-p.done:
+		case <-time.After(stopGracePeriod):
+			log.Warn().Int("pid", p.cmd.Process.Pid).Msg("parser process did not exit in time, killing")
+			p.cmd.Process.Kill()
+		}
+	})
+}
+
+// kill terminates the process immediately (e.g. in case
+// its state is unknown after a timeout)
+func (p *process) kill() {
+	p.stopOnce.Do(func() {
+		close(p.stopping)
+		p.stdin.Close()
+		p.cmd.Process.Kill()
+	})
+}
+
+func startProcess(conf *Conf) (*process, error) {
+	cmd := exec.Command(conf.Command[0], conf.Command[1:]...)
+	cmd.Dir = conf.Dir
+	cmd.Env = os.Environ()
+	for k, v := range conf.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start parser process: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start parser process: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start parser process: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start parser process: %w", err)
+	}
+	p := &process{
+		cmd:       cmd,
+		stdin:     stdin,
+		responses: make(chan []byte),
+		stopping:  make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go func() {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			p.forwardStderr(stderr)
+			wg.Done()
+		}()
+		p.readStdout(stdout)
+		wg.Wait()
+		// Wait must be called only after both pipes are read
+		p.exitErr = cmd.Wait()
+		close(p.done)
+		log.Info().Err(p.exitErr).Int("pid", cmd.Process.Pid).Msg("parser process exited")
+	}()
+	log.Info().Strs("command", conf.Command).Int("pid", cmd.Process.Pid).Msg("started parser process")
+	return p, nil
+}
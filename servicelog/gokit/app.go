@@ -0,0 +1,64 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gokit
+
+import (
+	"fmt"
+	"klogproc/apps"
+	"klogproc/servicelog/custom"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// ConfProvider is implemented by log configurations
+// supporting the `gokit` app type
+type ConfProvider interface {
+	GetGokit() *Conf
+}
+
+func getConf(env apps.Env) (*Conf, error) {
+	if cp, ok := env.LogConf().(ConfProvider); ok && cp.GetGokit() != nil {
+		return cp.GetGokit(), nil
+	}
+	return nil, fmt.Errorf("no gokit configuration")
+}
+
+func init() {
+	apps.Register(apps.App{
+		Type: AppType,
+		NewParser: func(env apps.Env) (storage.LineParser, error) {
+			conf, err := getConf(env)
+			if err != nil {
+				return nil, fmt.Errorf("cannot create parser for %s: %w", AppType, err)
+			}
+			lp, err := NewLineParser(conf)
+			if err != nil {
+				return nil, err
+			}
+			return apps.WrapParser(lp), nil
+		},
+		NewTransformer: func(env apps.Env) (storage.LogItemTransformer, error) {
+			conf, err := getConf(env)
+			if err != nil {
+				return nil, fmt.Errorf("cannot create transformer for %s: %w", AppType, err)
+			}
+			return NewTransformer(conf), nil
+		},
+		NewInputRecord:  func() storage.InputRecord { return &InputRecord{} },
+		NewOutputRecord: func() storage.OutputRecord { return &custom.OutputRecord{} },
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gokit
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"klogproc/servicelog/mapping"
+)
+
+const (
+	// AppType is a config code of services logging via
+	// the cnc-gokit (gin) logging middleware
+	AppType = "gokit"
+)
+
+// ActionRule derives an action from a request path. Pattern is
+// a regular expression matched against the path, Action may refer
+// to the pattern groups (e.g. `$1`). If Action is empty, the first
+// group (or the whole match if there are no groups) is used.
+type ActionRule struct {
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+}
+
+// QueryRule specifies which requests are queries. A request is a query
+// if it was successful (2xx) and both its action and HTTP method
+// match (empty lists match any value). If both lists are empty,
+// no request is a query.
+type QueryRule struct {
+	Actions []string `json:"actions"`
+	Methods []string `json:"methods"`
+}
+
+// Conf configures the `gokit` app type
+type Conf struct {
+	// Service is the `type` property of output records (required)
+	Service string `json:"service"`
+
+	// Actions are tested in the order of definition, the first matching
+	// one is applied. If none matches, the first element of the path
+	// is used as the action.
+	Actions []ActionRule `json:"actions"`
+
+	IsQuery QueryRule `json:"isQuery"`
+
+	// Fields are additional properties copied from the input record
+	// (see the `mapping` app type)
+	Fields []mapping.FieldConf `json:"fields"`
+}
+
+// Validate checks the configuration
+func (conf *Conf) Validate() error {
+	if conf.Service == "" {
+		return errors.New("invalid gokit configuration: missing service")
+	}
+	if _, err := compileActionRules(conf.Actions); err != nil {
+		return fmt.Errorf("invalid gokit configuration: %w", err)
+	}
+	for _, fc := range conf.Fields {
+		if err := fc.Validate(); err != nil {
+			return fmt.Errorf("invalid gokit configuration of fields: %w", err)
+		}
+	}
+	return nil
+}
+
+type compiledActionRule struct {
+	pattern *regexp.Regexp
+	action  string
+}
+
+func (car compiledActionRule) apply(path string) (string, bool) {
+	m := car.pattern.FindStringSubmatchIndex(path)
+	if m == nil {
+		return "", false
+	}
+	if car.action != "" {
+		return string(car.pattern.ExpandString(nil, car.action, path, m)), true
+	}
+	if len(m) > 2 && m[2] >= 0 {
+		return path[m[2]:m[3]], true
+	}
+	return path[m[0]:m[1]], true
+}
+
+func compileActionRules(rules []ActionRule) ([]compiledActionRule, error) {
+	ans := make([]compiledActionRule, len(rules))
+	for i, r := range rules {
+		if r.Pattern == "" {
+			return nil, fmt.Errorf("missing pattern of action rule %d", i)
+		}
+		rx, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of action rule %d: %w", i, err)
+		}
+		ans[i] = compiledActionRule{pattern: rx, action: r.Action}
+	}
+	return ans, nil
+}
+
+func contains(items []string, v string) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Match tests whether a request is a query
+func (qr QueryRule) Match(action, method string, status int) bool {
+	if len(qr.Actions) == 0 && len(qr.Methods) == 0 {
+		return false
+	}
+	if status < 200 || status >= 300 {
+		return false
+	}
+	return (len(qr.Actions) == 0 || contains(qr.Actions, action)) &&
+		(len(qr.Methods) == 0 || contains(qr.Methods, method))
+}
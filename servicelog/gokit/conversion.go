@@ -0,0 +1,93 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gokit
+
+import (
+	"fmt"
+
+	"klogproc/servicelog/custom"
+	"klogproc/servicelog/mapping"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// Transformer creates generic output records from records
+// of services using the cnc-gokit logging middleware
+type Transformer struct {
+	conf *Conf
+}
+
+func (t *Transformer) AppType() string {
+	return AppType
+}
+
+func (t *Transformer) Transform(
+	logRecord storage.InputRecord,
+) (storage.OutputRecord, error) {
+	tLogRecord, ok := logRecord.(*InputRecord)
+	if !ok {
+		panic(storage.ErrFailedTypeAssertion)
+	}
+	rec := &custom.OutputRecord{
+		Type:        t.conf.Service,
+		IPAddress:   tLogRecord.ClientIP,
+		UserAgent:   tLogRecord.UserAgentValue,
+		IsAnonymous: true, // the middleware does not log users
+		IsQuery:     t.conf.IsQuery.Match(tLogRecord.Action, tLogRecord.Method, tLogRecord.Status),
+		Props: map[string]any{
+			"level":    tLogRecord.Level,
+			"action":   tLogRecord.Action,
+			"path":     tLogRecord.Path,
+			"method":   tLogRecord.Method,
+			"status":   tLogRecord.Status,
+			"procTime": tLogRecord.Latency,
+			"bodySize": tLogRecord.BodySize,
+		},
+	}
+	if tLogRecord.ErrorMessage != "" {
+		rec.Props["error"] = tLogRecord.ErrorMessage
+	}
+	for _, fc := range t.conf.Fields {
+		v, ok := mapping.Lookup(tLogRecord.Data, fc.Path)
+		if !ok {
+			continue
+		}
+		cv, err := mapping.ConvertValue(v, fc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform %s record: %w", t.conf.Service, err)
+		}
+		rec.Props[fc.OutName()] = cv
+	}
+	rec.SetTime(tLogRecord.GetTime())
+	rec.ID = rec.GenerateDeterministicID()
+	return rec, nil
+}
+
+func (t *Transformer) HistoryLookupItems() int {
+	return 0
+}
+
+func (t *Transformer) Preprocess(
+	rec storage.InputRecord, prevRecs storage.ServiceLogBuffer,
+) ([]storage.InputRecord, error) {
+	return []storage.InputRecord{rec}, nil
+}
+
+// NewTransformer is a factory for Transformer
+func NewTransformer(conf *Conf) *Transformer {
+	return &Transformer{conf: conf}
+}
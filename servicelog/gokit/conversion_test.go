@@ -0,0 +1,64 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gokit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"klogproc/servicelog/mapping"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	conf := &Conf{
+		Service: "mquery",
+		Actions: []ActionRule{{Pattern: `^/corpus/[^/]+/(\w+)$`}},
+		IsQuery: QueryRule{Actions: []string{"concordance"}, Methods: []string{"GET"}},
+		Fields:  []mapping.FieldConf{{Path: "/args/q", Name: "query"}},
+	}
+	assert.NoError(t, conf.Validate())
+	p, err := NewLineParser(conf)
+	assert.NoError(t, err)
+	rec, err := p.ParseLine(testLine, 1)
+	assert.NoError(t, err)
+
+	out, err := NewTransformer(conf).Transform(rec)
+	assert.NoError(t, err)
+	data, err := out.ToJSON()
+	assert.NoError(t, err)
+	var tmp map[string]any
+	assert.NoError(t, json.Unmarshal(data, &tmp))
+	assert.Equal(t, "mquery", tmp["type"])
+	assert.Equal(t, "10.0.0.1", tmp["ipAddress"])
+	assert.Equal(t, true, tmp["isQuery"])
+	assert.Equal(t, "concordance", tmp["action"])
+	assert.Equal(t, 0.25, tmp["procTime"])
+	assert.Equal(t, float64(200), tmp["status"])
+	assert.Equal(t, `[word="test"]`, tmp["query"])
+	assert.NotEmpty(t, out.GetID())
+}
+
+func TestQueryRule(t *testing.T) {
+	qr := QueryRule{Actions: []string{"search"}}
+	assert.True(t, qr.Match("search", "POST", 200))
+	assert.False(t, qr.Match("search", "POST", 500))
+	assert.False(t, qr.Match("other", "POST", 200))
+	assert.False(t, QueryRule{}.Match("search", "GET", 200))
+	assert.True(t, QueryRule{Methods: []string{"POST"}}.Match("x", "POST", 201))
+}
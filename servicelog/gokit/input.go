@@ -0,0 +1,85 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gokit
+
+import (
+	"net"
+	"time"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// InputRecord represents a log record written by the cnc-gokit
+// logging middleware. Data contains the whole decoded record
+// (including service-specific values).
+type InputRecord struct {
+	Level          string         `json:"level"`
+	Time           string         `json:"time"`
+	Latency        float64        `json:"latency"`
+	ClientIP       string         `json:"clientIP"`
+	Method         string         `json:"method"`
+	Status         int            `json:"status"`
+	ErrorMessage   string         `json:"errorMessage"`
+	BodySize       int            `json:"bodySize"`
+	Path           string         `json:"path"`
+	UserAgentValue string         `json:"userAgent"`
+	Action         string         `json:"-"`
+	Data           map[string]any `json:"-"`
+	time           time.Time
+}
+
+// GetTime returns a normalized log date and time information
+func (r *InputRecord) GetTime() time.Time {
+	return r.time
+}
+
+func (r *InputRecord) GetClientIP() net.IP {
+	return net.ParseIP(r.ClientIP)
+}
+
+func (r *InputRecord) ClusteringClientID() string {
+	return storage.GenerateRandomClusteringID()
+}
+
+func (r *InputRecord) ClusterSize() int {
+	return 0
+}
+
+func (r *InputRecord) SetCluster(size int) {
+}
+
+func (r *InputRecord) GetUserAgent() string {
+	return r.UserAgentValue
+}
+
+func (r *InputRecord) IsProcessable() bool {
+	// process only http requests
+	return len(r.Method) > 0
+}
+
+func (r *InputRecord) IsSuspicious() bool {
+	return false
+}
+
+func (r *InputRecord) ExportError() *storage.ErrorRecord {
+	if r.ErrorMessage != "" {
+		return &storage.ErrorRecord{
+			Name: r.ErrorMessage,
+		}
+	}
+	return nil
+}
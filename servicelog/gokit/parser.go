@@ -0,0 +1,72 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gokit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// LineParser parses JSON log lines written by the cnc-gokit
+// logging middleware
+type LineParser struct {
+	actions []compiledActionRule
+}
+
+func (lp *LineParser) getAction(path string) string {
+	for _, rule := range lp.actions {
+		if action, ok := rule.apply(path); ok {
+			return action
+		}
+	}
+	split := strings.Split(path, "/")
+	if len(split) >= 2 {
+		return split[1]
+	}
+	return ""
+}
+
+// ParseLine parses a JSON log line
+func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
+	var record InputRecord
+	if err := json.Unmarshal([]byte(s), &record); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(s), &record.Data); err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, record.Time)
+	if err != nil {
+		return nil, storage.NewLineParsingError(lineNum, fmt.Sprintf("invalid time: %s", err))
+	}
+	record.time = t
+	record.Action = lp.getAction(record.Path)
+	return &record, nil
+}
+
+// NewLineParser is a factory for LineParser
+func NewLineParser(conf *Conf) (*LineParser, error) {
+	actions, err := compileActionRules(conf.Actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gokit parser: %w", err)
+	}
+	return &LineParser{actions: actions}, nil
+}
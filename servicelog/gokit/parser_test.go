@@ -0,0 +1,70 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gokit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testLine = `{"level":"info","time":"2026-03-01T09:11:12.123Z","latency":0.25,"clientIP":"10.0.0.1",` +
+	`"method":"GET","status":200,"bodySize":1024,"path":"/corpus/syn2020/concordance",` +
+	`"userAgent":"curl/8.0","args":{"q":"[word=\"test\"]"}}`
+
+func TestParseLine(t *testing.T) {
+	p, err := NewLineParser(&Conf{Service: "mquery"})
+	assert.NoError(t, err)
+	rec, err := p.ParseLine(testLine, 1)
+	assert.NoError(t, err)
+	assert.True(t, rec.IsProcessable())
+	assert.Equal(t, "10.0.0.1", rec.GetClientIP().String())
+	assert.Equal(t, "curl/8.0", rec.GetUserAgent())
+	assert.Equal(t, 200, rec.Status)
+	assert.Equal(t, "corpus", rec.Action)
+	assert.Equal(t, "2026-03-01T09:11:12.123Z", rec.GetTime().Format("2006-01-02T15:04:05.000Z07:00"))
+	assert.Contains(t, rec.Data, "args")
+}
+
+func TestParseLineActionRules(t *testing.T) {
+	p, err := NewLineParser(&Conf{
+		Service: "mquery",
+		Actions: []ActionRule{
+			{Pattern: `^/corpus/[^/]+/(\w+)$`},
+			{Pattern: `^/api/(\w+)/(\w+)$`, Action: "$1-$2"},
+			{Pattern: `^/status$`},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "concordance", p.getAction("/corpus/syn2020/concordance"))
+	assert.Equal(t, "v1-search", p.getAction("/api/v1/search"))
+	assert.Equal(t, "/status", p.getAction("/status"))
+	assert.Equal(t, "other", p.getAction("/other/path"))
+}
+
+func TestParseLineInvalidTime(t *testing.T) {
+	p, err := NewLineParser(&Conf{Service: "mquery"})
+	assert.NoError(t, err)
+	_, err = p.ParseLine(`{"time":"yesterday","method":"GET"}`, 1)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, (&Conf{}).Validate())
+	assert.Error(t, (&Conf{Service: "x", Actions: []ActionRule{{Pattern: "("}}}).Validate())
+	assert.NoError(t, (&Conf{Service: "x", Actions: []ActionRule{{Pattern: "^/(\\w+)"}}}).Validate())
+}
@@ -22,6 +22,7 @@ import (
 	"net"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -222,3 +223,8 @@ func (rec *InputRecord) GetAlignedCorpora() []string {
 func (rec *InputRecord) IsSuspicious() bool {
 	return false
 }
+
+// GetUserID returns an ID of the user (if known)
+func (rec *InputRecord) GetUserID() string {
+	return strconv.Itoa(rec.UserID)
+}
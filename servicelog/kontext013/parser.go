@@ -21,6 +21,8 @@ import (
 	"regexp"
 	"strings"
 
+	"klogproc/errstream"
+
 	"github.com/czcorpus/klogproc-core/storage"
 )
 
@@ -48,6 +50,7 @@ func getLineType(s string) string {
 // LineParser is a parser for reading KonText application logs
 type LineParser struct {
 	appErrorRegister storage.AppErrorRegister
+	errorSink        errstream.Sink
 }
 
 func (lp *LineParser) isIgnoredError(s string) bool {
@@ -68,12 +71,23 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 	} else {
 		if tp == "ERROR" && !lp.isIgnoredError(s) {
 			lp.appErrorRegister.OnError(s)
+			if entry, ok := errstream.ParseLegacyErrorLine(s, lp.errorSink.Location()); ok {
+				lp.errorSink.Add(entry)
+			}
 		}
 		return nil, storage.NewLineParsingError(lineNum, fmt.Sprintf("ignored non-query entry"))
 	}
 }
 
-// NewLineParser is a factory for LineParser
-func NewLineParser(appErrRegister storage.AppErrorRegister) *LineParser {
-	return &LineParser{appErrorRegister: appErrRegister}
+// NewLineParser is a factory for LineParser. Parsed ERROR entries
+// are passed to errorSink (use errstream.NullSink if not needed).
+func NewLineParser(appErrRegister storage.AppErrorRegister, errorSink errstream.Sink) *LineParser {
+	return &LineParser{appErrorRegister: appErrRegister, errorSink: errorSink}
+}
+
+// recognizes tests whether a parsed record has the shape of the 0.13/0.14
+// log (i.e. `params` instead of `args` used since 0.15)
+func recognizes(rec storage.InputRecord) bool {
+	tRec, ok := rec.(*InputRecord)
+	return ok && tRec.Params != nil
 }
@@ -0,0 +1,56 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kontext015
+
+import (
+	"klogproc/apps"
+	"klogproc/errstream"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	kontextCore "github.com/czcorpus/klogproc-core/storage/kontext015"
+)
+
+func init() {
+	versions := []string{
+		storage.AppVersionKontext015,
+		storage.AppVersionKontext016,
+		storage.AppVersionKontext017,
+		storage.AppVersionKontext017API,
+	}
+	for _, version := range versions {
+		// note: the "API" variant uses the same log format
+		// and thus it cannot be detected
+		isAPIVariant := version == storage.AppVersionKontext017API
+		apps.Register(apps.App{
+			Type:    storage.AppTypeKontext,
+			Version: version,
+			NewParser: func(env apps.Env) (storage.LineParser, error) {
+				return apps.WrapParser(NewLineParser(env.AppErrRegister(), errstream.GetSink(env))), nil
+			},
+			NewTransformer: func(env apps.Env) (storage.LogItemTransformer, error) {
+				return &Transformer{
+					AnonymousUsers: env.AnonymousUsers(),
+					IsAPI:          !isAPIVariant,
+				}, nil
+			},
+			NewInputRecord:  func() storage.InputRecord { return &InputRecord{} },
+			NewOutputRecord: func() storage.OutputRecord { return &kontextCore.OutputRecord{} },
+			NoDetection:     isAPIVariant,
+			Recognizes:      recognizes,
+		})
+	}
+}
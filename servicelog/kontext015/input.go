@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"reflect"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/czcorpus/klogproc-core/storage"
@@ -267,3 +268,8 @@ func (rec *InputRecord) GetAlignedCorpora() []string {
 func (rec *InputRecord) IsSuspicious() bool {
 	return false
 }
+
+// GetUserID returns an ID of the user (if known)
+func (rec *InputRecord) GetUserID() string {
+	return strconv.Itoa(rec.UserID)
+}
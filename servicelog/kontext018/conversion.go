@@ -102,7 +102,7 @@ func exportArgs(action string, data map[string]any) map[string]any {
 
 // Transformer converts a source log object into a destination one
 type Transformer struct {
-	analyzer       *analysis.BotAnalyzer[*InputRecord]
+	analyzer       storage.Preprocessor
 	anonymousUsers []int
 }
 
@@ -147,7 +147,7 @@ func (t *Transformer) HistoryLookupItems() int {
 func (t *Transformer) Preprocess(
 	rec storage.InputRecord, prevRecs storage.ServiceLogBuffer,
 ) ([]storage.InputRecord, error) {
-	return []storage.InputRecord{rec}, nil
+	return t.analyzer.Preprocess(rec, prevRecs), nil
 }
 
 func NewTransformer(
@@ -156,7 +156,13 @@ func NewTransformer(
 	emailNotifier analysis.Notifier,
 	anonymousUsers []int,
 ) *Transformer {
-	analyzer := analysis.NewBotAnalyzer[*InputRecord]("kontext", bufferConf, realtimeClock, emailNotifier)
+	var analyzer storage.Preprocessor
+	if bufferConf != nil && bufferConf.BotDetection != nil {
+		analyzer = analysis.NewBotAnalyzer[*InputRecord]("kontext", bufferConf, realtimeClock, emailNotifier)
+
+	} else {
+		analyzer = analysis.NewNullAnalyzer[*InputRecord]("kontext")
+	}
 	return &Transformer{
 		analyzer:       analyzer,
 		anonymousUsers: anonymousUsers,
@@ -17,13 +17,52 @@
 package kontext018
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"time"
 
+	"klogproc/errstream"
+
 	"github.com/czcorpus/klogproc-core/storage"
 )
 
+// analyzedActions contains actions which are expensive to handle
+// and/or typical for scraping (searching, browsing and downloading
+// concordances, frequencies, collocations and word lists). Actions
+// may be logged with or without a leading slash.
+var analyzedActions = map[string]bool{
+	"query_submit":      true,
+	"create_view":       true,
+	"create_lazy_view":  true,
+	"view":              true,
+	"restore_conc":      true,
+	"widectx":           true,
+	"fullref":           true,
+	"structctx":         true,
+	"saveconc":          true,
+	"filter":            true,
+	"sortx":             true,
+	"shuffle":           true,
+	"freqs":             true,
+	"freqml":            true,
+	"freqct":            true,
+	"freqs_submit":      true,
+	"savefreq":          true,
+	"collx":             true,
+	"savecoll":          true,
+	"wordlist/submit":   true,
+	"wordlist/result":   true,
+	"wordlist/savewl":   true,
+	"pquery/submit":     true,
+	"pquery/download":   true,
+	"keywords/submit":   true,
+	"keywords/download": true,
+}
+
 func getSliceOfStrings(data interface{}, key string) ([]string, bool) {
 	v, ok := data.(map[string]interface{})
 	if !ok {
@@ -86,14 +125,37 @@ type InputRecord struct {
 // to seconds.
 func (rec *InputRecord) GetTime() time.Time {
 	if rec.isProcessable {
-		if rec.Date[len(rec.Date)-1] == 'Z' {
-			return storage.ConvertDatetimeStringWithMillisNoTZ(rec.Date[:len(rec.Date)-1] + "000")
-		}
-		return storage.ConvertDatetimeStringWithMillis(rec.Date)
+		return rec.parseDate()
 	}
 	return time.Time{}
 }
 
+func (rec *InputRecord) parseDate() time.Time {
+	if rec.Date == "" {
+		return time.Time{}
+	}
+	if rec.Date[len(rec.Date)-1] == 'Z' {
+		return storage.ConvertDatetimeStringWithMillisNoTZ(rec.Date[:len(rec.Date)-1] + "000")
+	}
+	return storage.ConvertDatetimeStringWithMillis(rec.Date)
+}
+
+// ExportErrorEntry returns an error entry in case the record
+// is an ERROR-level entry or it contains an exception
+func (rec *InputRecord) ExportErrorEntry() (errstream.Entry, bool) {
+	if rec.Level != "ERROR" && rec.Level != "CRITICAL" && rec.Exception.Type == "" {
+		return errstream.Entry{}, false
+	}
+	return errstream.Entry{
+		Time:          rec.parseDate(),
+		Logger:        rec.Logger,
+		ExceptionID:   rec.Exception.ID,
+		ExceptionType: rec.Exception.Type,
+		Message:       rec.Message,
+		Stack:         rec.Exception.Stack,
+	}, true
+}
+
 // GetClientIP returns a client IP no matter in which
 // part of the record it was found
 // (e.g. REMOTE_ADDR vs. HTTP_REMOTE_ADDR vs. HTTP_FORWARDED_FOR)
@@ -110,14 +172,19 @@ func (rec *InputRecord) GetClientIP() net.IP {
 	return nil
 }
 
+// ShouldBeAnalyzed tells whether the record should be considered
+// by bot detection
 func (rec *InputRecord) ShouldBeAnalyzed() bool {
-	return rec.Action == "query_submit" || rec.Action == "create_view" ||
-		rec.Action == "create_lazy_view" || rec.Action == "wordlist/submit"
-	// TODO the list of actions is incomplete
+	return analyzedActions[strings.TrimPrefix(rec.Action, "/")]
 }
 
+// ClusteringClientID returns a stable client identifier derived
+// from user ID, IP address and user agent. The user ID alone is not
+// enough as all the anonymous users share the same ID.
 func (rec *InputRecord) ClusteringClientID() string {
-	return storage.GenerateRandomClusteringID()
+	sum := sha1.New()
+	sum.Write([]byte(fmt.Sprintf("%d#%s#%s", rec.UserID, rec.GetClientIP(), rec.GetUserAgent())))
+	return hex.EncodeToString(sum.Sum(nil))
 }
 
 func (rec *InputRecord) ClusterSize() int {
@@ -193,3 +260,8 @@ func (rec *InputRecord) GetAlignedCorpora() []string {
 func (rec *InputRecord) IsSuspicious() bool {
 	return false
 }
+
+// GetUserID returns an ID of the user (if known)
+func (rec *InputRecord) GetUserID() string {
+	return strconv.Itoa(rec.UserID)
+}
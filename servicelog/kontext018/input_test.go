@@ -0,0 +1,90 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kontext018
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldBeAnalyzed(t *testing.T) {
+	assert.True(t, (&InputRecord{Action: "query_submit"}).ShouldBeAnalyzed())
+	assert.True(t, (&InputRecord{Action: "/wordlist/submit"}).ShouldBeAnalyzed())
+	assert.True(t, (&InputRecord{Action: "freqs"}).ShouldBeAnalyzed())
+	assert.False(t, (&InputRecord{Action: "/user/ajax_query_history"}).ShouldBeAnalyzed())
+	assert.False(t, (&InputRecord{Action: "corpora/corplist"}).ShouldBeAnalyzed())
+}
+
+func TestClusteringClientIDIsStable(t *testing.T) {
+	rec1 := &InputRecord{
+		UserID:  0,
+		Request: Request{HTTPForwardedFor: "192.168.1.10", HTTPUserAgent: "Mozilla/5.0"},
+	}
+	rec2 := &InputRecord{
+		UserID:  0,
+		Action:  "view",
+		Request: Request{RemoteAddr: "192.168.1.10", HTTPUserAgent: "Mozilla/5.0"},
+	}
+	assert.Equal(t, rec1.ClusteringClientID(), rec2.ClusteringClientID())
+	assert.Len(t, rec1.ClusteringClientID(), 40)
+}
+
+func TestClusteringClientIDDistinguishesClients(t *testing.T) {
+	rec := &InputRecord{
+		UserID:  0,
+		Request: Request{HTTPForwardedFor: "192.168.1.10", HTTPUserAgent: "Mozilla/5.0"},
+	}
+	otherUA := &InputRecord{
+		UserID:  0,
+		Request: Request{HTTPForwardedFor: "192.168.1.10", HTTPUserAgent: "python-requests/2.31"},
+	}
+	otherIP := &InputRecord{
+		UserID:  0,
+		Request: Request{HTTPForwardedFor: "192.168.1.11", HTTPUserAgent: "Mozilla/5.0"},
+	}
+	otherUser := &InputRecord{
+		UserID:  1234,
+		Request: Request{HTTPForwardedFor: "192.168.1.10", HTTPUserAgent: "Mozilla/5.0"},
+	}
+	assert.NotEqual(t, rec.ClusteringClientID(), otherUA.ClusteringClientID())
+	assert.NotEqual(t, rec.ClusteringClientID(), otherIP.ClusteringClientID())
+	assert.NotEqual(t, rec.ClusteringClientID(), otherUser.ClusteringClientID())
+}
+
+func TestExportErrorEntry(t *testing.T) {
+	rec := &InputRecord{
+		Logger:  "kontext",
+		Level:   "ERROR",
+		Date:    "2026-03-01T09:11:12.345Z",
+		Message: "failed to find corpus",
+		Exception: ExceptionInfo{
+			ID:    "abc123",
+			Type:  "CorpusNotFound",
+			Stack: []string{`File "/opt/kontext/lib/corplib.py", line 20, in open`},
+		},
+	}
+	entry, ok := rec.ExportErrorEntry()
+	assert.True(t, ok)
+	assert.Equal(t, "CorpusNotFound", entry.ExceptionType)
+	assert.Equal(t, "abc123", entry.ExceptionID)
+	assert.Equal(t, "failed to find corpus", entry.Message)
+	assert.Len(t, entry.Stack, 1)
+
+	_, ok = (&InputRecord{Logger: "QUERY", Level: "INFO"}).ExportErrorEntry()
+	assert.False(t, ok)
+}
@@ -19,11 +19,14 @@ package kontext018
 import (
 	"encoding/json"
 
+	"klogproc/errstream"
+
 	"github.com/czcorpus/klogproc-core/storage"
 )
 
 // LineParser is a parser for reading KonText application logs
 type LineParser struct {
+	errorSink errstream.Sink
 }
 
 // ParseLine parses a query log line - i.e. it expects
@@ -37,10 +40,14 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 	if record.Logger == "QUERY" {
 		record.isProcessable = true
 	}
+	if entry, ok := record.ExportErrorEntry(); ok {
+		lp.errorSink.Add(entry)
+	}
 	return &record, nil
 }
 
-// NewLineParser is a factory for LineParser
-func NewLineParser() *LineParser {
-	return &LineParser{}
+// NewLineParser is a factory for LineParser. ERROR entries and
+// exceptions are passed to errorSink (use errstream.NullSink if not needed).
+func NewLineParser(errorSink errstream.Sink) *LineParser {
+	return &LineParser{errorSink: errorSink}
 }
@@ -85,3 +85,8 @@ func (rec *InputRecord) IsProcessable() bool {
 func (rec *InputRecord) IsSuspicious() bool {
 	return false
 }
+
+// GetUserID returns an ID of the user (if known)
+func (rec *InputRecord) GetUserID() string {
+	return rec.UserID
+}
@@ -183,3 +183,8 @@ func (rec *InputRecord) ExportError() *storage.ErrorRecord {
 	}
 	return nil
 }
+
+// GetUserID returns an ID of the user (if known)
+func (rec *InputRecord) GetUserID() string {
+	return rec.Headers.XUserID()
+}
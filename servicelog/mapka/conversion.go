@@ -19,6 +19,8 @@ package mapka
 import (
 	"strconv"
 
+	"klogproc/load/accesslog"
+
 	"github.com/czcorpus/klogproc-core/storage"
 	mapkaCore "github.com/czcorpus/klogproc-core/storage/mapka"
 )
@@ -43,25 +45,30 @@ func (t *Transformer) Transform(
 	}
 	userID := -1
 
-	r := &mapkaCore.OutputRecord{
-		Type:        t.AppType(),
-		IPAddress:   tLogRecord.Request.RemoteAddr,
-		UserAgent:   tLogRecord.Request.HTTPUserAgent,
-		IsAnonymous: userID == -1 || storage.UserBelongsToList(userID, t.anonymousUsers),
-		IsQuery:     false,
-		UserID:      strconv.Itoa(userID),
-		Action:      tLogRecord.Action,
-		Path:        tLogRecord.Path,
-		ProcTime:    tLogRecord.ProcTime,
-		Params:      tLogRecord.Params,
+	r := &OutputRecord{
+		OutputRecord: mapkaCore.OutputRecord{
+			Type:        t.AppType(),
+			IPAddress:   tLogRecord.Request.RemoteAddr,
+			UserAgent:   tLogRecord.Request.HTTPUserAgent,
+			IsAnonymous: userID == -1 || storage.UserBelongsToList(userID, t.anonymousUsers),
+			IsQuery:     false,
+			UserID:      strconv.Itoa(userID),
+			Action:      tLogRecord.Action,
+			Path:        tLogRecord.Path,
+			ProcTime:    tLogRecord.ProcTime,
+			Params:      tLogRecord.Params,
+		},
+		Status:   tLogRecord.Status,
+		BodySize: tLogRecord.BodySize,
 	}
 	r.SetTime(tLogRecord.GetTime())
 	r.ID = r.GenerateDeterministicID()
-	if t.prevReqs.ContainsSimilar(r) && r.Action == "overlay" ||
-		!t.prevReqs.ContainsSimilar(r) && r.Action == "text" {
+	if accesslog.IsSuccessStatus(tLogRecord.Status) &&
+		(t.prevReqs.ContainsSimilar(&r.OutputRecord) && r.Action == "overlay" ||
+			!t.prevReqs.ContainsSimilar(&r.OutputRecord) && r.Action == "text") {
 		r.IsQuery = true
 	}
-	t.prevReqs.AddItem(r)
+	t.prevReqs.AddItem(&r.OutputRecord)
 	return r, nil
 }
 
@@ -41,6 +41,8 @@ type InputRecord struct {
 	Request       *Request
 	Params        *mapkaCore.RequestParams `json:"params"`
 	ProcTime      float64
+	Status        int
+	BodySize      int64
 	isProcessable bool
 }
 
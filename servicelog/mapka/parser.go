@@ -21,6 +21,7 @@ import (
 
 	"klogproc/load/accesslog"
 
+	"github.com/czcorpus/klogproc-core/storage"
 	mapkaCore "github.com/czcorpus/klogproc-core/storage/mapka"
 )
 
@@ -59,6 +60,12 @@ type LineParser struct {
 	parser accesslog.LineParser
 }
 
+// NewLineParser creates a parser using the provided access log parser
+// (which allows for custom access log formats)
+func NewLineParser(parser accesslog.LineParser) *LineParser {
+	return &LineParser{parser: parser}
+}
+
 // ParseLine parses a HTTP access log format line
 func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 	parsed, err := lp.parser.ParseLine(s, lineNum)
@@ -77,11 +84,20 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 		Datetime:      parsed.Datetime,
 		Request: &Request{
 			HTTPUserAgent:  parsed.UserAgent,
-			HTTPRemoteAddr: parsed.IPAddress,
-			RemoteAddr:     parsed.IPAddress, // TODO the same stuff as above?
+			HTTPRemoteAddr: parsed.ClientIP(),
+			RemoteAddr:     parsed.ClientIP(), // TODO the same stuff as above?
 		},
 		Params:   params,
 		ProcTime: parsed.ProcTime,
+		Status:   parsed.Status,
+		BodySize: parsed.BodySize,
 	}
 	return ans, nil
 }
+
+// recognizes tests whether a parsed record contains an action
+// specific to the first version of Mapka
+func recognizes(rec storage.InputRecord) bool {
+	tRec, ok := rec.(*InputRecord)
+	return ok && (tRec.Action == "text" || tRec.Action == "overlay")
+}
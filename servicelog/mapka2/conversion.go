@@ -19,6 +19,8 @@ package mapka2
 import (
 	"strconv"
 
+	"klogproc/load/accesslog"
+
 	"github.com/czcorpus/klogproc-core/storage"
 	mapka2Core "github.com/czcorpus/klogproc-core/storage/mapka2"
 )
@@ -42,20 +44,25 @@ func (t *Transformer) Transform(
 	}
 	userID := -1
 
-	r := &mapka2Core.OutputRecord{
-		Type:        t.AppType(),
-		IPAddress:   tLogRecord.Request.RemoteAddr,
-		UserAgent:   tLogRecord.Request.HTTPUserAgent,
-		IsAnonymous: userID == -1 || storage.UserBelongsToList(userID, t.anonymousUsers),
-		IsQuery:     false,
-		UserID:      strconv.Itoa(userID),
-		Action:      tLogRecord.Action,
-		Path:        tLogRecord.Path,
-		ProcTime:    tLogRecord.ProcTime,
+	r := &OutputRecord{
+		OutputRecord: mapka2Core.OutputRecord{
+			Type:        t.AppType(),
+			IPAddress:   tLogRecord.Request.RemoteAddr,
+			UserAgent:   tLogRecord.Request.HTTPUserAgent,
+			IsAnonymous: userID == -1 || storage.UserBelongsToList(userID, t.anonymousUsers),
+			IsQuery:     false,
+			UserID:      strconv.Itoa(userID),
+			Action:      tLogRecord.Action,
+			Path:        tLogRecord.Path,
+			ProcTime:    tLogRecord.ProcTime,
+		},
+		Status:   tLogRecord.Status,
+		BodySize: tLogRecord.BodySize,
 	}
 	r.SetTime(tLogRecord.GetTime())
 	r.ID = r.GenerateDeterministicID()
-	if r.Action == "index" || r.Action == "records_list" || r.Action == "city" {
+	if accesslog.IsSuccessStatus(tLogRecord.Status) &&
+		(r.Action == "index" || r.Action == "records_list" || r.Action == "city") {
 		r.IsQuery = true
 	}
 	return r, nil
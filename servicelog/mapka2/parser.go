@@ -21,6 +21,8 @@ import (
 	"strings"
 
 	"klogproc/load/accesslog"
+
+	"github.com/czcorpus/klogproc-core/storage"
 )
 
 var (
@@ -48,6 +50,12 @@ type LineParser struct {
 	parser accesslog.LineParser
 }
 
+// NewLineParser creates a parser using the provided access log parser
+// (which allows for custom access log formats)
+func NewLineParser(parser accesslog.LineParser) *LineParser {
+	return &LineParser{parser: parser}
+}
+
 // ParseLine parses a HTTP access log format line
 func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 	parsed, err := lp.parser.ParseLine(s, lineNum)
@@ -66,10 +74,21 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 		Datetime:      parsed.Datetime,
 		Request: &Request{
 			HTTPUserAgent:  parsed.UserAgent,
-			HTTPRemoteAddr: parsed.IPAddress,
-			RemoteAddr:     parsed.IPAddress, // TODO the same stuff as above?
+			HTTPRemoteAddr: parsed.ClientIP(),
+			RemoteAddr:     parsed.ClientIP(), // TODO the same stuff as above?
 		},
 		ProcTime: parsed.ProcTime,
+		Status:   parsed.Status,
+		BodySize: parsed.BodySize,
 	}
 	return ans, nil
 }
+
+// recognizes tests whether a parsed record contains an action specific
+// to the second version of Mapka (i.e. not an index or an action of the
+// first version)
+func recognizes(rec storage.InputRecord) bool {
+	tRec, ok := rec.(*InputRecord)
+	return ok && tRec.IsProcessable() &&
+		tRec.Action != "index" && tRec.Action != "text" && tRec.Action != "overlay"
+}
@@ -135,3 +135,8 @@ func (r *InputRecord) ShouldBeAnalyzed() bool {
 func (rec *InputRecord) IsSuspicious() bool {
 	return false
 }
+
+// GetUserID returns an ID of the user (if known)
+func (r *InputRecord) GetUserID() string {
+	return r.Extra.UserID
+}
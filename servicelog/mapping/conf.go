@@ -0,0 +1,163 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// AppType is a config code of applications processed
+	// by the declarative mapping transformer
+	AppType = "mapping"
+
+	FieldTypeString = "string"
+	FieldTypeInt    = "int"
+	FieldTypeFloat  = "float"
+	FieldTypeBool   = "bool"
+	FieldTypeTime   = "time"
+
+	TimeFormatRFC3339 = "rfc3339"
+	TimeFormatUnix    = "unix"
+	TimeFormatUnixMs  = "unixms"
+)
+
+// FieldConf specifies how a single input value is converted
+// into an output property. Path is either a JSON pointer
+// (e.g. `/args/corpname`) or a dotted path (e.g. `args.corpname`).
+type FieldConf struct {
+	Path string `json:"path"`
+
+	// Name is the name of the output property. If empty, the last
+	// element of Path is used.
+	Name string `json:"name"`
+
+	// Type is one of string (default), int, float, bool, time
+	Type string `json:"type"`
+
+	// Format is used with time values: rfc3339 (default), unix,
+	// unixms or a Go time layout (e.g. `2006-01-02 15:04:05`)
+	Format string `json:"format"`
+}
+
+// OutName returns the name of the output property
+func (fc FieldConf) OutName() string {
+	if fc.Name != "" {
+		return fc.Name
+	}
+	path := strings.ReplaceAll(strings.Trim(fc.Path, "/"), "/", ".")
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+// Validate checks the field configuration
+func (fc FieldConf) Validate() error {
+	if fc.Path == "" {
+		return errors.New("missing path")
+	}
+	switch fc.Type {
+	case "", FieldTypeString, FieldTypeInt, FieldTypeFloat, FieldTypeBool, FieldTypeTime:
+	default:
+		return fmt.Errorf("unknown type %s of %s", fc.Type, fc.Path)
+	}
+	if fc.Format != "" && fc.Type != FieldTypeTime {
+		return fmt.Errorf("format can be used only with time values (%s)", fc.Path)
+	}
+	return nil
+}
+
+// Condition tests a single input value. If Values are empty,
+// the condition is met if the value exists and it is not empty.
+// Otherwise, the value (converted to a string) must be equal
+// to one of the Values.
+type Condition struct {
+	Path   string   `json:"path"`
+	Values []string `json:"values"`
+	Negate bool     `json:"negate"`
+}
+
+// Conditions is a list of conditions which all must be met
+type Conditions []Condition
+
+func (cc Conditions) validate() error {
+	for _, c := range cc {
+		if c.Path == "" {
+			return errors.New("missing condition path")
+		}
+	}
+	return nil
+}
+
+// Conf describes a conversion of a JSON log record into an output record
+type Conf struct {
+	// Type is the `type` property of output records
+	Type string `json:"type"`
+
+	// Time specifies a value containing the record time (required)
+	Time FieldConf `json:"time"`
+
+	IPAddress FieldConf `json:"ipAddress"`
+	UserAgent FieldConf `json:"userAgent"`
+	UserID    FieldConf `json:"userId"`
+
+	// Fields are additional properties copied from the input record
+	Fields []FieldConf `json:"fields"`
+
+	// Constants are properties with the same value in all
+	// the output records
+	Constants map[string]any `json:"constants"`
+
+	// IsQuery conditions mark a record as a query (if all are met)
+	IsQuery Conditions `json:"isQuery"`
+
+	// Skip conditions mark a record as non-processable (if all are met)
+	Skip Conditions `json:"skip"`
+}
+
+// Validate checks the configuration
+func (conf *Conf) Validate() error {
+	if conf.Type == "" {
+		return errors.New("invalid mapping: missing type")
+	}
+	if err := conf.Time.Validate(); err != nil {
+		return fmt.Errorf("invalid mapping of time: %w", err)
+	}
+	if conf.Time.Type != "" && conf.Time.Type != FieldTypeTime {
+		return errors.New("invalid mapping of time: type must be time")
+	}
+	for _, fc := range []FieldConf{conf.IPAddress, conf.UserAgent, conf.UserID} {
+		if fc.Path == "" {
+			continue
+		}
+		if err := fc.Validate(); err != nil {
+			return fmt.Errorf("invalid mapping: %w", err)
+		}
+	}
+	for _, fc := range conf.Fields {
+		if err := fc.Validate(); err != nil {
+			return fmt.Errorf("invalid mapping of fields: %w", err)
+		}
+	}
+	if err := conf.IsQuery.validate(); err != nil {
+		return fmt.Errorf("invalid mapping of isQuery: %w", err)
+	}
+	if err := conf.Skip.validate(); err != nil {
+		return fmt.Errorf("invalid mapping of skip: %w", err)
+	}
+	return nil
+}
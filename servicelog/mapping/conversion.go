@@ -0,0 +1,87 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"fmt"
+
+	"klogproc/servicelog/custom"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// Transformer creates generic output records from JSON log records
+// as specified by a mapping configuration
+type Transformer struct {
+	conf           *Conf
+	anonymousUsers []int
+}
+
+func (t *Transformer) AppType() string {
+	return AppType
+}
+
+func (t *Transformer) Transform(
+	logRecord storage.InputRecord,
+) (storage.OutputRecord, error) {
+	tLogRecord, ok := logRecord.(*InputRecord)
+	if !ok {
+		panic(storage.ErrFailedTypeAssertion)
+	}
+	userID := tLogRecord.GetNumericUserID()
+	rec := &custom.OutputRecord{
+		Type:        t.conf.Type,
+		IPAddress:   tLogRecord.IPAddress,
+		UserAgent:   tLogRecord.UserAgent,
+		UserID:      tLogRecord.UserID,
+		IsAnonymous: userID == -1 || storage.UserBelongsToList(userID, t.anonymousUsers),
+		IsQuery:     len(t.conf.IsQuery) > 0 && t.conf.IsQuery.Match(tLogRecord.Data),
+		Props:       make(map[string]any, len(t.conf.Fields)+len(t.conf.Constants)),
+	}
+	for k, v := range t.conf.Constants {
+		rec.Props[k] = v
+	}
+	for _, fc := range t.conf.Fields {
+		v, ok := Lookup(tLogRecord.Data, fc.Path)
+		if !ok {
+			continue
+		}
+		cv, err := ConvertValue(v, fc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform %s record: %w", t.conf.Type, err)
+		}
+		rec.Props[fc.OutName()] = cv
+	}
+	rec.SetTime(tLogRecord.GetTime())
+	rec.ID = rec.GenerateDeterministicID()
+	return rec, nil
+}
+
+func (t *Transformer) HistoryLookupItems() int {
+	return 0
+}
+
+func (t *Transformer) Preprocess(
+	rec storage.InputRecord, prevRecs storage.ServiceLogBuffer,
+) ([]storage.InputRecord, error) {
+	return []storage.InputRecord{rec}, nil
+}
+
+// NewTransformer is a factory for Transformer
+func NewTransformer(conf *Conf, anonymousUsers []int) *Transformer {
+	return &Transformer{conf: conf, anonymousUsers: anonymousUsers}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	conf := &Conf{
+		Type:      "vlo",
+		Time:      FieldConf{Path: "time"},
+		IPAddress: FieldConf{Path: "clientIP"},
+		Fields: []FieldConf{
+			{Path: "latency", Name: "procTime", Type: FieldTypeFloat},
+			{Path: "/args/page", Type: FieldTypeInt},
+		},
+		Constants: map[string]any{"service": "vlo"},
+		IsQuery:   Conditions{{Path: "operation", Values: []string{"GetRecord", "ListRecords"}}},
+		Skip:      Conditions{{Path: "method", Negate: true}},
+	}
+	assert.NoError(t, conf.Validate())
+	p := NewLineParser(conf)
+	rec, err := p.ParseLine(
+		`{"time": "2026-03-01T09:11:12Z", "clientIP": "10.0.0.1", "method": "GET", `+
+			`"latency": 0.25, "operation": "GetRecord", "args": {"page": "3"}}`, 1)
+	assert.NoError(t, err)
+	assert.True(t, rec.IsProcessable())
+
+	out, err := NewTransformer(conf, []int{}).Transform(rec)
+	assert.NoError(t, err)
+	data, err := out.ToJSON()
+	assert.NoError(t, err)
+	var tmp map[string]any
+	assert.NoError(t, json.Unmarshal(data, &tmp))
+	assert.Equal(t, "vlo", tmp["type"])
+	assert.Equal(t, "2026-03-01T09:11:12Z", tmp["datetime"])
+	assert.Equal(t, "10.0.0.1", tmp["ipAddress"])
+	assert.Equal(t, true, tmp["isQuery"])
+	assert.Equal(t, 0.25, tmp["procTime"])
+	assert.Equal(t, float64(3), tmp["page"])
+	assert.Equal(t, "vlo", tmp["service"])
+	assert.NotEmpty(t, out.GetID())
+
+	out2, err := NewTransformer(conf, []int{}).Transform(rec)
+	assert.NoError(t, err)
+	assert.Equal(t, out.GetID(), out2.GetID())
+}
+
+func TestParseLineSkipAndMissingTime(t *testing.T) {
+	conf := &Conf{
+		Type: "vlo",
+		Time: FieldConf{Path: "ts", Format: TimeFormatUnix},
+		Skip: Conditions{{Path: "method", Negate: true}},
+	}
+	p := NewLineParser(conf)
+	rec, err := p.ParseLine(`{"ts": 1772356272, "msg": "starting server"}`, 1)
+	assert.NoError(t, err)
+	assert.False(t, rec.IsProcessable())
+	_, err = p.ParseLine(`{"msg": "no time"}`, 2)
+	assert.Error(t, err)
+}
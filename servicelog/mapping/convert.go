@@ -0,0 +1,137 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+func toString(v any) string {
+	switch tv := v.(type) {
+	case string:
+		return tv
+	case float64:
+		return strconv.FormatFloat(tv, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(tv)
+	case nil:
+		return ""
+	default:
+		data, err := json.Marshal(tv)
+		if err != nil {
+			return fmt.Sprint(tv)
+		}
+		return string(data)
+	}
+}
+
+func parseTime(v any, format string) (time.Time, error) {
+	switch format {
+	case TimeFormatUnix, TimeFormatUnixMs:
+		var num float64
+		switch tv := v.(type) {
+		case float64:
+			num = tv
+		case string:
+			var err error
+			num, err = strconv.ParseFloat(tv, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid UNIX time %s", tv)
+			}
+		default:
+			return time.Time{}, fmt.Errorf("invalid UNIX time %v", v)
+		}
+		if format == TimeFormatUnixMs {
+			return time.UnixMilli(int64(num)), nil
+		}
+		sec, frac := math.Modf(num)
+		return time.Unix(int64(sec), int64(frac*1e9)), nil
+	default:
+		sv, ok := v.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid time %v", v)
+		}
+		layout := format
+		if layout == "" || layout == TimeFormatRFC3339 {
+			layout = time.RFC3339Nano
+		}
+		return time.Parse(layout, sv)
+	}
+}
+
+// ConvertValue converts a decoded JSON value according to the field
+// configuration. Time values are returned as RFC3339 strings.
+func ConvertValue(v any, fc FieldConf) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch fc.Type {
+	case "", FieldTypeString:
+		return toString(v), nil
+	case FieldTypeInt:
+		switch tv := v.(type) {
+		case float64:
+			return int(tv), nil
+		case string:
+			ans, err := strconv.Atoi(tv)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s to int: %w", fc.Path, err)
+			}
+			return ans, nil
+		case bool:
+			if tv {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case FieldTypeFloat:
+		switch tv := v.(type) {
+		case float64:
+			return tv, nil
+		case string:
+			ans, err := strconv.ParseFloat(tv, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s to float: %w", fc.Path, err)
+			}
+			return ans, nil
+		}
+	case FieldTypeBool:
+		switch tv := v.(type) {
+		case bool:
+			return tv, nil
+		case float64:
+			return tv != 0, nil
+		case string:
+			ans, err := strconv.ParseBool(tv)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s to bool: %w", fc.Path, err)
+			}
+			return ans, nil
+		}
+	case FieldTypeTime:
+		t, err := parseTime(v, fc.Format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s to time: %w", fc.Path, err)
+		}
+		return t.Format(time.RFC3339), nil
+	}
+	return nil, fmt.Errorf("failed to convert %s to %s: unsupported value %v", fc.Path, fc.Type, v)
+}
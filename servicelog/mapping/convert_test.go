@@ -0,0 +1,76 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertValue(t *testing.T) {
+	v, err := ConvertValue("12", FieldConf{Path: "x", Type: FieldTypeInt})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, v)
+	v, err = ConvertValue(float64(3.5), FieldConf{Path: "x", Type: FieldTypeFloat})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.5, v)
+	v, err = ConvertValue("true", FieldConf{Path: "x", Type: FieldTypeBool})
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+	v, err = ConvertValue(float64(17), FieldConf{Path: "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "17", v)
+	_, err = ConvertValue("foo", FieldConf{Path: "x", Type: FieldTypeInt})
+	assert.Error(t, err)
+}
+
+func TestConvertTime(t *testing.T) {
+	v, err := ConvertValue(float64(1772356272), FieldConf{Path: "x", Type: FieldTypeTime, Format: TimeFormatUnix})
+	assert.NoError(t, err)
+	tm, err := parseTime(v, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1772356272), tm.Unix())
+
+	tm, err = parseTime("1772356272123", TimeFormatUnixMs)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1772356272123), tm.UnixMilli())
+
+	tm, err = parseTime("2026-03-01 09:11:12", "2006-01-02 15:04:05")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1772356272), tm.Unix())
+
+	_, err = parseTime(true, "")
+	assert.Error(t, err)
+}
+
+func TestConfValidate(t *testing.T) {
+	conf := &Conf{Type: "vlo", Time: FieldConf{Path: "time"}}
+	assert.NoError(t, conf.Validate())
+	conf.Fields = []FieldConf{{Path: "latency", Type: "decimal"}}
+	assert.Error(t, conf.Validate())
+	conf.Fields = []FieldConf{{Path: "latency", Format: "unix"}}
+	assert.Error(t, conf.Validate())
+	assert.Error(t, (&Conf{Time: FieldConf{Path: "time"}}).Validate())
+	assert.Error(t, (&Conf{Type: "vlo"}).Validate())
+}
+
+func TestOutName(t *testing.T) {
+	assert.Equal(t, "corpname", FieldConf{Path: "/args/corpname"}.OutName())
+	assert.Equal(t, "corpname", FieldConf{Path: "args.corpname"}.OutName())
+	assert.Equal(t, "corpus", FieldConf{Path: "args.corpname", Name: "corpus"}.OutName())
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// LineParser parses JSON log lines and extracts common values
+// (time, IP address etc.) as specified by a mapping configuration
+type LineParser struct {
+	conf *Conf
+}
+
+func (lp *LineParser) lookupString(data map[string]any, fc FieldConf) string {
+	if fc.Path == "" {
+		return ""
+	}
+	v, ok := Lookup(data, fc.Path)
+	if !ok {
+		return ""
+	}
+	return toString(v)
+}
+
+// ParseLine parses a JSON log line
+func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
+	var data map[string]any
+	if err := json.Unmarshal([]byte(s), &data); err != nil {
+		return nil, err
+	}
+	tv, ok := Lookup(data, lp.conf.Time.Path)
+	if !ok {
+		return nil, storage.NewLineParsingError(lineNum, fmt.Sprintf("missing time (%s)", lp.conf.Time.Path))
+	}
+	t, err := parseTime(tv, lp.conf.Time.Format)
+	if err != nil {
+		return nil, storage.NewLineParsingError(lineNum, err.Error())
+	}
+	return &InputRecord{
+		Data:      data,
+		Time:      t,
+		IPAddress: lp.lookupString(data, lp.conf.IPAddress),
+		UserAgent: lp.lookupString(data, lp.conf.UserAgent),
+		UserID:    lp.lookupString(data, lp.conf.UserID),
+		Skip:      len(lp.conf.Skip) > 0 && lp.conf.Skip.Match(data),
+	}, nil
+}
+
+// NewLineParser is a factory for LineParser
+func NewLineParser(conf *Conf) *LineParser {
+	return &LineParser{conf: conf}
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"strconv"
+	"strings"
+)
+
+// splitPath splits a JSON pointer (RFC 6901) or a dotted path
+// into individual keys
+func splitPath(path string) []string {
+	if strings.HasPrefix(path, "/") {
+		items := strings.Split(path[1:], "/")
+		for i, item := range items {
+			items[i] = strings.ReplaceAll(strings.ReplaceAll(item, "~1", "/"), "~0", "~")
+		}
+		return items
+	}
+	return strings.Split(path, ".")
+}
+
+// Lookup finds a value in a decoded JSON document
+func Lookup(data any, path string) (any, bool) {
+	curr := data
+	for _, key := range splitPath(path) {
+		switch tCurr := curr.(type) {
+		case map[string]any:
+			v, ok := tCurr[key]
+			if !ok {
+				return nil, false
+			}
+			curr = v
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(tCurr) {
+				return nil, false
+			}
+			curr = tCurr[idx]
+		default:
+			return nil, false
+		}
+	}
+	return curr, true
+}
+
+// Match tests whether the data meet all the conditions
+func (cc Conditions) Match(data any) bool {
+	for _, c := range cc {
+		if c.match(data) == c.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) match(data any) bool {
+	v, ok := Lookup(data, c.Path)
+	if !ok || v == nil {
+		return false
+	}
+	sv := toString(v)
+	if len(c.Values) == 0 {
+		return sv != ""
+	}
+	for _, item := range c.Values {
+		if item == sv {
+			return true
+		}
+	}
+	return false
+}
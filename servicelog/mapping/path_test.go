@@ -0,0 +1,60 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapping
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, s string) map[string]any {
+	var ans map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(s), &ans))
+	return ans
+}
+
+func TestLookup(t *testing.T) {
+	data := decode(t, `{"a": {"b": [10, {"c": "x"}]}, "d/e": 1, "f.g": 2}`)
+	v, ok := Lookup(data, "a.b.1.c")
+	assert.True(t, ok)
+	assert.Equal(t, "x", v)
+	v, ok = Lookup(data, "/a/b/0")
+	assert.True(t, ok)
+	assert.Equal(t, float64(10), v)
+	v, ok = Lookup(data, "/d~1e")
+	assert.True(t, ok)
+	assert.Equal(t, float64(1), v)
+	v, ok = Lookup(data, "/f.g")
+	assert.True(t, ok)
+	assert.Equal(t, float64(2), v)
+	_, ok = Lookup(data, "a.b.5")
+	assert.False(t, ok)
+	_, ok = Lookup(data, "a.x")
+	assert.False(t, ok)
+}
+
+func TestConditions(t *testing.T) {
+	data := decode(t, `{"operation": "GetRecord", "status": 200, "path": ""}`)
+	assert.True(t, Conditions{{Path: "operation", Values: []string{"GetRecord", "ListRecords"}}}.Match(data))
+	assert.True(t, Conditions{{Path: "status", Values: []string{"200"}}, {Path: "operation"}}.Match(data))
+	assert.False(t, Conditions{{Path: "path"}}.Match(data))
+	assert.False(t, Conditions{{Path: "missing"}}.Match(data))
+	assert.True(t, Conditions{{Path: "missing", Negate: true}}.Match(data))
+	assert.False(t, Conditions{{Path: "status", Values: []string{"200"}, Negate: true}}.Match(data))
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shiny
+
+import (
+	"fmt"
+	"klogproc/apps"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	shinyCore "github.com/czcorpus/klogproc-core/storage/shiny"
+)
+
+func init() {
+	// all the Shiny apps use the same log format
+	appTypes := []string{
+		storage.AppTypeAkalex,
+		storage.AppTypeCalc,
+		storage.AppTypeGramatikat,
+		storage.AppTypeLists,
+		storage.AppTypeQuitaUp,
+	}
+	for _, appType := range appTypes {
+		apps.Register(apps.App{
+			Type: appType,
+			NewParser: func(env apps.Env) (storage.LineParser, error) {
+				return apps.WrapParser(&LineParser{}), nil
+			},
+			NewTransformer: func(env apps.Env) (storage.LogItemTransformer, error) {
+				userResolver, err := env.UserResolver()
+				if err != nil {
+					return nil, fmt.Errorf("cannot create transformer for %s: %w", appType, err)
+				}
+				return NewTransformer(appType, env.AnonymousUsers(), userResolver), nil
+			},
+			NewInputRecord:  func() storage.InputRecord { return &InputRecord{} },
+			NewOutputRecord: func() storage.OutputRecord { return &shinyCore.OutputRecord{} },
+		})
+	}
+}
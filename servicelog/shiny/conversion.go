@@ -19,6 +19,8 @@ package shiny
 import (
 	"strconv"
 
+	"klogproc/users"
+
 	"github.com/czcorpus/klogproc-core/storage"
 	shinyCore "github.com/czcorpus/klogproc-core/storage/shiny"
 )
@@ -26,6 +28,7 @@ import (
 // Transformer converts a source log object into a destination one
 type Transformer struct {
 	appType        string
+	users          users.IDResolver
 	anonymousUsers []int
 }
 
@@ -42,6 +45,12 @@ func (t *Transformer) Transform(
 		panic(storage.ErrFailedTypeAssertion)
 	}
 	userID := tLogRecord.User.ID
+	if userID == 0 && tLogRecord.User.User != "" {
+		// some records contain only a username
+		if uid, ok := t.users.Resolve(tLogRecord.User.User); ok && uid > 0 {
+			userID = uid
+		}
+	}
 	if userID == 0 && len(t.anonymousUsers) > 0 {
 		userID = t.anonymousUsers[0]
 	}
@@ -70,9 +79,13 @@ func (t *Transformer) Preprocess(
 	return []storage.InputRecord{rec}, nil
 }
 
+// NewTransformer creates a new Transformer for one of the Shiny apps.
+// The userResolver is used for records without user ID (use
+// users.EmptyUserMap() if there is no source of users).
 func NewTransformer(
 	appType string,
 	anonymousUsers []int,
+	userResolver users.IDResolver,
 ) *Transformer {
 	if appType != storage.AppTypeAkalex && appType != storage.AppTypeCalc &&
 		appType != storage.AppTypeGramatikat && appType != storage.AppTypeQuitaUp &&
@@ -81,6 +94,7 @@ func NewTransformer(
 	}
 	return &Transformer{
 		appType:        appType,
+		users:          userResolver,
 		anonymousUsers: anonymousUsers,
 	}
 }
@@ -28,7 +28,7 @@ import (
 
 // Transformer converts a source log object into a destination one
 type Transformer struct {
-	userMap        *users.UserMap
+	users          users.IDResolver
 	anonymousUsers []int
 }
 
@@ -46,8 +46,8 @@ func (t *Transformer) Transform(
 	}
 	userID := -1
 	if tLogRecord.User != "-" && tLogRecord.User != "" {
-		uid := t.userMap.GetIdOf(tLogRecord.User)
-		if uid < 0 {
+		uid, ok := t.users.Resolve(tLogRecord.User)
+		if !ok {
 			return nil, fmt.Errorf("failed to find user ID of [%s]", tLogRecord.User)
 		}
 		userID = uid
@@ -83,16 +83,15 @@ func (t *Transformer) Preprocess(
 }
 
 // NewTransformer is a default constructor for the Transformer.
-// It also loads user ID map from a configured file (if exists).
-// Note: Due to the fact that SkE is no more used in the CNC,
-// UserMap loading was removed from Klogproc. In case it will
-// be needed in the future, a custom Lua script should be used
-// for this (users.UserMap has a Lua interface).
+// SkE logs usernames so a resolver is needed to obtain user IDs
+// (see users.NewResolver). If there is no source of users,
+// users.EmptyUserMap() should be used.
 func NewTransformer(
 	anonymousUsers []int,
+	userResolver users.IDResolver,
 ) *Transformer {
 	return &Transformer{
-		userMap:        users.EmptyUserMap(),
+		users:          userResolver,
 		anonymousUsers: anonymousUsers,
 	}
 }
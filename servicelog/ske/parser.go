@@ -40,6 +40,12 @@ type LineParser struct {
 	parser accesslog.LineParser
 }
 
+// NewLineParser creates a parser using the provided access log parser
+// (which allows for custom access log formats)
+func NewLineParser(parser accesslog.LineParser) *LineParser {
+	return &LineParser{parser: parser}
+}
+
 // ParseLine parses a HTTP access log format line
 func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 	parsed, err := lp.parser.ParseLine(s, lineNum)
@@ -60,8 +66,8 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 		Datetime:      parsed.Datetime,
 		Request: Request{
 			HTTPUserAgent:  parsed.UserAgent,
-			HTTPRemoteAddr: parsed.IPAddress,
-			RemoteAddr:     parsed.IPAddress, // TODO the same stuff as above?
+			HTTPRemoteAddr: parsed.ClientIP(),
+			RemoteAddr:     parsed.ClientIP(), // TODO the same stuff as above?
 		},
 		ProcTime: parsed.ProcTime,
 	}
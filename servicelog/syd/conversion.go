@@ -20,15 +20,39 @@ import (
 	"fmt"
 	"strconv"
 
+	"klogproc/corpora"
+
 	"github.com/czcorpus/klogproc-core/storage"
 	sydCore "github.com/czcorpus/klogproc-core/storage/syd"
 )
 
+const (
+	// CorporaSync is a corpora configuration category for
+	// the synchronic tool (Ltool = "S")
+	CorporaSync = "sync"
+
+	// CorporaDia is a corpora configuration category for
+	// the diachronic tool (Ltool = "D")
+	CorporaDia = "dia"
+)
+
+var corpora01 = corpora.Conf{
+	CorporaSync: {{Corpora: []string{"syn2010", "oral_v2", "ksk-dopisy"}}},
+	CorporaDia:  {{Corpora: []string{"diakon"}}},
+}
+
+// defaultCorpora contains corpora used in case they are
+// not configured explicitly (an empty version is the same
+// as 0.1)
+var defaultCorpora = map[string]corpora.Conf{
+	"":    corpora01,
+	"0.1": corpora01,
+}
+
 // Transformer converts a SyD log record to a destination format
 type Transformer struct {
 	version        string
-	syncCorpora    []string
-	diaCorpora     []string
+	corpora        *corpora.Assignments
 	anonymousUsers []int
 }
 
@@ -68,10 +92,10 @@ func (t *Transformer) Transform(
 	r.SetTime(tLogRecord.GetTime())
 	r.ID = r.GenerateDeterministicID()
 	if tLogRecord.Ltool == "S" {
-		r.Corpus = t.syncCorpora
+		r.Corpus = t.corpora.Get(CorporaSync, tLogRecord.GetTime())
 
 	} else if tLogRecord.Ltool == "D" {
-		r.Corpus = t.diaCorpora
+		r.Corpus = t.corpora.Get(CorporaDia, tLogRecord.GetTime())
 	}
 	return r, nil
 }
@@ -86,20 +110,29 @@ func (t *Transformer) Preprocess(
 }
 
 // NewTransformer is a recommended factory for new Transformer instances
-// to reflect the version properly
+// to reflect the version properly. The corporaConf may be nil in which
+// case version's default corpora are used (versions without defaults
+// require the configuration).
 func NewTransformer(
 	version string,
+	corporaConf corpora.Conf,
 	anonymousUsers []int,
-) *Transformer {
-	switch version {
-	case "0.1":
-		return &Transformer{
-			version:        version,
-			syncCorpora:    []string{"syn2010", "oral_v2", "ksk-dopisy"},
-			diaCorpora:     []string{"diakon"},
-			anonymousUsers: anonymousUsers,
+) (*Transformer, error) {
+	if corporaConf == nil {
+		var ok bool
+		corporaConf, ok = defaultCorpora[version]
+		if !ok {
+			return nil, fmt.Errorf(
+				"failed to create SyD transformer: no default corpora for version %s, configure `corpora`", version)
 		}
-	default:
-		return nil
 	}
+	assignments, err := corpora.NewAssignments(corporaConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SyD transformer: %w", err)
+	}
+	return &Transformer{
+		version:        version,
+		corpora:        assignments,
+		anonymousUsers: anonymousUsers,
+	}, nil
 }
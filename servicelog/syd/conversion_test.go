@@ -19,12 +19,15 @@ package syd
 import (
 	"testing"
 
+	"klogproc/corpora"
+
 	sydCore "github.com/czcorpus/klogproc-core/storage/syd"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestTransformDia(t *testing.T) {
-	tmr := NewTransformer("0.1", []int{0, 1})
+	tmr, err := NewTransformer("0.1", nil, []int{0, 1})
+	assert.NoError(t, err)
 	rec := &InputRecord{
 		UserID: "30",
 		Ltool:  "D",
@@ -38,7 +41,8 @@ func TestTransformDia(t *testing.T) {
 }
 
 func TestTransformSync(t *testing.T) {
-	tmr := NewTransformer("0.1", []int{0, 1})
+	tmr, err := NewTransformer("0.1", nil, []int{0, 1})
+	assert.NoError(t, err)
 	rec := &InputRecord{
 		UserID: "30",
 		Ltool:  "S",
@@ -54,7 +58,8 @@ func TestTransformSync(t *testing.T) {
 }
 
 func TestAcceptsDashAsUserID(t *testing.T) {
-	tmr := NewTransformer("0.1", []int{0, 1})
+	tmr, err := NewTransformer("0.1", nil, []int{0, 1})
+	assert.NoError(t, err)
 	rec := &InputRecord{
 		UserID: "-",
 	}
@@ -66,7 +71,8 @@ func TestAcceptsDashAsUserID(t *testing.T) {
 }
 
 func TestAnonymousUserDetection(t *testing.T) {
-	tmr := NewTransformer("0.1", []int{26, 27})
+	tmr, err := NewTransformer("0.1", nil, []int{26, 27})
+	assert.NoError(t, err)
 
 	rec := &InputRecord{
 		UserID: "27",
@@ -86,3 +92,45 @@ func TestAnonymousUserDetection(t *testing.T) {
 	assert.True(t, ok)
 	assert.False(t, tOutRec.IsAnonymous)
 }
+
+func TestTransformConfiguredCorpora(t *testing.T) {
+	tmr, err := NewTransformer(
+		"0.2",
+		corpora.Conf{
+			CorporaSync: {
+				{To: "2025-01-01", Corpora: []string{"syn2010"}},
+				{From: "2025-01-01", Corpora: []string{"syn2020", "oral_v2"}},
+			},
+		},
+		[]int{0, 1},
+	)
+	assert.NoError(t, err)
+	outRec, err := tmr.Transform(&InputRecord{
+		Datetime: "2024-12-31T23:59:59+00:00",
+		UserID:   "30",
+		Ltool:    "S",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"syn2010"}, outRec.(*sydCore.OutputRecord).Corpus)
+
+	outRec, err = tmr.Transform(&InputRecord{
+		Datetime: "2025-01-01T01:00:00+00:00",
+		UserID:   "30",
+		Ltool:    "S",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"syn2020", "oral_v2"}, outRec.(*sydCore.OutputRecord).Corpus)
+
+	outRec, err = tmr.Transform(&InputRecord{
+		Datetime: "2025-01-01T01:00:00+00:00",
+		UserID:   "30",
+		Ltool:    "D",
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, outRec.(*sydCore.OutputRecord).Corpus)
+}
+
+func TestUnknownVersionRequiresConfiguredCorpora(t *testing.T) {
+	_, err := NewTransformer("0.2", nil, []int{0, 1})
+	assert.Error(t, err)
+}
@@ -66,3 +66,11 @@ func (rec *InputRecord) IsProcessable() bool {
 func (rec *InputRecord) IsSuspicious() bool {
 	return false
 }
+
+// GetUserID returns an ID of the user (if known)
+func (rec *InputRecord) GetUserID() string {
+	if rec.UserID == "-" {
+		return ""
+	}
+	return rec.UserID
+}
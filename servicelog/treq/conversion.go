@@ -19,6 +19,9 @@ package treq
 import (
 	"fmt"
 	"strconv"
+	"strings"
+
+	"klogproc/corpora"
 
 	"github.com/czcorpus/klogproc-core/storage"
 	treqCore "github.com/czcorpus/klogproc-core/storage/treq"
@@ -27,11 +30,33 @@ import (
 const (
 	qTypeD = "D"
 	qTypeL = "L"
+
+	// CorporaDefault is a corpora configuration category for all
+	// the queries. Only the first corpus of a period is used and
+	// the "{lang}" placeholder is replaced by the query language.
+	CorporaDefault = "default"
+
+	corpusLangPlaceholder = "{lang}"
 )
 
+// defaultCorpora contains corpora used in case they are
+// not configured explicitly
+var defaultCorpora = corpora.Conf{
+	CorporaDefault: {{Corpora: []string{"intercorp_v8_" + corpusLangPlaceholder}}},
+}
+
 // Transformer converts a Treq log record to a destination format
 type Transformer struct {
 	AnonymousUsers []int
+	corpora        *corpora.Assignments
+}
+
+func (t *Transformer) corpusName(rec *InputRecord) string {
+	corps := t.corpora.Get(CorporaDefault, rec.GetTime())
+	if len(corps) == 0 {
+		return ""
+	}
+	return strings.ReplaceAll(corps[0], corpusLangPlaceholder, rec.QLang)
 }
 
 func (t *Transformer) AppType() string {
@@ -91,7 +116,7 @@ func (t *Transformer) Transform(
 	out.SetTime(tLogRecord.GetTime())
 	out.ID = out.GenerateDeterministicID()
 	if tLogRecord.QType == qTypeD {
-		out.Corpus = fmt.Sprintf("intercorp_v8_%s", tLogRecord.QLang)
+		out.Corpus = t.corpusName(tLogRecord)
 		out.IsQuery = true
 	}
 	return out, nil
@@ -106,3 +131,19 @@ func (t *Transformer) Preprocess(
 ) ([]storage.InputRecord, error) {
 	return []storage.InputRecord{rec}, nil
 }
+
+// NewTransformer creates a new Transformer instance. The corporaConf
+// may be nil in which case default corpora are used.
+func NewTransformer(corporaConf corpora.Conf, anonymousUsers []int) (*Transformer, error) {
+	if corporaConf == nil {
+		corporaConf = defaultCorpora
+	}
+	assignments, err := corpora.NewAssignments(corporaConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Treq transformer: %w", err)
+	}
+	return &Transformer{
+		AnonymousUsers: anonymousUsers,
+		corpora:        assignments,
+	}, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package treq
+
+import (
+	"testing"
+
+	"klogproc/corpora"
+
+	treqCore "github.com/czcorpus/klogproc-core/storage/treq"
+	"github.com/stretchr/testify/assert"
+)
+
+func createRecord(datetime string) *InputRecord {
+	return &InputRecord{
+		Datetime:    datetime,
+		UserID:      "1531",
+		QType:       qTypeD,
+		QLang:       "cs",
+		SecondLang:  "en",
+		IsLemma:     "1",
+		IsMultiWord: "0",
+		IsRegexp:    "0",
+		IsCaseInsen: "1",
+	}
+}
+
+func TestTransformDefaultCorpus(t *testing.T) {
+	tmr, err := NewTransformer(nil, []int{0, 1})
+	assert.NoError(t, err)
+	outRec, err := tmr.Transform(createRecord("2019-07-24T11:52:42+02:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, "intercorp_v8_cs", outRec.(*treqCore.OutputRecord).Corpus)
+}
+
+func TestTransformConfiguredCorpus(t *testing.T) {
+	tmr, err := NewTransformer(
+		corpora.Conf{
+			CorporaDefault: {
+				{To: "2024-03-01", Corpora: []string{"intercorp_v8_{lang}"}},
+				{From: "2024-03-01", Corpora: []string{"intercorp_v16ud_{lang}"}},
+			},
+		},
+		[]int{0, 1},
+	)
+	assert.NoError(t, err)
+	outRec, err := tmr.Transform(createRecord("2024-02-29T23:00:00+00:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, "intercorp_v8_cs", outRec.(*treqCore.OutputRecord).Corpus)
+
+	outRec, err = tmr.Transform(createRecord("2024-03-01T00:00:00+00:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, "intercorp_v16ud_cs", outRec.(*treqCore.OutputRecord).Corpus)
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wag06
+
+import (
+	"fmt"
+	"klogproc/apps"
+
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+func init() {
+	apps.Register(apps.App{
+		Type:    storage.AppTypeWag,
+		Version: storage.AppVersionWag06,
+		NewParser: func(env apps.Env) (storage.LineParser, error) {
+			alp, err := env.AccessLogParser()
+			if err != nil {
+				return nil, fmt.Errorf("cannot create parser for %s: %w", storage.AppTypeWag, err)
+			}
+			return apps.WrapParser(NewLineParser(alp)), nil
+		},
+		NewTransformer: func(env apps.Env) (storage.LogItemTransformer, error) {
+			return &Transformer{}, nil
+		},
+		NewInputRecord:  func() storage.InputRecord { return &InputRecord{} },
+		NewOutputRecord: func() storage.OutputRecord { return &OutputRecord{} },
+	})
+}
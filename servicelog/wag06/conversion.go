@@ -19,6 +19,8 @@ package wag06
 import (
 	"net/url"
 
+	"klogproc/load/accesslog"
+
 	"github.com/czcorpus/klogproc-core/storage"
 	wag06Core "github.com/czcorpus/klogproc-core/storage/wag06"
 	"github.com/rs/zerolog/log"
@@ -53,21 +55,25 @@ func (t *Transformer) Transform(
 	if !ok {
 		panic(storage.ErrFailedTypeAssertion)
 	}
-	r := &wag06Core.OutputRecord{
-		Type:                t.AppType(),
-		IPAddress:           tLogRecord.Request.RemoteAddr,
-		UserAgent:           tLogRecord.Request.HTTPUserAgent,
-		ReferringDomain:     domainFromURL(tLogRecord.Request.Referer),
-		IsAnonymous:         true, // from a web access log, we cannot extract the information
-		IsQuery:             isQuery(tLogRecord.Action),
-		IsMobileClient:      tLogRecord.IsMobileClient,
-		HasPosSpecification: tLogRecord.HasPosSpecification,
-		QueryType:           tLogRecord.QueryType,
-		Lang1:               tLogRecord.Lang1,
-		Lang2:               tLogRecord.Lang2,
-		Queries:             tLogRecord.Queries,
-		Action:              tLogRecord.Action,
-		ProcTime:            tLogRecord.ProcTime,
+	r := &OutputRecord{
+		OutputRecord: wag06Core.OutputRecord{
+			Type:                t.AppType(),
+			IPAddress:           tLogRecord.Request.RemoteAddr,
+			UserAgent:           tLogRecord.Request.HTTPUserAgent,
+			ReferringDomain:     domainFromURL(tLogRecord.Request.Referer),
+			IsAnonymous:         true, // from a web access log, we cannot extract the information
+			IsQuery:             isQuery(tLogRecord.Action) && accesslog.IsSuccessStatus(tLogRecord.Status),
+			IsMobileClient:      tLogRecord.IsMobileClient,
+			HasPosSpecification: tLogRecord.HasPosSpecification,
+			QueryType:           tLogRecord.QueryType,
+			Lang1:               tLogRecord.Lang1,
+			Lang2:               tLogRecord.Lang2,
+			Queries:             tLogRecord.Queries,
+			Action:              tLogRecord.Action,
+			ProcTime:            tLogRecord.ProcTime,
+		},
+		Status:   tLogRecord.Status,
+		BodySize: tLogRecord.BodySize,
 	}
 	r.SetTime(tLogRecord.GetTime())
 	r.ID = r.GenerateDeterministicID()
@@ -43,6 +43,8 @@ type InputRecord struct {
 	Datetime            string
 	Request             Request
 	ProcTime            float64
+	Status              int
+	BodySize            int64
 	isProcessable       bool
 	IsMobileClient      bool
 	HasPosSpecification bool
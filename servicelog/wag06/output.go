@@ -0,0 +1,50 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wag06
+
+import (
+	"encoding/json"
+	"fmt"
+
+	wag06Core "github.com/czcorpus/klogproc-core/storage/wag06"
+)
+
+// OutputRecord extends the core WaG 0.6 output record with the status
+// and the body size of the HTTP response as found in the access log
+type OutputRecord struct {
+	wag06Core.OutputRecord
+	Status   int   `json:"status,omitempty"`
+	BodySize int64 `json:"bodySize,omitempty"`
+
+	// SamplingRate is set in the sampling mode (see SetSamplingRate)
+	SamplingRate float64 `json:"samplingRate,omitempty"`
+}
+
+// SetSamplingRate sets a rate of the sample the record belongs to
+// (see load/sampling)
+func (r *OutputRecord) SetSamplingRate(rate float64) {
+	r.SamplingRate = rate
+}
+
+// ToJSON exports the record to JSON including the response properties
+func (r *OutputRecord) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export wag06 output record: %w", err)
+	}
+	return data, nil
+}
@@ -120,6 +120,12 @@ type LineParser struct {
 	parser accesslog.LineParser
 }
 
+// NewLineParser creates a parser using the provided access log parser
+// (which allows for custom access log formats)
+func NewLineParser(parser accesslog.LineParser) *LineParser {
+	return &LineParser{parser: parser}
+}
+
 // ParseLine parses a HTTP access log format line
 func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 	parsed, err := lp.parser.ParseLine(s, lineNum)
@@ -139,11 +145,13 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 		Datetime:      parsed.Datetime,
 		Request: Request{
 			HTTPUserAgent:  parsed.UserAgent,
-			HTTPRemoteAddr: parsed.IPAddress,
-			RemoteAddr:     parsed.IPAddress, // TODO the same stuff as above?
+			HTTPRemoteAddr: parsed.ClientIP(),
+			RemoteAddr:     parsed.ClientIP(), // TODO the same stuff as above?
 			Referer:        parsed.Referrer,
 		},
 		ProcTime:            parsed.ProcTime,
+		Status:              parsed.Status,
+		BodySize:            parsed.BodySize,
 		QueryType:           action.action, // for legacy reasons (otherwise it is redundant)
 		Lang1:               action.lang1,
 		Lang2:               action.lang2,
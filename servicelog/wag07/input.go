@@ -18,6 +18,7 @@ package wag07
 
 import (
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/czcorpus/klogproc-core/storage"
@@ -96,3 +97,8 @@ func (r *InputRecord) IsProcessable() bool {
 func (rec *InputRecord) IsSuspicious() bool {
 	return rec.IsQuery && !rec.HasMatch
 }
+
+// GetUserID returns an ID of the user (if known)
+func (r *InputRecord) GetUserID() string {
+	return strconv.Itoa(r.UserID)
+}
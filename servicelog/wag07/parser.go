@@ -19,6 +19,7 @@ package wag07
 import (
 	"encoding/json"
 
+	"github.com/czcorpus/klogproc-core/storage"
 	"github.com/rs/zerolog/log"
 )
 
@@ -58,3 +59,10 @@ func (lp *LineParser) ParseLine(s string, lineNum int64) (*InputRecord, error) {
 	}
 	return &record, nil
 }
+
+// recognizes tests whether a parsed record comes from a JSON log line
+// (the parser accepts also lines which are not JSON at all)
+func recognizes(rec storage.InputRecord) bool {
+	tRec, ok := rec.(*InputRecord)
+	return ok && tRec.Timestamp != ""
+}
@@ -3,55 +3,34 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"klogproc/servicelog/apiguard"
-	"klogproc/servicelog/kontext013"
-	"klogproc/servicelog/kontext015"
-	"klogproc/servicelog/kontext018"
-	"klogproc/servicelog/korpusdb"
-	"klogproc/servicelog/kwords"
-	"klogproc/servicelog/kwords2"
-	"klogproc/servicelog/mapka"
-	"klogproc/servicelog/mapka2"
-	"klogproc/servicelog/mapka3"
-	"klogproc/servicelog/masm"
-	"klogproc/servicelog/morfio"
-	"klogproc/servicelog/mquery"
-	"klogproc/servicelog/mquerysru"
-	"klogproc/servicelog/shiny"
-	"klogproc/servicelog/ske"
-	"klogproc/servicelog/syd"
-	"klogproc/servicelog/treq"
-	"klogproc/servicelog/vlo"
-	"klogproc/servicelog/wag06"
-	"klogproc/servicelog/wag07"
-	"klogproc/servicelog/wsserver"
+	"io"
+	"klogproc/apps"
+	"klogproc/luadefs"
+	"klogproc/servicelog/custom"
+	"os"
 	"reflect"
 	"text/template"
 
-	apiguardCore "github.com/czcorpus/klogproc-core/storage/apiguard"
-	k013Core "github.com/czcorpus/klogproc-core/storage/kontext013"
-	k015Core "github.com/czcorpus/klogproc-core/storage/kontext015"
-	kdbCore "github.com/czcorpus/klogproc-core/storage/korpusdb"
-	kwordsCore "github.com/czcorpus/klogproc-core/storage/kwords"
-	kwords2Core "github.com/czcorpus/klogproc-core/storage/kwords2"
-	mapkaCore "github.com/czcorpus/klogproc-core/storage/mapka"
-	mapka2Core "github.com/czcorpus/klogproc-core/storage/mapka2"
-	mapka3Core "github.com/czcorpus/klogproc-core/storage/mapka3"
-	masmCore "github.com/czcorpus/klogproc-core/storage/masm"
-	morfioCore "github.com/czcorpus/klogproc-core/storage/morfio"
-	mqueryCore "github.com/czcorpus/klogproc-core/storage/mquery"
-	mquerySRUCore "github.com/czcorpus/klogproc-core/storage/mquerysru"
-	shinyCore "github.com/czcorpus/klogproc-core/storage/shiny"
-	skeCore "github.com/czcorpus/klogproc-core/storage/ske"
-	sydCore "github.com/czcorpus/klogproc-core/storage/syd"
-	treqCore "github.com/czcorpus/klogproc-core/storage/treq"
-	vloCore "github.com/czcorpus/klogproc-core/storage/vlo"
-	wag06Core "github.com/czcorpus/klogproc-core/storage/wag06"
-	wsserverCore "github.com/czcorpus/klogproc-core/storage/wsserver"
-
 	"github.com/czcorpus/klogproc-core/storage"
 )
 
+// customParseLineStub is appended to stubs of the `custom` app type
+// as such applications must define their own line parser
+const customParseLineStub = `
+-- parse_line function parses a raw log line and returns a table
+-- with record properties (or nil to ignore the line). Known keys:
+-- time (ISO 8601 string or UNIX time; required), ip, user_agent,
+-- user_id, is_query, ignore, suspicious. Other keys are available
+-- in input_rec.Fields.
+function parse_line(line)
+    local ts, ip, path = string.match(line, "^(%S+) (%S+) (%S+)")
+    if ts == nil then
+        return nil
+    end
+    return {time = ts, ip = ip, path = path}
+end
+`
+
 type FieldInfo struct {
 	Name        string
 	Type        string
@@ -188,7 +167,6 @@ if record_prop_exists(any_rec, name, value)
 
 For debugging, use:
 
-dump = require('dump')
 print(dump(my_value))
 
 To generate a deterministic ID:
@@ -262,95 +240,53 @@ end
 	return buf.String(), nil
 }
 
+// getRecordTypes returns empty input and output records
+// of a respective application type and version
+func getRecordTypes(appType, version string) (storage.InputRecord, storage.OutputRecord, error) {
+	app, err := apps.Get(appType, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !app.HasRecordTypes() {
+		return nil, nil, fmt.Errorf("no record types defined for %s", app)
+	}
+	return app.NewInputRecord(), app.NewOutputRecord(), nil
+}
+
 func generateLuaStub(appType, version string) error {
-	var src string
-	var err error
-	switch appType {
-	case storage.AppTypeAkalex:
-		src, err = generateLuaStubForType(&shiny.InputRecord{}, &shinyCore.OutputRecord{})
-	case storage.AppTypeAPIGuard:
-		src, err = generateLuaStubForType(&apiguard.InputRecord{}, &apiguardCore.OutputRecord{})
-	case storage.AppTypeCalc:
-		src, err = generateLuaStubForType(&shiny.InputRecord{}, &shinyCore.OutputRecord{})
-	case storage.AppTypeGramatikat:
-		src, err = generateLuaStubForType(&shiny.InputRecord{}, &shinyCore.OutputRecord{})
-	case storage.AppTypeKontext:
-		switch version {
-		case storage.AppVersionKontext013,
-			storage.AppVersionKontext014:
-			src, err = generateLuaStubForType(&kontext013.InputRecord{}, &k013Core.OutputRecord{})
-		case storage.AppVersionKontext015,
-			storage.AppVersionKontext016,
-			storage.AppVersionKontext017,
-			storage.AppVersionKontext017API,
-			storage.AppVersionKontext018:
-			src, err = generateLuaStubForType(&kontext015.InputRecord{}, &k015Core.OutputRecord{})
-		case "018":
-			src, err = generateLuaStubForType(&kontext018.InputRecord{}, &k015Core.OutputRecord{})
-		default:
-			return fmt.Errorf("failed to create Lua script stub for 'kontext': unknown version '%s'", version)
-		}
-	case storage.AppTypeKorpusDB:
-		src, err = generateLuaStubForType(&korpusdb.InputRecord{}, &kdbCore.OutputRecord{})
-	case storage.AppTypeKwords:
-		switch version {
-		case "1":
-			src, err = generateLuaStubForType(&kwords.InputRecord{}, &kwordsCore.OutputRecord{})
-		case "2":
-			src, err = generateLuaStubForType(&kwords2.InputRecord{}, &kwords2Core.OutputRecord{})
-		default:
-			return fmt.Errorf("failed to create Lua script stub for 'kwords': unknown version '%s'", version)
-		}
-	case storage.AppTypeLists:
-		src, err = generateLuaStubForType(&shiny.InputRecord{}, &shinyCore.OutputRecord{})
-	case storage.AppTypeMapka:
-		switch version {
-		case "1":
-			src, err = generateLuaStubForType(&mapka.InputRecord{}, &mapkaCore.OutputRecord{})
-		case "2":
-			src, err = generateLuaStubForType(&mapka2.InputRecord{}, &mapka2Core.OutputRecord{})
-		case "3":
-			src, err = generateLuaStubForType(&mapka3.InputRecord{}, &mapka3Core.OutputRecord{})
-		default:
-			return fmt.Errorf("failed to create Lua script stub for 'mapka': unknown version '%s'", version)
-		}
-	case storage.AppTypeMorfio:
-		src, err = generateLuaStubForType(&morfio.InputRecord{}, &morfioCore.OutputRecord{})
-	case storage.AppTypeQuitaUp:
-		src, err = generateLuaStubForType(&shiny.InputRecord{}, &shinyCore.OutputRecord{})
-	case storage.AppTypeSke:
-		src, err = generateLuaStubForType(&ske.InputRecord{}, &skeCore.OutputRecord{})
-	case storage.AppTypeSyd:
-		src, err = generateLuaStubForType(&syd.InputRecord{}, &sydCore.OutputRecord{})
-	case storage.AppTypeTreq:
-		src, err = generateLuaStubForType(&treq.InputRecord{}, &treqCore.OutputRecord{})
-	case storage.AppTypeWag:
-		switch version {
-		case "0.6":
-			src, err = generateLuaStubForType(&wag06.InputRecord{}, &wag06Core.OutputRecord{})
-		case "0.7":
-			src, err = generateLuaStubForType(&wag07.InputRecord{}, &wag06Core.OutputRecord{})
-		default:
-			return fmt.Errorf("failed to create Lua script stub for 'wag': unknown version '%s'", version)
-		}
-	case storage.AppTypeWsserver:
-		src, err = generateLuaStubForType(&wsserver.InputRecord{}, &wsserverCore.OutputRecord{})
-	case storage.AppTypeMasm:
-		src, err = generateLuaStubForType(&masm.InputRecord{}, &masmCore.OutputRecord{})
-	case storage.AppTypeMquery:
-		src, err = generateLuaStubForType(&mquery.InputRecord{}, &mqueryCore.OutputRecord{})
-	case storage.AppTypeMquerySRU:
-		src, err = generateLuaStubForType(&mquerysru.InputRecord{}, &mquerySRUCore.OutputRecord{})
-	case storage.AppTypeVLO:
-		src, err = generateLuaStubForType(&vlo.InputRecord{}, &vloCore.OutputRecord{})
-	default:
-		return fmt.Errorf("failed to create Lua script stub: unknown application '%s'", version)
+	inputRec, outputRec, err := getRecordTypes(appType, version)
+	if err != nil {
+		return fmt.Errorf("failed to create Lua script stub: %w", err)
 	}
+	src, err := generateLuaStubForType(inputRec, outputRec)
 	if err != nil {
 		return fmt.Errorf("failed to create Lua script stub for %s: %w", appType, err)
-
-	} else {
-		fmt.Println(src)
 	}
+	if appType == custom.AppType {
+		src += customParseLineStub
+	}
+	fmt.Println(src)
 	return nil
 }
+
+// generateLuaDefs writes LuaLS (EmmyLua) annotations for an application
+// to a file (or to stdout if outputPath is empty)
+func generateLuaDefs(appType, version, outputPath string) error {
+	inputRec, outputRec, err := getRecordTypes(appType, version)
+	if err != nil {
+		return fmt.Errorf("failed to create Lua definitions: %w", err)
+	}
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create Lua definitions: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	return luadefs.Write(
+		w, appType, version, inputRec, outputRec,
+		luadefs.Options{WithParseLine: appType == custom.AppType},
+	)
+}
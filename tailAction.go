@@ -18,17 +18,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os/signal"
 	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
 
+	"klogproc/apps"
 	"klogproc/config"
+	"klogproc/errstream"
 	"klogproc/healthchk"
 	"klogproc/load/alarm"
 	"klogproc/load/tail"
+	"klogproc/luasandbox"
 	"klogproc/notifications"
 	"klogproc/trfactory"
 
@@ -60,6 +65,7 @@ type tailProcessor struct {
 	conf              *config.Main
 	lineParser        storage.LineParser
 	logTransformer    storage.LogItemTransformer
+	scriptReloader    *trfactory.ReloadableTransformer
 	geoDB             *geoip2.Reader
 	anonymousUsers    []int
 	elasticChunkSize  int
@@ -68,9 +74,59 @@ type tailProcessor struct {
 	logBuffer         storage.ServiceLogBuffer
 	procHealthChecker processingHealthChecker
 	dryRun            bool
+
+	// errWriter writes application errors (nil if not configured)
+	errWriter *errstream.Writer
+
+	// versionMonitor watches for log format changes in case
+	// the version has been detected automatically (nil otherwise)
+	versionMonitor *trfactory.VersionMonitor
+	notifier       analysis.Notifier
+}
+
+func (tp *tailProcessor) reportVersionChange(version string) {
+	log.Error().
+		Str("appType", tp.appType).
+		Str("file", tp.filePath).
+		Str("appVersion", tp.version).
+		Str("detectedVersion", version).
+		Msg("log format seems to have changed, restart klogproc to process the log properly")
+	if tp.notifier == nil {
+		return
+	}
+	err := tp.notifier.SendNotification(
+		tp.appType,
+		fmt.Sprintf("Klogproc: log format of %s has changed", tp.appType),
+		map[string]any{
+			"appType":         tp.appType,
+			"file":            tp.filePath,
+			"appVersion":      tp.version,
+			"detectedVersion": version,
+		},
+		fmt.Sprintf(
+			"Recent lines of %s cannot be parsed as version %s but they match version %s. "+
+				"Restart klogproc to process the log properly.",
+			tp.filePath, tp.version, version,
+		),
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to send log format change notification")
+	}
 }
 
 func (tp *tailProcessor) OnCheckStart() (tail.LineProcConfirmChan, *tail.LogDataWriter) {
+	if tp.scriptReloader != nil {
+		reloaded, err := tp.scriptReloader.ReloadIfChanged()
+		if err != nil {
+			log.Error().Err(err).Str("appType", tp.appType).Msg("keeping the previous version of Lua script")
+
+		} else if reloaded {
+			log.Info().Str("appType", tp.appType).Str("file", tp.filePath).Msg("reloaded Lua script")
+		}
+	}
+	if tp.errWriter != nil {
+		tp.errWriter.Start()
+	}
 	itemConfirm := make(tail.LineProcConfirmChan, 10)
 	dataWriter := tail.LogDataWriter{
 		Elastic: make(chan *storage.BoundOutputRecord, tp.elasticChunkSize*2),
@@ -119,6 +175,18 @@ func (tp *tailProcessor) OnEntry(
 	logPosition storage.LogRange,
 ) {
 	parsed, err := tp.lineParser.ParseLine(item, -1) // TODO (line num - hard to keep track)
+	if errors.Is(err, apps.ErrParserUnavailable) {
+		// the line itself may be fine so it is not a parsing error
+		// (and it must not trigger version detection)
+		tp.alarm.OnError(fmt.Sprintf("line of %s lost: %s", tp.filePath, err))
+		dataWriter.Ignored <- save.NewIgnoredItemMsg(tp.filePath, logPosition)
+		return
+	}
+	if tp.versionMonitor != nil {
+		if version, changed := tp.versionMonitor.Register(item, parsed, err); changed {
+			tp.reportVersionChange(version)
+		}
+	}
 	if err != nil {
 		switch tErr := err.(type) {
 		case storage.LineParsingError:
@@ -137,6 +205,9 @@ func (tp *tailProcessor) OnEntry(
 				Str("appVersion", tp.version).
 				Err(err).
 				Msgf("Failed to transform item %s", parsed)
+			if luasandbox.IsLimitError(err) {
+				tp.alarm.OnError(err.Error())
+			}
 			dataWriter.Ignored <- save.NewIgnoredItemMsg(tp.filePath, logPosition)
 			return
 		}
@@ -149,6 +220,9 @@ func (tp *tailProcessor) OnEntry(
 					Str("appVersion", tp.version).
 					Err(err).
 					Msgf("Failed to transform item %s", precord)
+				if luasandbox.IsLimitError(err) {
+					tp.alarm.OnError(err.Error())
+				}
 				dataWriter.Ignored <- save.NewIgnoredItemMsg(tp.filePath, logPosition)
 				return
 			}
@@ -169,11 +243,19 @@ func (tp *tailProcessor) OnEntry(
 func (tp *tailProcessor) OnCheckStop(dataWriter *tail.LogDataWriter) {
 	close(dataWriter.Elastic)
 	close(dataWriter.Ignored)
+	if tp.errWriter != nil {
+		tp.errWriter.Stop()
+	}
 	tp.alarm.Evaluate()
 }
 
 func (tp *tailProcessor) OnQuit() {
 	tp.alarm.Reset()
+	if c, ok := tp.lineParser.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Error().Err(err).Str("appType", tp.appType).Msg("failed to close line parser")
+		}
+	}
 	if tp.analysis != nil {
 		close(tp.analysis)
 	}
@@ -229,16 +311,48 @@ func newTailProcessor(
 	if err != nil {
 		log.Fatal().Msgf("Failed to initialize alarm: %s", err)
 	}
-	lineParser, err := trfactory.NewLineParser(tailConf.AppType, tailConf.Version, procAlarm)
+	var versionMonitor *trfactory.VersionMonitor
+	if tailConf.Version == trfactory.AutoVersion {
+		versionMonitor = resolveTailVersion(tailConf)
+	}
+	var errorSink errstream.Sink = errstream.NullSink{}
+	var errWriter *errstream.Writer
+	if tailConf.ErrorStream != nil {
+		errWriter = errstream.NewWriter(
+			ctx,
+			tailConf.ErrorStream,
+			conf.ElasticSearch,
+			tailConf.AppType,
+			tailConf.Version,
+			filepath.Clean(tailConf.Path),
+			conf.TimezoneLocation(),
+			options.dryRun,
+		)
+		errorSink = errWriter
+	}
+	lineParser, err := trfactory.NewLineParser(tailConf, procAlarm, errorSink)
 	if err != nil {
 		log.Fatal().Msgf("Failed to initialize parser: %s", err)
 	}
-	logTransformer, err := trfactory.GetLogTransformer(
-		tailConf,
-		conf.AnonymousUsers,
-		true,
-		notifier,
-	)
+	var logTransformer storage.LogItemTransformer
+	var scriptReloader *trfactory.ReloadableTransformer
+	if tailConf.ScriptPath != "" {
+		scriptReloader, err = trfactory.NewReloadableTransformer(
+			tailConf,
+			conf.AnonymousUsers,
+			true,
+			notifier,
+		)
+		logTransformer = scriptReloader
+
+	} else {
+		logTransformer, err = trfactory.GetLogTransformer(
+			tailConf,
+			conf.AnonymousUsers,
+			true,
+			notifier,
+		)
+	}
 	if err != nil {
 		log.Fatal().Msgf("Failed to initialize transformer: %s", err)
 	}
@@ -323,6 +437,7 @@ func newTailProcessor(
 		conf:              &conf,
 		lineParser:        lineParser,
 		logTransformer:    logTransformer,
+		scriptReloader:    scriptReloader,
 		geoDB:             geoDB,
 		anonymousUsers:    conf.AnonymousUsers,
 		elasticChunkSize:  conf.ElasticSearch.PushChunkSize,
@@ -330,7 +445,41 @@ func newTailProcessor(
 		logBuffer:         buffStorage,
 		procHealthChecker: healthChecker,
 		dryRun:            options.dryRun,
+		errWriter:         errWriter,
+		versionMonitor:    versionMonitor,
+		notifier:          notifier,
+	}
+}
+
+// resolveTailVersion replaces the `auto` version of a watched log with
+// a version detected from the most recent lines of the log. For an empty
+// log, the newest version is used. In both cases, a monitor for detecting
+// later log format changes is returned.
+func resolveTailVersion(tailConf *tail.FileConf) *trfactory.VersionMonitor {
+	sample, err := trfactory.ReadTailSample(tailConf.Path, trfactory.DetectionSampleSize)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to detect application version")
+	}
+	var version string
+	if len(sample) == 0 {
+		versions := trfactory.DetectableVersions(tailConf.AppType)
+		version = versions[len(versions)-1]
+		log.Warn().
+			Str("appType", tailConf.AppType).
+			Str("logPath", tailConf.Path).
+			Str("version", version).
+			Msg("no lines to detect application version, using the newest one")
+
+	} else {
+		version, err = trfactory.ResolveAutoVersion(
+			tailConf.AppType, tailConf.WithVersion, sample, tailConf.Path)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to detect application version")
+		}
 	}
+	tailConf.Version = version
+	return trfactory.NewVersionMonitor(
+		tailConf.AppType, version, tailConf.WithVersion, trfactory.DetectionSampleSize)
 }
 
 // -----
@@ -0,0 +1,372 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trfactory
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"klogproc/apps"
+	"klogproc/errstream"
+	"klogproc/load/alarm"
+	"os"
+	"strings"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// AutoVersion is a special value of the `version` configuration
+	// property which makes klogproc detect the version from log contents
+	AutoVersion = "auto"
+
+	// DetectionSampleSize specifies how many log lines are used
+	// to detect a version
+	DetectionSampleSize = 100
+
+	// maxTailSampleBytes limits how much data is read from the end
+	// of a log file when sampling its most recent lines
+	maxTailSampleBytes = 1024 * 1024
+)
+
+// detectableApps returns versions of appType (from the oldest to the newest)
+// which can be detected automatically
+func detectableApps(appType string) []apps.App {
+	var ans []apps.App
+	for _, app := range apps.OfType(appType) {
+		if app.NewParser != nil && !app.NoDetection {
+			ans = append(ans, app)
+		}
+	}
+	if len(ans) < 2 {
+		return nil
+	}
+	return ans
+}
+
+// DetectableVersions returns versions of appType (from the oldest to the newest)
+// which can be detected automatically. For app types without versions, nil
+// is returned. Some versions share the same parser and thus cannot be
+// distinguished - in such case, the newest one is selected by DetectVersion.
+func DetectableVersions(appType string) []string {
+	var ans []string
+	for _, app := range detectableApps(appType) {
+		ans = append(ans, app.Version)
+	}
+	return ans
+}
+
+// ConfForVersion creates a copy of a log configuration with a specified
+// version. It allows for testing different versions without losing
+// other (app type specific) configuration.
+type ConfForVersion func(version string) storage.LogProcConf
+
+// VersionScore describes how a parser of a version handles a sample of lines
+type VersionScore struct {
+	Version string
+
+	// Parsed is the number of lines parsed without errors
+	Parsed int
+
+	// Recognized is the number of parsed lines with a record shape
+	// specific to the version (see apps.App.Recognizes)
+	Recognized int
+
+	// Processable is the number of parsed lines which
+	// would be processed further
+	Processable int
+
+	// family identifies versions sharing the same parser (and input
+	// records) which therefore cannot be distinguished
+	family string
+}
+
+func (vs VersionScore) isBetterThan(other VersionScore) bool {
+	if vs.Parsed != other.Parsed {
+		return vs.Parsed > other.Parsed
+	}
+	if vs.Recognized != other.Recognized {
+		return vs.Recognized > other.Recognized
+	}
+	return vs.Processable > other.Processable
+}
+
+func (vs VersionScore) String() string {
+	return fmt.Sprintf(
+		"%s: %d parsed, %d recognized, %d processable",
+		vs.Version, vs.Parsed, vs.Recognized, vs.Processable)
+}
+
+// scoreVersion runs a parser of app configured by conf on all the sample lines
+func scoreVersion(app apps.App, conf storage.LogProcConf, sample []string) (ans VersionScore, err error) {
+	ans.Version = app.Version
+	if app.NewInputRecord != nil {
+		ans.family = fmt.Sprintf("%T", app.NewInputRecord())
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parser panicked: %v", r)
+		}
+	}()
+	lp, err := NewLineParser(conf, &alarm.NullAlarm{}, errstream.NullSink{})
+	if err != nil {
+		return
+	}
+	for i, line := range sample {
+		rec, pErr := lp.ParseLine(line, int64(i))
+		if pErr != nil || rec == nil {
+			continue
+		}
+		ans.Parsed++
+		if app.Recognizes == nil || app.Recognizes(rec) {
+			ans.Recognized++
+		}
+		if rec.IsProcessable() {
+			ans.Processable++
+		}
+	}
+	return
+}
+
+// DetectVersion tries parsers of all the detectable versions of appType
+// on provided sample lines and returns the version which is able to parse
+// (and recognize) most of them. For equal results of versions sharing
+// the same parser, the newer version is preferred. Equal results of versions
+// with different parsers mean the sample is ambiguous and an error is returned.
+// The returned scores are in the same order as DetectableVersions.
+func DetectVersion(
+	appType string,
+	confForVersion ConfForVersion,
+	sample []string,
+) (string, []VersionScore, error) {
+	versions := detectableApps(appType)
+	if len(versions) == 0 {
+		return "", nil, fmt.Errorf("version detection not supported for %s", appType)
+	}
+	scores := make([]VersionScore, 0, len(versions))
+	var best *VersionScore
+	var tied []string
+	for _, app := range versions {
+		score, err := scoreVersion(app, confForVersion(app.Version), sample)
+		if err != nil {
+			log.Debug().Err(err).Str("appType", appType).Str("version", app.Version).Msg("skipping version in detection")
+			continue
+		}
+		scores = append(scores, score)
+		if best == nil || score.isBetterThan(*best) {
+			best = &scores[len(scores)-1]
+			tied = tied[:0]
+
+		} else if !best.isBetterThan(score) {
+			if score.family != best.family {
+				tied = append(tied, best.Version)
+			}
+			best = &scores[len(scores)-1]
+		}
+	}
+	if best == nil || best.Parsed == 0 {
+		return "", scores, fmt.Errorf("no version of %s is able to parse the sample", appType)
+	}
+	if len(tied) > 0 {
+		return "", scores, fmt.Errorf(
+			"ambiguous sample - versions %s and %s of %s score the same",
+			strings.Join(tied, ", "), best.Version, appType)
+	}
+	return best.Version, scores, nil
+}
+
+// ResolveAutoVersion detects a version of appType based on a sample
+// of lines and logs the result along with scores of all the versions
+func ResolveAutoVersion(
+	appType string,
+	confForVersion ConfForVersion,
+	sample []string,
+	logPath string,
+) (string, error) {
+	version, scores, err := DetectVersion(appType, confForVersion, sample)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect version of %s: %w", logPath, err)
+	}
+	strScores := make([]string, len(scores))
+	for i, s := range scores {
+		strScores[i] = s.String()
+	}
+	log.Info().
+		Str("appType", appType).
+		Str("logPath", logPath).
+		Str("version", version).
+		Int("sampleSize", len(sample)).
+		Strs("scores", strScores).
+		Msg("detected application version")
+	return version, nil
+}
+
+// readSampleLines reads non-empty lines from r. With maxLines > 0,
+// reading stops once the number of lines is reached.
+func readSampleLines(r io.Reader, skipFirst bool, maxLines int) ([]string, error) {
+	ans := make([]string, 0, DetectionSampleSize)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxTailSampleBytes)
+	for sc.Scan() && (maxLines <= 0 || len(ans) < maxLines) {
+		if skipFirst {
+			skipFirst = false
+			continue
+		}
+		if strings.TrimSpace(sc.Text()) != "" {
+			ans = append(ans, sc.Text())
+		}
+	}
+	return ans, sc.Err()
+}
+
+// ReadHeadSample reads first (non-empty) lines of a log file
+// to be used for version detection
+func ReadHeadSample(path string, numLines int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample lines: %w", err)
+	}
+	defer f.Close()
+	ans, err := readSampleLines(f, false, numLines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample lines: %w", err)
+	}
+	return ans, nil
+}
+
+// ReadTailSample reads last (non-empty) lines of a log file
+// to be used for version detection. This is preferred for
+// watched logs where the most recent lines matter.
+func ReadTailSample(path string, numLines int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample lines: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample lines: %w", err)
+	}
+	var offset int64
+	if info.Size() > maxTailSampleBytes {
+		offset = info.Size() - maxTailSampleBytes
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to read sample lines: %w", err)
+		}
+	}
+	// with a non-zero offset, the first line is most likely incomplete
+	ans, err := readSampleLines(f, offset > 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample lines: %w", err)
+	}
+	if len(ans) > numLines {
+		ans = ans[len(ans)-numLines:]
+	}
+	return ans, nil
+}
+
+// -----
+
+// VersionMonitor watches parsing of a log with an automatically detected
+// version and tests whether the log format has changed (e.g. due to
+// an application upgrade).
+type VersionMonitor struct {
+	appType        string
+	version        string
+	confForVersion ConfForVersion
+	sampleSize     int
+
+	// recognizes tests records of the current version
+	// (see apps.App.Recognizes), it may be nil
+	recognizes func(rec storage.InputRecord) bool
+
+	// failed contains recent consecutive lines which failed to parse
+	// or which were parsed into records not matching the version
+	failed []string
+
+	// reported contains versions already reported as replacing
+	// the current one (to prevent repeated reports)
+	reported map[string]bool
+}
+
+// Version returns the version the monitored log is processed with
+func (vm *VersionMonitor) Version() string {
+	return vm.version
+}
+
+// matchesVersion tests whether a parsed record looks like a record
+// of the current version. A log of a different version may be still
+// parsed without errors but into records with missing data (e.g. KonText
+// 0.18 logs parsed as 0.17 ones) so the record shape is tested
+// (see apps.App.Recognizes). For applications without such a test,
+// processable records are considered matching.
+func (vm *VersionMonitor) matchesVersion(rec storage.InputRecord) bool {
+	if rec == nil {
+		return false
+	}
+	if vm.recognizes != nil {
+		return vm.recognizes(rec)
+	}
+	return rec.IsProcessable()
+}
+
+// Register registers a result of parsing a line. Once there is enough
+// consecutive lines which failed to parse or which do not match the current
+// version (see matchesVersion), other versions are tested on them (the same
+// way as in DetectVersion). In case some other version handles them better,
+// the version is returned along with true. Each detected version is reported
+// only once.
+func (vm *VersionMonitor) Register(line string, rec storage.InputRecord, parseErr error) (string, bool) {
+	if parseErr == nil && vm.matchesVersion(rec) {
+		vm.failed = vm.failed[:0]
+		return "", false
+	}
+	vm.failed = append(vm.failed, line)
+	if len(vm.failed) < vm.sampleSize {
+		return "", false
+	}
+	version, _, err := DetectVersion(vm.appType, vm.confForVersion, vm.failed)
+	vm.failed = vm.failed[:0]
+	if err != nil || version == vm.version || vm.reported[version] {
+		return "", false
+	}
+	vm.reported[version] = true
+	return version, true
+}
+
+// NewVersionMonitor creates a monitor for a log processed with version.
+// Sample size specifies how many consecutive failed (or not matching)
+// lines trigger detection.
+func NewVersionMonitor(
+	appType, version string,
+	confForVersion ConfForVersion,
+	sampleSize int,
+) *VersionMonitor {
+	ans := &VersionMonitor{
+		appType:        appType,
+		version:        version,
+		confForVersion: confForVersion,
+		sampleSize:     sampleSize,
+		failed:         make([]string, 0, sampleSize),
+		reported:       make(map[string]bool),
+	}
+	if app, err := apps.Get(appType, version); err == nil {
+		ans.recognizes = app.Recognizes
+	}
+	return ans
+}
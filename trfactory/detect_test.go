@@ -0,0 +1,239 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trfactory
+
+import (
+	"klogproc/errstream"
+	"klogproc/load/alarm"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// samples contains real-world like log lines of individual application versions
+var samples = map[string][]string{
+	"kontext-0.13": {
+		`2018-03-06 19:34:40,755 [QUERY] INFO: {"user_id": 4230, "proc_time": 0.5398, "pid": 46885, "request": {"HTTP_X_FORWARDED_FOR": "66.249.65.216", "HTTP_USER_AGENT": "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"}, "action": "view", "params": {"ctxattrs": "word", "attr_vmode": "visible", "pagesize": "50", "q": "~U1OTWBoC", "viewmode": "kwic", "attrs": "word", "corpname": "omezeni/syn2015", "structs": "p", "attr_allpos": "kw"}, "date": "2018-03-06 19:34:40.755029"}`,
+		`2018-03-06 22:07:57,836 [actions.concordance] ERROR: AttrNotFound (lemma)`,
+		`2018-03-06 19:35:02,101 [QUERY] INFO: {"user_id": 1, "proc_time": 0.0123, "pid": 46886, "request": {"REMOTE_ADDR": "192.168.1.10", "HTTP_USER_AGENT": "Mozilla/5.0 (X11; Linux x86_64)"}, "action": "first", "params": {"corpname": "syn2015", "queryselector": "iqueryrow", "iquery": "dům"}, "settings": {"pagesize": 20}, "date": "2018-03-06 19:35:02.101223"}`,
+	},
+	"kontext-0.15": {
+		`2021-06-01 10:00:00,123 [QUERY] INFO: {"user_id": 12, "proc_time": 0.2041, "date": "2021-06-01 10:00:00.123456", "action": "query_submit", "is_indirect_call": false, "is_api": false, "request": {"HTTP_X_FORWARDED_FOR": "195.113.53.66", "HTTP_USER_AGENT": "Mozilla/5.0 (X11; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0"}, "args": {"corpname": "syn2020", "qtype": "simple", "uses_context": 0}}`,
+		`2021-06-01 10:00:03,511 [QUERY] INFO: {"user_id": 12, "proc_time": 0.0815, "date": "2021-06-01 10:00:03.511004", "action": "view", "is_indirect_call": true, "is_api": false, "request": {"REMOTE_ADDR": "195.113.53.66", "HTTP_USER_AGENT": "Mozilla/5.0"}, "args": {"corpora": ["syn2020"]}}`,
+		`2021-06-01 10:00:05,002 [actions.concordance] ERROR: syntax error, unexpected $end near position 3`,
+	},
+	"kontext-0.18": {
+		`{"logger": "QUERY", "level": "INFO", "date": "2024-02-12T09:11:12.345678", "user_id": 12, "proc_time": 0.133, "action": "query_submit", "is_indirect_call": false, "is_api": false, "request": {"HTTP_X_FORWARDED_FOR": "195.113.53.66", "HTTP_USER_AGENT": "Mozilla/5.0"}, "args": {"corpname": "syn2020", "queries": [{"qtype": "simple", "query": "dům"}]}}`,
+		`{"logger": "actions.concordance", "level": "ERROR", "date": "2024-02-12T09:11:14.000001", "message": "failed to process query", "exception": {"id": "a1b2", "type": "CorpusNotFound", "stack": ["File \"conc.py\", line 12"]}}`,
+	},
+	"wag-0.6": {
+		`10.0.3.50 - janedoe [17/May/2021:06:36:36 +0200] "GET /slovo-v-kostce/search/cs/za%C5%A1kolit?pos=V&lemma=za%C5%A1kolit HTTP/2.0" 200 9218 "https://prirucka.ujc.cas.cz/" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.212 Safari/537.36" rt=0.465`,
+		`10.0.3.50 - - [17/May/2021:06:36:38 +0200] "GET /slovo-v-kostce/assets/main.js HTTP/2.0" 200 1024 "https://www.korpus.cz/slovo-v-kostce/" "Mozilla/5.0 (iPhone; CPU iPhone OS 14_4 like Mac OS X)" rt=0.002`,
+		`10.0.3.51 - - [17/May/2021:06:37:01 +0200] "GET /slovo-v-kostce/compare/cs/dům--byt HTTP/2.0" 200 10211 "-" "Mozilla/5.0 (X11; Linux x86_64)" rt=0.812`,
+	},
+	"wag-0.7": {
+		`{"level":"info","message":"query","userId":12,"action":"search","queryType":"single","request":{"httpForwardedFor":"195.113.53.66","userAgent":"Mozilla/5.0","origin":"https://www.korpus.cz","referer":"https://www.korpus.cz/"},"lang1":"cs","lang2":"","queries":["dům"],"time":"2023-05-10T08:01:02.123Z","isMobileClient":false,"hasMatch":true,"isQuery":true,"hasPosSpecification":false}`,
+		`{"level":"info","message":"query","userId":-1,"action":"translate","queryType":"translat","request":{"userAgent":"Mozilla/5.0 (Android 11)"},"lang1":"cs","lang2":"en","queries":["pes"],"time":"2023-05-10T08:01:09.998Z","isMobileClient":true,"hasMatch":true,"isQuery":true,"hasPosSpecification":false}`,
+	},
+	"mapka-1": {
+		`195.113.53.66 - - [10/Feb/2020:10:01:02 +0100] "GET /mapka/text/osobni/Praha HTTP/1.1" 200 5012 "https://www.korpus.cz/mapka/" "Mozilla/5.0 (X11; Linux x86_64)" rt=0.051`,
+		`195.113.53.66 - - [10/Feb/2020:10:01:05 +0100] "GET /mapka/overlay/layers+kraje.json HTTP/1.1" 200 31012 "https://www.korpus.cz/mapka/" "Mozilla/5.0 (X11; Linux x86_64)" rt=0.011`,
+		`195.113.53.67 - - [10/Feb/2020:10:02:00 +0100] "GET /mapka/ HTTP/1.1" 200 2012 "-" "Mozilla/5.0" rt=0.005`,
+	},
+	"mapka-2": {
+		`195.113.53.66 - - [12/Mar/2022:11:01:02 +0100] "GET /mapka/records_list?city=Brno HTTP/1.1" 200 8012 "https://www.korpus.cz/mapka/" "Mozilla/5.0 (X11; Linux x86_64)" rt=0.151`,
+		`195.113.53.66 - - [12/Mar/2022:11:01:07 +0100] "GET /mapka/city/Brno HTTP/1.1" 200 3012 "https://www.korpus.cz/mapka/" "Mozilla/5.0 (X11; Linux x86_64)" rt=0.021`,
+		`195.113.53.68 - - [12/Mar/2022:11:02:00 +0100] "GET /mapka/static/app.js HTTP/1.1" 200 91012 "-" "Mozilla/5.0" rt=0.001`,
+	},
+	"mapka-3": {
+		`{"message":"access","context":{"action":"records_list"},"level":200,"level_name":"INFO","access":"access","datetime":"2024-04-02T10:11:12.123456+02:00","extra":{"session_selector":"ab12","user_id":"12","url":"/mapka/records_list?city=Brno","ip":"195.113.53.66","forwarded_for":"","http_method":"GET","server":"www.korpus.cz","referrer":"https://www.korpus.cz/mapka/"}}`,
+	},
+}
+
+// testLogConf is a minimal log configuration
+// (parsers of the tested apps need just a type and a version)
+type testLogConf struct {
+	storage.LogProcConf
+	appType string
+	version string
+}
+
+func (c *testLogConf) GetAppType() string {
+	return c.appType
+}
+
+func (c *testLogConf) GetVersion() string {
+	return c.version
+}
+
+func confFor(appType string) ConfForVersion {
+	return func(version string) storage.LogProcConf {
+		return &testLogConf{appType: appType, version: version}
+	}
+}
+
+func writeLog(t *testing.T, lines ...[]string) string {
+	var data strings.Builder
+	for _, chunk := range lines {
+		for _, line := range chunk {
+			data.WriteString(line + "\n\n")
+		}
+	}
+	path := filepath.Join(t.TempDir(), "application.log")
+	if err := os.WriteFile(path, []byte(data.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDetectVersion(t *testing.T) {
+	tests := []struct {
+		sample   string
+		appType  string
+		expected string
+	}{
+		// 0.13 and 0.14 share the parser so the newer one is selected
+		{"kontext-0.13", storage.AppTypeKontext, storage.AppVersionKontext014},
+		{"kontext-0.15", storage.AppTypeKontext, storage.AppVersionKontext017},
+		{"kontext-0.18", storage.AppTypeKontext, storage.AppVersionKontext018},
+		{"wag-0.6", storage.AppTypeWag, storage.AppVersionWag06},
+		{"wag-0.7", storage.AppTypeWag, storage.AppVersionWag07},
+		{"mapka-1", storage.AppTypeMapka, storage.AppVersionMapka1},
+		{"mapka-2", storage.AppTypeMapka, storage.AppVersionMapka2},
+		{"mapka-3", storage.AppTypeMapka, storage.AppVersionMapka3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.sample, func(t *testing.T) {
+			version, scores, err := DetectVersion(tc.appType, confFor(tc.appType), samples[tc.sample])
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, version, "scores: %v", scores)
+		})
+	}
+}
+
+func TestDetectVersionAmbiguous(t *testing.T) {
+	// both 0.13 and 0.15+ parsers accept the line and there
+	// is nothing specific to any of the versions
+	sample := []string{`2018-03-06 19:34:40,755 [QUERY] INFO: {"date": "2018-03-06 19:34:40.755029"}`}
+	_, _, err := DetectVersion(storage.AppTypeKontext, confFor(storage.AppTypeKontext), sample)
+	assert.ErrorContains(t, err, "ambiguous sample")
+}
+
+func TestDetectVersionNoMatch(t *testing.T) {
+	_, _, err := DetectVersion(
+		storage.AppTypeKontext, confFor(storage.AppTypeKontext), []string{"foo", "bar"})
+	assert.Error(t, err)
+}
+
+func TestReadTailSample(t *testing.T) {
+	tests := []struct {
+		sample   string
+		previous string
+		appType  string
+		expected string
+	}{
+		{"kontext-0.18", "kontext-0.15", storage.AppTypeKontext, storage.AppVersionKontext018},
+		{"kontext-0.15", "kontext-0.13", storage.AppTypeKontext, storage.AppVersionKontext017},
+		{"wag-0.7", "wag-0.6", storage.AppTypeWag, storage.AppVersionWag07},
+		{"mapka-3", "mapka-2", storage.AppTypeMapka, storage.AppVersionMapka3},
+		{"mapka-2", "mapka-1", storage.AppTypeMapka, storage.AppVersionMapka2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.sample, func(t *testing.T) {
+			// the log has been written by an older version before an upgrade
+			path := writeLog(t, samples[tc.previous], samples[tc.sample])
+			sample, err := ReadTailSample(path, len(samples[tc.sample]))
+			assert.NoError(t, err)
+			assert.Equal(t, samples[tc.sample], sample)
+			version, _, err := DetectVersion(tc.appType, confFor(tc.appType), sample)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, version)
+		})
+	}
+}
+
+func TestReadTailSampleLargeFile(t *testing.T) {
+	line := samples["kontext-0.18"][0]
+	lines := make([]string, maxTailSampleBytes/len(line)+10)
+	for i := range lines {
+		lines[i] = line
+	}
+	path := writeLog(t, lines)
+	sample, err := ReadTailSample(path, len(lines))
+	assert.NoError(t, err)
+	// the file is read just partially and the first
+	// (most likely incomplete) line is skipped
+	assert.Less(t, len(sample), len(lines))
+	for _, s := range sample {
+		assert.Equal(t, line, s)
+	}
+}
+
+func TestVersionMonitor(t *testing.T) {
+	tests := []struct {
+		name     string
+		appType  string
+		version  string
+		sample   string
+		expected string
+	}{
+		{"kontext upgrade", storage.AppTypeKontext, storage.AppVersionKontext017, "kontext-0.18", storage.AppVersionKontext018},
+		{"kontext downgrade", storage.AppTypeKontext, storage.AppVersionKontext018, "kontext-0.15", storage.AppVersionKontext017},
+		// the 0.13 parser is able to parse 0.15 lines but the records are not recognized
+		{"kontext parsed upgrade", storage.AppTypeKontext, storage.AppVersionKontext014, "kontext-0.15", storage.AppVersionKontext017},
+		{"mapka downgrade", storage.AppTypeMapka, storage.AppVersionMapka3, "mapka-2", storage.AppVersionMapka2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := confFor(tc.appType)
+			lines := samples[tc.sample]
+			vm := NewVersionMonitor(tc.appType, tc.version, conf, len(lines))
+			lp, err := NewLineParser(conf(tc.version), &alarm.NullAlarm{}, errstream.NullSink{})
+			assert.NoError(t, err)
+			var detected []string
+			for range 2 {
+				for i, line := range lines {
+					rec, pErr := lp.ParseLine(line, int64(i))
+					if version, changed := vm.Register(line, rec, pErr); changed {
+						detected = append(detected, version)
+					}
+				}
+			}
+			// each version is reported just once
+			assert.Equal(t, []string{tc.expected}, detected)
+			assert.Equal(t, tc.version, vm.Version())
+		})
+	}
+}
+
+func TestVersionMonitorNoChange(t *testing.T) {
+	conf := confFor(storage.AppTypeWag)
+	lines := samples["wag-0.6"]
+	vm := NewVersionMonitor(storage.AppTypeWag, storage.AppVersionWag06, conf, 2)
+	lp, err := NewLineParser(conf(storage.AppVersionWag06), &alarm.NullAlarm{}, errstream.NullSink{})
+	assert.NoError(t, err)
+	for i, line := range lines {
+		rec, pErr := lp.ParseLine(line, int64(i))
+		assert.NoError(t, pErr)
+		_, changed := vm.Register(line, rec, pErr)
+		assert.False(t, changed)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trfactory
+
+import (
+	"klogproc/corpora"
+	"klogproc/errstream"
+	"klogproc/load/accesslog"
+	"klogproc/lookup"
+	"klogproc/luasandbox"
+	"klogproc/users"
+
+	"github.com/czcorpus/klogproc-core/analysis"
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+// factoryEnv implements apps.Env (and errstream.SinkProvider) for
+// application factories. Fields not related to a created object
+// (e.g. anonymousUsers for parsers) may be left empty.
+type factoryEnv struct {
+	logConf        storage.LogProcConf
+	appErrRegister storage.AppErrorRegister
+	errorSink      errstream.Sink
+	anonymousUsers []int
+	realtimeClock  bool
+	notifier       analysis.Notifier
+}
+
+func (env *factoryEnv) LogConf() storage.LogProcConf {
+	return env.logConf
+}
+
+func (env *factoryEnv) AppErrRegister() storage.AppErrorRegister {
+	return env.appErrRegister
+}
+
+func (env *factoryEnv) ErrorSink() errstream.Sink {
+	return env.errorSink
+}
+
+func (env *factoryEnv) AnonymousUsers() []int {
+	return env.anonymousUsers
+}
+
+func (env *factoryEnv) RealtimeClock() bool {
+	return env.realtimeClock
+}
+
+func (env *factoryEnv) Notifier() analysis.Notifier {
+	return env.notifier
+}
+
+func (env *factoryEnv) AccessLogParser() (accesslog.LineParser, error) {
+	return getAccessLogParser(env.logConf)
+}
+
+func (env *factoryEnv) UserResolver() (users.IDResolver, error) {
+	return getUserResolver(env.logConf)
+}
+
+func (env *factoryEnv) Corpora() corpora.Conf {
+	return getCorporaConf(env.logConf)
+}
+
+func (env *factoryEnv) LookupTables() (*lookup.Registry, error) {
+	return getLookupRegistry(env.logConf)
+}
+
+func (env *factoryEnv) ScriptLimits() luasandbox.Limits {
+	return getScriptLimits(env.logConf)
+}
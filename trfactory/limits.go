@@ -0,0 +1,139 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trfactory
+
+import (
+	"fmt"
+	"os"
+
+	"klogproc/lookup"
+	"klogproc/luasandbox"
+
+	"github.com/czcorpus/klogproc-core/scripting"
+	"github.com/czcorpus/klogproc-core/storage"
+)
+
+const (
+	// scriptStub is loaded by klogproc-core when creating a scripting
+	// environment. The actual script is loaded once the environment is
+	// sandboxed so its code never runs with unrestricted libraries.
+	scriptStub = "-- the script is loaded by klogproc into a sandboxed environment\n"
+)
+
+// scriptLimitsProvider is implemented by log configurations
+// supporting Lua script limits
+type scriptLimitsProvider interface {
+	GetScriptLimits() *luasandbox.Limits
+}
+
+func getScriptLimits(logConf storage.LogProcConf) luasandbox.Limits {
+	if lp, ok := logConf.(scriptLimitsProvider); ok {
+		return lp.GetScriptLimits().WithDefaults()
+	}
+	return (*luasandbox.Limits)(nil).WithDefaults()
+}
+
+// lookupTablesProvider is implemented by log configurations
+// supporting lookup tables
+type lookupTablesProvider interface {
+	GetLookupTables() []lookup.Conf
+}
+
+// getLookupRegistry loads lookup tables configured for a log
+func getLookupRegistry(logConf storage.LogProcConf) (*lookup.Registry, error) {
+	var confs []lookup.Conf
+	if lp, ok := logConf.(lookupTablesProvider); ok {
+		confs = lp.GetLookupTables()
+	}
+	return lookup.NewRegistry(confs)
+}
+
+// writeScriptStub creates a temporary file with an empty script
+// (see scriptStub). The caller is responsible for removing the file.
+func writeScriptStub() (string, error) {
+	f, err := os.CreateTemp("", "klogproc-script-*.lua")
+	if err != nil {
+		return "", fmt.Errorf("failed to create script stub: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(scriptStub); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to create script stub: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// sandboxedLogConf overrides the script path of a log configuration
+// so the scripting environment loads a script stub
+type sandboxedLogConf struct {
+	storage.LogProcConf
+	scriptPath string
+}
+
+func (conf *sandboxedLogConf) GetScriptPath() string {
+	return conf.scriptPath
+}
+
+// ScriptTransformer wraps a scripting transformer and runs all its calls
+// within the script limits. Limits violations are reported as errors wrapping
+// luasandbox.ErrLimitExceeded, other errors and panics are passed through.
+type ScriptTransformer struct {
+	tr      *scripting.Transformer
+	sandbox *luasandbox.Sandbox
+}
+
+func (st *ScriptTransformer) AppType() string {
+	return st.tr.AppType()
+}
+
+func (st *ScriptTransformer) HistoryLookupItems() int {
+	return st.tr.HistoryLookupItems()
+}
+
+func (st *ScriptTransformer) Preprocess(
+	rec storage.InputRecord, prevRecs storage.ServiceLogBuffer,
+) (ans []storage.InputRecord, err error) {
+	if st.sandbox == nil {
+		return st.tr.Preprocess(rec, prevRecs)
+	}
+	err = st.sandbox.Call(func() error {
+		var err error
+		ans, err = st.tr.Preprocess(rec, prevRecs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (st *ScriptTransformer) Transform(
+	rec storage.InputRecord,
+) (ans storage.OutputRecord, err error) {
+	if st.sandbox == nil {
+		return st.tr.Transform(rec)
+	}
+	err = st.sandbox.Call(func() error {
+		var err error
+		ans, err = st.tr.Transform(rec)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
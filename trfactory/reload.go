@@ -0,0 +1,186 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trfactory
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"klogproc/lookup"
+
+	"github.com/czcorpus/klogproc-core/analysis"
+	"github.com/czcorpus/klogproc-core/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// ReloadableTransformer is a scripting transformer which can be replaced
+// by a new version once its Lua script changes.
+// It is intended for long-running processing (the `tail` action) where
+// a restart would reset all the log buffers.
+type ReloadableTransformer struct {
+	logConf        storage.LogProcConf
+	anonymousUsers []int
+	notifier       analysis.Notifier
+	curr           atomic.Pointer[ScriptTransformer]
+	scriptMtime    time.Time
+
+	// static is a transformer shared by all the versions of the script
+	// so its state (e.g. bot analysis, clustering) survives reloading
+	static storage.LogItemTransformer
+
+	// lookups are checked for changes along with the script
+	// (scripts read current data of the tables so they do not
+	// have to be reloaded)
+	lookups *lookup.Registry
+
+	// lastRecord is the last record successfully transformed
+	// by the current transformer. It is used to smoke-test
+	// a reloaded script.
+	lastRecord storage.InputRecord
+	recMutex   sync.Mutex
+}
+
+func (rt *ReloadableTransformer) AppType() string {
+	return rt.curr.Load().AppType()
+}
+
+func (rt *ReloadableTransformer) HistoryLookupItems() int {
+	return rt.curr.Load().HistoryLookupItems()
+}
+
+func (rt *ReloadableTransformer) Preprocess(
+	rec storage.InputRecord, prevRecs storage.ServiceLogBuffer,
+) ([]storage.InputRecord, error) {
+	return rt.curr.Load().Preprocess(rec, prevRecs)
+}
+
+func (rt *ReloadableTransformer) Transform(rec storage.InputRecord) (storage.OutputRecord, error) {
+	ans, err := rt.curr.Load().Transform(rec)
+	if err == nil {
+		rt.recMutex.Lock()
+		rt.lastRecord = rec
+		rt.recMutex.Unlock()
+	}
+	return ans, err
+}
+
+func (rt *ReloadableTransformer) smokeTest(tr *ScriptTransformer) (err error) {
+	rt.recMutex.Lock()
+	rec := rt.lastRecord
+	rt.recMutex.Unlock()
+	if rec == nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("script panicked: %v", r)
+		}
+	}()
+	_, err = tr.Transform(rec)
+	return
+}
+
+func (rt *ReloadableTransformer) notifyFailure(err error) {
+	if rt.notifier == nil {
+		return
+	}
+	nErr := rt.notifier.SendNotification(
+		rt.logConf.GetAppType(),
+		fmt.Sprintf("Klogproc: failed to reload Lua script for %s", rt.logConf.GetAppType()),
+		map[string]any{
+			"appType": rt.logConf.GetAppType(),
+			"script":  rt.logConf.GetScriptPath(),
+		},
+		fmt.Sprintf("The previous version of the script keeps running. Error: %s", err),
+	)
+	if nErr != nil {
+		log.Error().Err(nErr).Msg("failed to send script reload notification")
+	}
+}
+
+// ReloadIfChanged reloads modified lookup tables and tests whether the Lua script
+// has been modified since the last (re)load. If so, it compiles the script, smoke-tests
+// it and replaces the current transformer. In case of an error, the current
+// transformer stays active and a notification is sent.
+func (rt *ReloadableTransformer) ReloadIfChanged() (bool, error) {
+	info, err := os.Stat(rt.logConf.GetScriptPath())
+	if err != nil {
+		return false, fmt.Errorf("failed to check Lua script: %w", err)
+	}
+	if _, err := rt.lookups.ReloadIfChanged(); err != nil {
+		log.Error().Err(err).Msg("keeping the previous version of lookup table")
+	}
+	if !info.ModTime().After(rt.scriptMtime) {
+		return false, nil
+	}
+	// we update the mtime even if the reload fails to prevent repeated
+	// notifications about the same broken script
+	rt.scriptMtime = info.ModTime()
+	tr, err := newScriptTransformer(rt.logConf, rt.anonymousUsers, rt.static)
+	if err == nil {
+		err = rt.smokeTest(tr)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to reload Lua script %s: %w", rt.logConf.GetScriptPath(), err)
+		rt.notifyFailure(err)
+		return false, err
+	}
+	rt.curr.Store(tr)
+	return true, nil
+}
+
+// NewReloadableTransformer creates a new ReloadableTransformer. The logConf
+// must have a Lua script configured.
+func NewReloadableTransformer(
+	logConf storage.LogProcConf,
+	anonymousUsers []int,
+	realtimeClock bool,
+	notifier analysis.Notifier,
+) (*ReloadableTransformer, error) {
+	if logConf.GetScriptPath() == "" {
+		return nil, fmt.Errorf("failed to create reloadable transformer: no Lua script configured")
+	}
+	info, err := os.Stat(logConf.GetScriptPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reloadable transformer: %w", err)
+	}
+	lookups, err := getLookupRegistry(logConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reloadable transformer: %w", err)
+	}
+	static, err := GetStaticLogTransformer(logConf, anonymousUsers, realtimeClock, notifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reloadable transformer: %w", err)
+	}
+	tr, err := newScriptTransformer(logConf, anonymousUsers, static)
+	if err != nil {
+		return nil, err
+	}
+	ans := &ReloadableTransformer{
+		logConf:        logConf,
+		anonymousUsers: anonymousUsers,
+		notifier:       notifier,
+		static:         static,
+		scriptMtime:    info.ModTime(),
+		lookups:        lookups,
+	}
+	ans.curr.Store(tr)
+	return ans, nil
+}
@@ -18,103 +18,88 @@ package trfactory
 
 import (
 	"fmt"
+	"os"
+
+	"klogproc/apps"
+	"klogproc/luasandbox"
 
 	"github.com/czcorpus/klogproc-core/analysis"
 	"github.com/czcorpus/klogproc-core/scripting"
 	"github.com/czcorpus/klogproc-core/storage"
-	k015Core "github.com/czcorpus/klogproc-core/storage/kontext015"
-	kdbCore "github.com/czcorpus/klogproc-core/storage/korpusdb"
-	kwords2Core "github.com/czcorpus/klogproc-core/storage/kwords2"
-	skeCore "github.com/czcorpus/klogproc-core/storage/ske"
-	treqCore "github.com/czcorpus/klogproc-core/storage/treq"
 )
 
+// GetOutputRecordFactory returns a function creating empty output records
+// for a concrete app type and version (as registered in the apps registry)
+func GetOutputRecordFactory(appType, version string) (func() storage.OutputRecord, error) {
+	app, err := apps.Get(appType, version)
+	if err != nil {
+		return nil, err
+	}
+	if app.NewOutputRecord == nil {
+		return nil, fmt.Errorf("no output record defined for %s", app)
+	}
+	return app.NewOutputRecord, nil
+}
+
 // GetLogTransformer creates a log transformer with optional support for Lua scripting.
-// In case there is no script defined or the application type does not support scripting
-// access, the transformer delegates its methods to the traditional "static" transformer
-// (i.e. the one compiled directly to klogproc).
+// In case there is no script defined, the transformer delegates its methods
+// to the traditional "static" transformer (i.e. the one compiled directly to klogproc).
+// Scripts are run with limits configured via `scriptLimits` (see luasandbox.Limits)
+// and with configured lookup tables available as `lookup.<name>`.
 func GetLogTransformer(
 	logConf storage.LogProcConf,
 	anonymousUsers []int,
 	realtimeClock bool,
 	emailNotifier analysis.Notifier,
-) (*scripting.Transformer, error) {
+) (*ScriptTransformer, error) {
 	tr, err := GetStaticLogTransformer(logConf, anonymousUsers, realtimeClock, emailNotifier)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
 	}
+	return newScriptTransformer(logConf, anonymousUsers, tr)
+}
 
+// newScriptTransformer creates a scripting transformer on top of an existing
+// static transformer. This allows for reloading a script without losing a state
+// of the static transformer (e.g. bot analysis).
+func newScriptTransformer(
+	logConf storage.LogProcConf,
+	anonymousUsers []int,
+	tr storage.LogItemTransformer,
+) (*ScriptTransformer, error) {
 	if logConf.GetScriptPath() == "" {
-		return scripting.NewTransformer(nil, tr), nil
+		return &ScriptTransformer{tr: scripting.NewTransformer(nil, tr)}, nil
 	}
 
-	version := logConf.GetVersion()
-	switch logConf.GetAppType() {
-	case storage.AppTypeKontext:
-		if version == storage.AppVersionKontext018 ||
-			version == storage.AppVersionKontext017 ||
-			version == storage.AppVersionKontext017API ||
-			version == storage.AppVersionKontext016 ||
-			version == storage.AppVersionKontext015 {
-			env, err := scripting.CreateEnvironment(
-				logConf,
-				anonymousUsers,
-				tr,
-				func() storage.OutputRecord { return &k015Core.OutputRecord{} },
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
-			}
-			return scripting.NewTransformer(env, tr), nil
-		}
-	case storage.AppTypeKorpusDB:
-		env, err := scripting.CreateEnvironment(
-			logConf,
-			anonymousUsers,
-			tr,
-			func() storage.OutputRecord { return &kdbCore.OutputRecord{} },
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
-		}
-		return scripting.NewTransformer(env, tr), nil
-	case storage.AppTypeKwords:
-		if version == storage.AppVersionKwords2 {
-			env, err := scripting.CreateEnvironment(
-				logConf,
-				anonymousUsers,
-				tr,
-				func() storage.OutputRecord { return &kwords2Core.OutputRecord{} },
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
-			}
-			return scripting.NewTransformer(env, tr), nil
-		}
-	case storage.AppTypeSke:
-		env, err := scripting.CreateEnvironment(
-			logConf,
-			anonymousUsers,
-			tr,
-			func() storage.OutputRecord { return &skeCore.OutputRecord{} },
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
-		}
-		return scripting.NewTransformer(env, tr), nil
-	case storage.AppTypeTreq:
-		env, err := scripting.CreateEnvironment(
-			logConf,
-			anonymousUsers,
-			tr,
-			func() storage.OutputRecord { return &treqCore.OutputRecord{} },
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
-		}
-		return scripting.NewTransformer(env, tr), nil
-
+	outRecFactory, err := GetOutputRecordFactory(logConf.GetAppType(), logConf.GetVersion())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
 	}
-
-	return nil, fmt.Errorf("klogproc does not support scripting for %s logs", logConf.GetAppType())
+	lookups, err := getLookupRegistry(logConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
+	}
+	stubPath, err := writeScriptStub()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
+	}
+	defer os.Remove(stubPath)
+	env, err := scripting.CreateEnvironment(
+		&sandboxedLogConf{LogProcConf: logConf, scriptPath: stubPath},
+		anonymousUsers,
+		tr,
+		outRecFactory,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
+	}
+	sandbox := luasandbox.Wrap(env, getScriptLimits(logConf))
+	lookups.Register(env)
+	if err := sandbox.DoFile(logConf.GetScriptPath()); err != nil {
+		return nil, fmt.Errorf("failed to create scripting transformer for %s: %w", logConf.GetAppType(), err)
+	}
+	return &ScriptTransformer{
+		tr:      scripting.NewTransformer(env, tr),
+		sandbox: sandbox,
+	}, nil
 }
@@ -0,0 +1,132 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatSQLDump = "sqldump"
+
+	DefaultIDColumn       = "id"
+	DefaultUsernameColumn = "username"
+	DefaultTable          = "users"
+
+	// DefaultRefreshIntervalSecs specifies how often the source file
+	// is tested for changes
+	DefaultRefreshIntervalSecs = 300
+
+	// DefaultMissCacheTTLSecs specifies how long an unknown username
+	// is remembered before the source file is tested again for changes
+	// (e.g. because of a newly registered user)
+	DefaultMissCacheTTLSecs = 60
+)
+
+// Conf configures a username to user ID resolver.
+//
+// Supported formats are:
+//   - `json` - either an object mapping usernames to IDs or an array of objects
+//     with IDColumn and UsernameColumn properties,
+//   - `csv` - a file with a header containing IDColumn and UsernameColumn,
+//   - `sqldump` - an SQL dump of a SQLite database (`sqlite3 users.db .dump`),
+//     where rows of the Table are read from INSERT statements.
+//
+// Format is derived from the file suffix (.json, .csv, .sql) if not specified.
+// A negative RefreshIntervalSecs disables refreshing, a negative MissCacheTTLSecs
+// disables refreshing on unknown usernames.
+type Conf struct {
+	Path                string `json:"path"`
+	Format              string `json:"format"`
+	Table               string `json:"table"`
+	IDColumn            string `json:"idColumn"`
+	UsernameColumn      string `json:"usernameColumn"`
+	Delimiter           string `json:"delimiter"`
+	RefreshIntervalSecs int    `json:"refreshIntervalSecs"`
+	MissCacheTTLSecs    int    `json:"missCacheTTLSecs"`
+}
+
+// GetFormat returns the configured format or a format derived
+// from the file suffix
+func (conf *Conf) GetFormat() string {
+	if conf.Format != "" {
+		return conf.Format
+	}
+	switch filepath.Ext(conf.Path) {
+	case ".csv":
+		return FormatCSV
+	case ".sql":
+		return FormatSQLDump
+	}
+	return FormatJSON
+}
+
+func (conf *Conf) idColumn() string {
+	if conf.IDColumn == "" {
+		return DefaultIDColumn
+	}
+	return conf.IDColumn
+}
+
+func (conf *Conf) usernameColumn() string {
+	if conf.UsernameColumn == "" {
+		return DefaultUsernameColumn
+	}
+	return conf.UsernameColumn
+}
+
+func (conf *Conf) table() string {
+	if conf.Table == "" {
+		return DefaultTable
+	}
+	return conf.Table
+}
+
+func secsOrDefault(v, dflt int) time.Duration {
+	if v == 0 {
+		v = dflt
+	}
+	if v < 0 {
+		return 0
+	}
+	return time.Duration(v) * time.Second
+}
+
+// Validate checks the configuration
+func (conf *Conf) Validate() error {
+	if conf.Path == "" {
+		return errors.New("invalid users configuration: missing path")
+	}
+	switch conf.GetFormat() {
+	case FormatJSON, FormatSQLDump:
+		if conf.Delimiter != "" {
+			return errors.New("invalid users configuration: delimiter can be used only with CSV")
+		}
+	case FormatCSV:
+		if len([]rune(conf.Delimiter)) > 1 {
+			return errors.New("invalid users configuration: delimiter must be a single character")
+		}
+	default:
+		return fmt.Errorf("invalid users configuration: unsupported format %s", conf.Format)
+	}
+	return nil
+}
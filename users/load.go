@@ -0,0 +1,158 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// importID converts different representations of a user ID
+func importID(v any) (int, error) {
+	switch tv := v.(type) {
+	case float64:
+		return int(tv), nil
+	case int:
+		return tv, nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(tv))
+	}
+	return 0, fmt.Errorf("invalid user ID %v", v)
+}
+
+func loadJSON(r io.Reader, conf *Conf) (map[string]int, error) {
+	var tmp any
+	if err := json.NewDecoder(r).Decode(&tmp); err != nil {
+		return nil, err
+	}
+	switch tTmp := tmp.(type) {
+	case map[string]any:
+		ans := make(map[string]int, len(tTmp))
+		for username, v := range tTmp {
+			id, err := importID(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import user %s: %w", username, err)
+			}
+			ans[username] = id
+		}
+		return ans, nil
+	case []any:
+		ans := make(map[string]int, len(tTmp))
+		for i, item := range tTmp {
+			obj, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("item %d is not an object", i)
+			}
+			username, ok := obj[conf.usernameColumn()].(string)
+			if !ok {
+				return nil, fmt.Errorf("item %d has no string property %s", i, conf.usernameColumn())
+			}
+			id, err := importID(obj[conf.idColumn()])
+			if err != nil {
+				return nil, fmt.Errorf("failed to import item %d: %w", i, err)
+			}
+			ans[username] = id
+		}
+		return ans, nil
+	default:
+		return nil, errors.New("expected an object or an array")
+	}
+}
+
+func columnIndex(header []string, name string) int {
+	for i, v := range header {
+		if v == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func loadCSV(r io.Reader, conf *Conf) (map[string]int, error) {
+	cr := csv.NewReader(r)
+	if conf.Delimiter != "" {
+		cr.Comma = []rune(conf.Delimiter)[0]
+	}
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	idIdx := columnIndex(header, conf.idColumn())
+	if idIdx < 0 {
+		return nil, fmt.Errorf("column %s not found", conf.idColumn())
+	}
+	usernameIdx := columnIndex(header, conf.usernameColumn())
+	if usernameIdx < 0 {
+		return nil, fmt.Errorf("column %s not found", conf.usernameColumn())
+	}
+	ans := make(map[string]int)
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(row[idIdx]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to import user %s: %w", row[usernameIdx], err)
+		}
+		ans[row[usernameIdx]] = id
+	}
+	return ans, nil
+}
+
+func loadSQLDump(r io.Reader, conf *Conf) (map[string]int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readSQLDumpTable(string(data), conf.table())
+	if err != nil {
+		return nil, err
+	}
+	ans := make(map[string]int, len(rows))
+	for i, row := range rows {
+		username, ok := row[conf.usernameColumn()].(string)
+		if !ok {
+			return nil, fmt.Errorf("row %d has no string column %s", i, conf.usernameColumn())
+		}
+		id, err := importID(row[conf.idColumn()])
+		if err != nil {
+			return nil, fmt.Errorf("failed to import row %d: %w", i, err)
+		}
+		ans[username] = id
+	}
+	return ans, nil
+}
+
+func load(r io.Reader, conf *Conf) (map[string]int, error) {
+	switch conf.GetFormat() {
+	case FormatCSV:
+		return loadCSV(r, conf)
+	case FormatSQLDump:
+		return loadSQLDump(r, conf)
+	default:
+		return loadJSON(r, conf)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxCachedMisses limits the size of the cache of unknown
+	// usernames (once exceeded, the cache is cleared)
+	maxCachedMisses = 100000
+)
+
+var (
+	// resolverCache makes sure the same file is loaded only once
+	// even if it is configured for multiple log files
+	resolverCache      = make(map[Conf]*Resolver)
+	resolverCacheMutex sync.Mutex
+)
+
+// IDResolver provides user IDs for usernames
+type IDResolver interface {
+	// Resolve returns a user ID of a username. The second
+	// return value is false if the user is unknown.
+	Resolve(username string) (int, bool)
+}
+
+// Resolver is an IDResolver backed by a file (JSON, CSV or an SQL dump).
+// The file is tested for changes periodically and also when an unknown
+// username is encountered (but at most once per MissCacheTTLSecs for the
+// same username). It is safe for concurrent use.
+type Resolver struct {
+	conf            Conf
+	data            atomic.Pointer[map[string]int]
+	mtime           time.Time
+	lastCheck       time.Time
+	refreshInterval time.Duration
+	missTTL         time.Duration
+	misses          map[string]time.Time
+	mutex           sync.Mutex
+}
+
+func (r *Resolver) load() error {
+	f, err := os.Open(r.conf.Path)
+	if err != nil {
+		return fmt.Errorf("failed to load users from %s: %w", r.conf.Path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to load users from %s: %w", r.conf.Path, err)
+	}
+	data, err := load(f, &r.conf)
+	if err != nil {
+		return fmt.Errorf("failed to load users from %s: %w", r.conf.Path, err)
+	}
+	r.data.Store(&data)
+	r.mtime = info.ModTime()
+	r.misses = make(map[string]time.Time)
+	log.Info().
+		Str("path", r.conf.Path).
+		Int("numUsers", len(data)).
+		Msg("loaded users")
+	return nil
+}
+
+// ReloadIfChanged reloads users in case the source file has been modified.
+// In case of an error, the previous data are kept.
+func (r *Resolver) ReloadIfChanged() (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.reloadIfChanged()
+}
+
+func (r *Resolver) reloadIfChanged() (bool, error) {
+	r.lastCheck = time.Now()
+	info, err := os.Stat(r.conf.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check users file: %w", err)
+	}
+	if !info.ModTime().After(r.mtime) {
+		return false, nil
+	}
+	if err := r.load(); err != nil {
+		// prevent repeated reloading of the same broken file
+		r.mtime = info.ModTime()
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Resolver) refreshIfNeeded() {
+	if r.refreshInterval == 0 {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if time.Since(r.lastCheck) > r.refreshInterval {
+		if _, err := r.reloadIfChanged(); err != nil {
+			log.Error().Err(err).Msg("keeping the previous version of users")
+		}
+	}
+}
+
+// shouldRecheck registers a miss and tells whether the source
+// file should be tested for changes
+func (r *Resolver) shouldRecheck(username string) bool {
+	if r.missTTL == 0 {
+		return false
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if t, ok := r.misses[username]; ok && time.Since(t) < r.missTTL {
+		return false
+	}
+	if len(r.misses) >= maxCachedMisses {
+		r.misses = make(map[string]time.Time)
+	}
+	r.misses[username] = time.Now()
+	return true
+}
+
+// Resolve returns a user ID of a username
+func (r *Resolver) Resolve(username string) (int, bool) {
+	r.refreshIfNeeded()
+	if id, ok := (*r.data.Load())[username]; ok {
+		return id, true
+	}
+	if !r.shouldRecheck(username) {
+		return -1, false
+	}
+	changed, err := r.ReloadIfChanged()
+	if err != nil {
+		log.Error().Err(err).Msg("keeping the previous version of users")
+	}
+	if changed {
+		if id, ok := (*r.data.Load())[username]; ok {
+			return id, true
+		}
+	}
+	return -1, false
+}
+
+// Len returns the number of known users
+func (r *Resolver) Len() int {
+	return len(*r.data.Load())
+}
+
+// NewResolver creates a resolver according to the configuration.
+// Resolvers with the same configuration are shared.
+func NewResolver(conf Conf) (*Resolver, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	resolverCacheMutex.Lock()
+	defer resolverCacheMutex.Unlock()
+	if r, ok := resolverCache[conf]; ok {
+		return r, nil
+	}
+	ans := &Resolver{
+		conf:            conf,
+		refreshInterval: secsOrDefault(conf.RefreshIntervalSecs, DefaultRefreshIntervalSecs),
+		missTTL:         secsOrDefault(conf.MissCacheTTLSecs, DefaultMissCacheTTLSecs),
+		lastCheck:       time.Now(),
+	}
+	if err := ans.load(); err != nil {
+		return nil, err
+	}
+	resolverCache[conf] = ans
+	return ans, nil
+}
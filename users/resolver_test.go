@@ -0,0 +1,121 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func touch(t *testing.T, path, content string) {
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, future, future))
+}
+
+func TestResolverFormats(t *testing.T) {
+	confs := []Conf{
+		{Path: writeFile(t, "users.json", `{"alice": 12, "bob": "13"}`)},
+		{Path: writeFile(t, "users2.json", `[{"id": 12, "username": "alice"}, {"id": 13, "username": "bob"}]`)},
+		{Path: writeFile(t, "users.csv", "id;login;email\n12;alice;a@x\n13;bob;b@x\n"), UsernameColumn: "login", Delimiter: ";"},
+		{Path: writeFile(t, "users.sql", sqlDumpSrc)},
+	}
+	for _, conf := range confs {
+		r, err := NewResolver(conf)
+		assert.NoError(t, err, conf.Path)
+		id, ok := r.Resolve("alice")
+		assert.True(t, ok, conf.Path)
+		assert.Equal(t, 12, id, conf.Path)
+		id, ok = r.Resolve("bob")
+		assert.True(t, ok, conf.Path)
+		assert.Equal(t, 13, id, conf.Path)
+		id, ok = r.Resolve("eve")
+		assert.False(t, ok, conf.Path)
+		assert.Equal(t, -1, id, conf.Path)
+	}
+}
+
+func TestResolverInvalidConf(t *testing.T) {
+	_, err := NewResolver(Conf{})
+	assert.Error(t, err)
+	_, err = NewResolver(Conf{Path: "users.xml", Format: "xml"})
+	assert.Error(t, err)
+	_, err = NewResolver(Conf{Path: writeFile(t, "users.json", `{"alice": 12}`), Delimiter: ";"})
+	assert.Error(t, err)
+	_, err = NewResolver(Conf{Path: writeFile(t, "users.csv", "id,name\n1,alice\n")})
+	assert.Error(t, err)
+}
+
+func TestResolverReloadsOnMiss(t *testing.T) {
+	path := writeFile(t, "users.json", `{"alice": 12}`)
+	r, err := NewResolver(Conf{Path: path, RefreshIntervalSecs: -1, MissCacheTTLSecs: 3600})
+	assert.NoError(t, err)
+	_, ok := r.Resolve("bob")
+	assert.False(t, ok)
+
+	touch(t, path, `{"alice": 12, "bob": 13, "carol": 14}`)
+	// a recent miss is cached so the file is not checked again
+	_, ok = r.Resolve("bob")
+	assert.False(t, ok)
+	// a new miss triggers a reload
+	id, ok := r.Resolve("carol")
+	assert.True(t, ok)
+	assert.Equal(t, 14, id)
+	// the reload clears the cache of misses
+	id, ok = r.Resolve("bob")
+	assert.True(t, ok)
+	assert.Equal(t, 13, id)
+	assert.Equal(t, 3, r.Len())
+}
+
+func TestResolverKeepsDataOnBrokenFile(t *testing.T) {
+	path := writeFile(t, "users.json", `{"alice": 12}`)
+	r, err := NewResolver(Conf{Path: path, RefreshIntervalSecs: -1})
+	assert.NoError(t, err)
+	touch(t, path, `{"alice": `)
+	changed, err := r.ReloadIfChanged()
+	assert.False(t, changed)
+	assert.Error(t, err)
+	id, ok := r.Resolve("alice")
+	assert.True(t, ok)
+	assert.Equal(t, 12, id)
+}
+
+func TestResolverIsShared(t *testing.T) {
+	conf := Conf{Path: writeFile(t, "users.json", `{"alice": 12}`)}
+	r1, err := NewResolver(conf)
+	assert.NoError(t, err)
+	r2, err := NewResolver(conf)
+	assert.NoError(t, err)
+	assert.Same(t, r1, r2)
+}
+
+func TestUserMapResolve(t *testing.T) {
+	id, ok := EmptyUserMap().Resolve("alice")
+	assert.True(t, ok)
+	assert.Equal(t, 0, id)
+}
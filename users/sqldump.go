@@ -0,0 +1,390 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file contains a minimal reader of SQL dumps as produced
+// by SQLite's `.dump` command. Only CREATE TABLE (to obtain column
+// names) and INSERT statements are interpreted.
+
+type tokenType int
+
+const (
+	tokenWord tokenType = iota
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	tp    tokenType
+	value string
+}
+
+func (t token) isWord(w string) bool {
+	return t.tp == tokenWord && strings.EqualFold(t.value, w)
+}
+
+func (t token) isPunct(p string) bool {
+	return t.tp == tokenPunct && t.value == p
+}
+
+func (t token) isIdent() bool {
+	return t.tp == tokenWord || t.tp == tokenQuotedIdent
+}
+
+// splitStatements splits SQL source into statements. Semicolons
+// inside string literals, quoted identifiers and comments are ignored.
+func splitStatements(src string) []string {
+	ans := make([]string, 0, 100)
+	var quote rune
+	start := 0
+	runes := []rune(src)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == ';':
+			ans = append(ans, string(runes[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		ans = append(ans, rest)
+	}
+	return ans
+}
+
+func isWordRune(c rune) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' || c > 127
+}
+
+// tokenize splits a single statement into tokens
+func tokenize(stmt string) ([]token, error) {
+	ans := make([]token, 0, 50)
+	runes := []rune(stmt)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			var b strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == closing {
+					// doubled quote is an escaped quote
+					if closing != ']' && i+1 < len(runes) && runes[i+1] == closing {
+						b.WriteRune(closing)
+						i++
+						continue
+					}
+					closed = true
+					break
+				}
+				b.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quoted value %s", b.String())
+			}
+			tp := tokenQuotedIdent
+			if c == '\'' {
+				tp = tokenString
+			}
+			ans = append(ans, token{tp: tp, value: b.String()})
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9':
+			j := i
+			for j < len(runes) && (isWordRune(runes[j]) || runes[j] == '.' ||
+				(runes[j] == '+' || runes[j] == '-') && (runes[j-1] == 'e' || runes[j-1] == 'E')) {
+				j++
+			}
+			ans = append(ans, token{tp: tokenNumber, value: string(runes[i:j])})
+			i = j - 1
+		case isWordRune(c):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			ans = append(ans, token{tp: tokenWord, value: string(runes[i:j])})
+			i = j - 1
+		default:
+			ans = append(ans, token{tp: tokenPunct, value: string(c)})
+		}
+	}
+	return ans, nil
+}
+
+// readTableName reads a (possibly schema-qualified) table name
+// starting at position i and returns the name and the next position
+func readTableName(tokens []token, i int) (string, int, error) {
+	if i >= len(tokens) || !tokens[i].isIdent() {
+		return "", i, fmt.Errorf("expected table name")
+	}
+	name := tokens[i].value
+	i++
+	if i+1 < len(tokens) && tokens[i].isPunct(".") && tokens[i+1].isIdent() {
+		name = tokens[i+1].value
+		i += 2
+	}
+	return name, i, nil
+}
+
+// readParenthesized returns comma-separated groups of tokens
+// enclosed in parentheses starting at position i along with
+// the position after the closing parenthesis
+func readParenthesized(tokens []token, i int) ([][]token, int, error) {
+	if i >= len(tokens) || !tokens[i].isPunct("(") {
+		return nil, i, fmt.Errorf("expected (")
+	}
+	ans := make([][]token, 0, 10)
+	curr := make([]token, 0, 5)
+	depth := 0
+	for i++; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			if depth == 0 {
+				return append(ans, curr), i + 1, nil
+			}
+			depth--
+		case t.isPunct(",") && depth == 0:
+			ans = append(ans, curr)
+			curr = make([]token, 0, 5)
+			continue
+		}
+		curr = append(curr, t)
+	}
+	return nil, i, fmt.Errorf("expected )")
+}
+
+var tableConstraints = map[string]bool{
+	"CONSTRAINT": true,
+	"PRIMARY":    true,
+	"UNIQUE":     true,
+	"CHECK":      true,
+	"FOREIGN":    true,
+}
+
+// parseCreateTable returns a table name and its columns
+func parseCreateTable(tokens []token) (string, []string, error) {
+	i := 1
+	for i < len(tokens) && !tokens[i].isWord("TABLE") {
+		i++ // e.g. TEMP
+	}
+	i++
+	if i+2 < len(tokens) && tokens[i].isWord("IF") && tokens[i+1].isWord("NOT") && tokens[i+2].isWord("EXISTS") {
+		i += 3
+	}
+	name, i, err := readTableName(tokens, i)
+	if err != nil {
+		return "", nil, err
+	}
+	defs, _, err := readParenthesized(tokens, i)
+	if err != nil {
+		return "", nil, err
+	}
+	columns := make([]string, 0, len(defs))
+	for _, def := range defs {
+		if len(def) == 0 || !def[0].isIdent() {
+			continue
+		}
+		if def[0].tp == tokenWord && tableConstraints[strings.ToUpper(def[0].value)] {
+			continue
+		}
+		columns = append(columns, def[0].value)
+	}
+	return name, columns, nil
+}
+
+func parseValue(tokens []token) (any, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+	sign := ""
+	if len(tokens) == 2 && (tokens[0].isPunct("-") || tokens[0].isPunct("+")) {
+		sign = tokens[0].value
+		tokens = tokens[1:]
+	}
+	if len(tokens) != 1 {
+		// expressions (e.g. X'..' blobs) are not supported
+		return nil, nil
+	}
+	t := tokens[0]
+	switch t.tp {
+	case tokenString:
+		return t.value, nil
+	case tokenNumber:
+		if v, err := strconv.Atoi(sign + t.value); err == nil {
+			return v, nil
+		}
+		v, err := strconv.ParseFloat(sign+t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t.value)
+		}
+		return v, nil
+	}
+	return nil, nil
+}
+
+// parseInsert returns a table name, optional column names
+// and rows of values
+func parseInsert(tokens []token) (string, []string, [][]any, error) {
+	i := 1
+	for i < len(tokens) && !tokens[i].isWord("INTO") {
+		i++ // e.g. OR REPLACE
+	}
+	name, i, err := readTableName(tokens, i+1)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	var columns []string
+	if i < len(tokens) && tokens[i].isPunct("(") {
+		groups, next, err := readParenthesized(tokens, i)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		for _, g := range groups {
+			if len(g) != 1 || !g[0].isIdent() {
+				return "", nil, nil, fmt.Errorf("invalid column list of %s", name)
+			}
+			columns = append(columns, g[0].value)
+		}
+		i = next
+	}
+	if i >= len(tokens) || !tokens[i].isWord("VALUES") {
+		return "", nil, nil, fmt.Errorf("only INSERT ... VALUES is supported")
+	}
+	i++
+	rows := make([][]any, 0, 1)
+	for i < len(tokens) {
+		groups, next, err := readParenthesized(tokens, i)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		row := make([]any, len(groups))
+		for j, g := range groups {
+			if row[j], err = parseValue(g); err != nil {
+				return "", nil, nil, err
+			}
+		}
+		rows = append(rows, row)
+		i = next
+		if i < len(tokens) && tokens[i].isPunct(",") {
+			i++
+			continue
+		}
+		break
+	}
+	return name, columns, rows, nil
+}
+
+// readSQLDumpTable returns all the rows of a table inserted
+// by an SQL dump. Rows are represented as column => value maps.
+func readSQLDumpTable(src, table string) ([]map[string]any, error) {
+	var tableColumns []string
+	ans := make([]map[string]any, 0, 1000)
+	for _, stmt := range splitStatements(src) {
+		tokens, err := tokenize(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		switch {
+		case tokens[0].isWord("CREATE"):
+			if len(tokens) < 3 || !(tokens[1].isWord("TABLE") || tokens[2].isWord("TABLE")) {
+				continue
+			}
+			name, columns, err := parseCreateTable(tokens)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse CREATE TABLE: %w", err)
+			}
+			if strings.EqualFold(name, table) {
+				tableColumns = columns
+			}
+		case tokens[0].isWord("INSERT"):
+			name, columns, rows, err := parseInsert(tokens)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse INSERT: %w", err)
+			}
+			if !strings.EqualFold(name, table) {
+				continue
+			}
+			if columns == nil {
+				columns = tableColumns
+			}
+			if columns == nil {
+				return nil, fmt.Errorf("unknown columns of table %s (missing CREATE TABLE)", table)
+			}
+			for _, row := range rows {
+				if len(row) != len(columns) {
+					return nil, fmt.Errorf("number of values does not match columns of %s", table)
+				}
+				item := make(map[string]any, len(columns))
+				for j, col := range columns {
+					item[col] = row[j]
+				}
+				ans = append(ans, item)
+			}
+		}
+	}
+	if tableColumns == nil && len(ans) == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+	return ans, nil
+}
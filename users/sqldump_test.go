@@ -0,0 +1,68 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sqlDumpSrc = `PRAGMA foreign_keys=OFF;
+BEGIN TRANSACTION;
+CREATE TABLE groups (id INTEGER PRIMARY KEY, name TEXT);
+INSERT INTO groups VALUES(1,'admins');
+CREATE TABLE IF NOT EXISTS "users" (
+	id INTEGER NOT NULL,
+	"username" VARCHAR(100), -- login; used in logs
+	note TEXT DEFAULT 'n/a',
+	score REAL,
+	PRIMARY KEY (id),
+	UNIQUE (username)
+);
+INSERT INTO users VALUES(12,'alice','it''s; me',-1.5);
+INSERT INTO "users" VALUES(13,'bob',NULL,2e3),(14,'carol','multi
+line',0.5);
+INSERT INTO users(username, id) VALUES('dave', 15);
+/* a comment; with a semicolon */
+COMMIT;
+`
+
+func TestReadSQLDumpTable(t *testing.T) {
+	rows, err := readSQLDumpTable(sqlDumpSrc, "users")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]map[string]any{
+			{"id": 12, "username": "alice", "note": "it's; me", "score": -1.5},
+			{"id": 13, "username": "bob", "note": nil, "score": 2000.0},
+			{"id": 14, "username": "carol", "note": "multi\nline", "score": 0.5},
+			{"id": 15, "username": "dave"},
+		},
+		rows,
+	)
+}
+
+func TestReadSQLDumpMissingTable(t *testing.T) {
+	_, err := readSQLDumpTable(sqlDumpSrc, "accounts")
+	assert.Error(t, err)
+}
+
+func TestReadSQLDumpUnknownColumns(t *testing.T) {
+	_, err := readSQLDumpTable("INSERT INTO users VALUES(1, 'alice');", "users")
+	assert.Error(t, err)
+}
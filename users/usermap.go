@@ -21,14 +21,27 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+
+	"klogproc/lookup"
 )
 
+// LookupTableName is the name of a lookup table (see package lookup)
+// used as a user map by transformers supporting it
+const LookupTableName = "users"
+
 type UserMap struct {
 	data          map[string]int
+	table         *lookup.Table
 	ignoreMissing bool
 }
 
 func (um *UserMap) GetIdOf(username string) int {
+	if um.table != nil {
+		if v, ok := um.table.GetInt(username); ok {
+			return v
+		}
+		return -1
+	}
 	v, ok := um.data[username]
 	if ok || um.ignoreMissing {
 		return v
@@ -36,6 +49,18 @@ func (um *UserMap) GetIdOf(username string) int {
 	return -1
 }
 
+// Resolve implements IDResolver
+func (um *UserMap) Resolve(username string) (int, bool) {
+	if um.table != nil {
+		return um.table.GetInt(username)
+	}
+	v, ok := um.data[username]
+	if ok || um.ignoreMissing {
+		return v, true
+	}
+	return -1, false
+}
+
 // LoadUserMap loads json-encoded [username]=>[user_id] map
 func LoadUserMap(path string) (*UserMap, error) {
 	fr, err := os.OpenFile(path, os.O_RDONLY, 0644)
@@ -51,6 +76,13 @@ func LoadUserMap(path string) (*UserMap, error) {
 	return &UserMap{data: ans, ignoreMissing: false}, err
 }
 
+// NewUserMapFromTable creates a user map backed by a lookup table
+// mapping usernames to user IDs. Changes of the table file are
+// reflected automatically.
+func NewUserMapFromTable(tbl *lookup.Table) *UserMap {
+	return &UserMap{table: tbl}
+}
+
 func EmptyUserMap() *UserMap {
 	return &UserMap{data: make(map[string]int), ignoreMissing: true}
 }